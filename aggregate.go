@@ -0,0 +1,94 @@
+package logseq
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aholstenson/logseq-go/internal/indexing"
+)
+
+// Facet is a field that the results of a query can be grouped by, with the
+// number of results counted for every value of the field. Facets return the
+// ten most common values, use WithSize to get more or fewer.
+type Facet = indexing.Facet
+
+// Aggregation is the result of grouping the results of a query by facets.
+type Aggregation = indexing.Aggregation
+
+// Bucket is a value of a facet together with the number of results that have
+// it.
+type Bucket = indexing.Bucket
+
+// FacetReferences groups results by the pages they reference.
+func FacetReferences() *Facet {
+	return indexing.FacetReferences()
+}
+
+// FacetTags groups results by their tags, both the ones written as `#tag` and
+// the ones in a `tags::` property.
+func FacetTags() *Facet {
+	return indexing.FacetTags()
+}
+
+// FacetPropertyValues groups results by the values of a property, such as the
+// values of `status::`.
+func FacetPropertyValues(property string) *Facet {
+	return indexing.FacetPropertyValues(property)
+}
+
+// FacetPageType groups results by the type of page they are or are on, which
+// is either `page` or `journal`.
+func FacetPageType() *Facet {
+	return indexing.FacetPageType()
+}
+
+// FacetJournalMonth groups journals and the blocks in them by the month of the
+// journal, in the form `2006-01`.
+func FacetJournalMonth() *Facet {
+	return indexing.FacetJournalMonth()
+}
+
+// FacetTaskStatus groups blocks that are tasks by their status, such as `TODO`
+// or `DONE`.
+func FacetTaskStatus() *Facet {
+	return indexing.FacetTaskStatus()
+}
+
+// Aggregate groups the blocks that match the query by the given facets, and
+// counts the blocks for every value of them. This can be used to count things
+// such as the tasks that reference a project by their status:
+//
+//	aggregation, err := graph.Aggregate(ctx, logseq.References("Project"), logseq.FacetTaskStatus())
+//
+//	for _, bucket := range aggregation.Buckets(logseq.FacetTaskStatus()) {
+//		fmt.Println(bucket.Term, bucket.Count)
+//	}
+//
+// A nil query matches every block. The counting is done by the index, so this
+// requires the graph to have been opened with indexing enabled.
+func (g *Graph) Aggregate(ctx context.Context, query Query, facets ...*Facet) (*Aggregation, error) {
+	if g.index == nil {
+		return nil, fmt.Errorf("indexing is not enabled")
+	}
+
+	if query == nil {
+		query = indexing.All()
+	}
+
+	return g.index.AggregateBlocks(ctx, query, facets)
+}
+
+// AggregatePages groups the pages that match the query by the given facets, in
+// the same way as Aggregate does for blocks. The tags and properties of a page
+// are the ones in the properties it opens with.
+func (g *Graph) AggregatePages(ctx context.Context, query Query, facets ...*Facet) (*Aggregation, error) {
+	if g.index == nil {
+		return nil, fmt.Errorf("indexing is not enabled")
+	}
+
+	if query == nil {
+		query = indexing.All()
+	}
+
+	return g.index.AggregatePages(ctx, query, facets)
+}
@@ -0,0 +1,128 @@
+package logseq_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	logseq "github.com/aholstenson/logseq-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Aggregations", func() {
+	var (
+		graph *logseq.Graph
+		dir   string
+		ctx   context.Context
+	)
+
+	BeforeEach(func() {
+		dir = setupGraph()
+		ctx = context.Background()
+	})
+
+	AfterEach(func() {
+		if graph != nil {
+			graph.Close()
+			graph = nil
+		}
+	})
+
+	It("counts blocks by task status", func() {
+		graph = openGraphWithPages(dir, map[string]string{
+			"a.md": "- TODO first [[Project]]\n- DONE second [[Project]]\n- TODO third [[Project]]\n- TODO elsewhere\n",
+		})
+
+		aggregation, err := graph.Aggregate(ctx, logseq.References("Project"), logseq.FacetTaskStatus())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(aggregation.Count).To(Equal(3))
+		Expect(aggregation.Buckets(logseq.FacetTaskStatus())).To(Equal([]logseq.Bucket{
+			{Term: "TODO", Count: 2},
+			{Term: "DONE", Count: 1},
+		}))
+	})
+
+	It("counts blocks by the pages they reference", func() {
+		graph = openGraphWithPages(dir, map[string]string{
+			"a.md": "- [[Alpha]] and [[Beta]]\n- [[alpha]]\n",
+		})
+
+		aggregation, err := graph.Aggregate(ctx, nil, logseq.FacetReferences())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(aggregation.Buckets(logseq.FacetReferences())).To(Equal([]logseq.Bucket{
+			{Term: "alpha", Count: 2},
+			{Term: "beta", Count: 1},
+		}))
+	})
+
+	It("counts tags from both hashtags and tags properties", func() {
+		graph = openGraphWithPages(dir, map[string]string{
+			"a.md": "tags:: Reading, #Book\n\n- note #book\n- other #idea #idea\n",
+		})
+
+		aggregation, err := graph.Aggregate(ctx, nil, logseq.FacetTags())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(aggregation.Buckets(logseq.FacetTags())).To(Equal([]logseq.Bucket{
+			{Term: "book", Count: 2},
+			{Term: "idea", Count: 1},
+			{Term: "reading", Count: 1},
+		}))
+	})
+
+	It("counts pages by property values", func() {
+		graph = openGraphWithPages(dir, map[string]string{
+			"a.md": "status:: active\n\n- content\n",
+			"b.md": "status:: active\n\n- content\n",
+			"c.md": "status:: done\n\n- content\n",
+			"d.md": "- content\n",
+		})
+
+		facet := logseq.FacetPropertyValues("status")
+		aggregation, err := graph.AggregatePages(ctx, nil, facet)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(aggregation.Count).To(Equal(4))
+		Expect(aggregation.Buckets(facet)).To(Equal([]logseq.Bucket{
+			{Term: "active", Count: 2},
+			{Term: "done", Count: 1},
+		}))
+	})
+
+	It("counts pages by type and journal month", func() {
+		for _, name := range []string{"2024_01_05.md", "2024_01_20.md", "2024_02_03.md"} {
+			Expect(os.WriteFile(
+				filepath.Join(dir, "journals", name),
+				[]byte("- entry\n"),
+				0o644,
+			)).To(Succeed())
+		}
+
+		graph = openGraphWithPages(dir, map[string]string{
+			"a.md": "- content\n",
+		})
+
+		aggregation, err := graph.AggregatePages(ctx, nil, logseq.FacetPageType(), logseq.FacetJournalMonth())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(aggregation.Buckets(logseq.FacetPageType())).To(Equal([]logseq.Bucket{
+			{Term: "journal", Count: 3},
+			{Term: "page", Count: 1},
+		}))
+		Expect(aggregation.Buckets(logseq.FacetJournalMonth())).To(Equal([]logseq.Bucket{
+			{Term: "2024-01", Count: 2},
+			{Term: "2024-02", Count: 1},
+		}))
+	})
+
+	It("limits the number of buckets with WithSize", func() {
+		graph = openGraphWithPages(dir, map[string]string{
+			"a.md": "- [[A]] [[B]]\n- [[A]] [[C]]\n",
+		})
+
+		facet := logseq.FacetReferences().WithSize(1)
+		aggregation, err := graph.Aggregate(ctx, nil, facet)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(aggregation.Buckets(facet)).To(Equal([]logseq.Bucket{
+			{Term: "a", Count: 2},
+		}))
+	})
+})
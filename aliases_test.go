@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 
 	logseq "github.com/aholstenson/logseq-go"
+	"github.com/aholstenson/logseq-go/content"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -76,6 +77,17 @@ var _ = Describe("Aliases", func() {
 			Expect(aliasesOf("target")).To(Equal([]string{"Other", "Second Name"}))
 		})
 
+		It("splits aliases set as text on commas", func() {
+			graph = openGraphWithPages(dir, map[string]string{})
+
+			tx := graph.NewTransaction()
+			page, err := tx.OpenPage("target")
+			Expect(err).ToNot(HaveOccurred())
+
+			page.Properties().Set("alias", content.NewText("Other, Second Name"))
+			Expect(page.Aliases()).To(Equal([]string{"Other", "Second Name"}))
+		})
+
 		It("has no aliases without an alias property", func() {
 			graph = openGraphWithPages(dir, map[string]string{
 				"target.md": "- content of target\n",
@@ -0,0 +1,42 @@
+package logseq
+
+import "github.com/aholstenson/logseq-go/internal/indexing"
+
+// Analyzer splits text into the terms that are indexed and searched for, which
+// decides what a search such as ContentMatches finds. Use WithASCIIFolding to
+// also remove diacritics, so that `Übung` is found by `ubung`.
+type Analyzer = indexing.Analyzer
+
+// TextField is a field of text that an analyzer can be chosen for.
+type TextField = indexing.TextField
+
+const (
+	// TextFieldTitle is the title of pages, as searched by TitleMatches.
+	TextFieldTitle = indexing.TextFieldTitle
+	// TextFieldContent is the content of pages and blocks, as searched by
+	// ContentMatches, along with the text of their properties.
+	TextFieldContent = indexing.TextFieldContent
+)
+
+// StandardAnalyzer splits text into words and lower cases them. This is the
+// analyzer used unless another one is chosen.
+func StandardAnalyzer() *Analyzer {
+	return indexing.StandardAnalyzer()
+}
+
+// CJKAnalyzer splits Chinese, Japanese and Korean text into overlapping pairs
+// of characters, as these languages do not separate words with spaces. Text
+// in other languages is split into words without stemming, which makes it a
+// good choice for graphs that mix CJK with other languages.
+func CJKAnalyzer() *Analyzer {
+	return indexing.CJKAnalyzer()
+}
+
+// LanguageAnalyzer returns the analyzer for a language, given as its ISO
+// 639-1 code such as `de`. Languages such as English and German get an
+// analyzer that reduces words to their stem, so that `Häuser` finds `Haus`,
+// while `ja`, `zh` and `ko` get the CJK analyzer. Returns nil if the language
+// is not supported.
+func LanguageAnalyzer(language string) *Analyzer {
+	return indexing.LanguageAnalyzer(language)
+}
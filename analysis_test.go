@@ -0,0 +1,121 @@
+package logseq_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	logseq "github.com/aholstenson/logseq-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Text analysis", func() {
+	var (
+		graph *logseq.Graph
+		dir   string
+		ctx   context.Context
+	)
+
+	BeforeEach(func() {
+		dir = setupGraph()
+		ctx = context.Background()
+	})
+
+	AfterEach(func() {
+		if graph != nil {
+			graph.Close()
+			graph = nil
+		}
+	})
+
+	open := func(pages map[string]string, opts ...logseq.Option) *logseq.Graph {
+		for name, content := range pages {
+			Expect(os.WriteFile(
+				filepath.Join(dir, "pages", name),
+				[]byte(content),
+				0o644,
+			)).To(Succeed())
+		}
+
+		graph, err := logseq.Open(ctx, dir, opts...)
+		Expect(err).ToNot(HaveOccurred())
+		return graph
+	}
+
+	countBlocks := func(query logseq.Query) int {
+		results, err := graph.SearchBlocks(ctx, logseq.WithQuery(query))
+		Expect(err).ToNot(HaveOccurred())
+		return results.Size()
+	}
+
+	It("stems and folds German", func() {
+		graph = open(map[string]string{
+			"a.md": "- Die Häuser am See\n",
+		}, logseq.WithInMemoryIndex(), logseq.WithLanguage("de"))
+
+		Expect(countBlocks(logseq.ContentMatches("Haus"))).To(Equal(1))
+		Expect(countBlocks(logseq.ContentMatches("hauser"))).To(Equal(1))
+	})
+
+	It("splits Japanese into pairs of characters", func() {
+		graph = open(map[string]string{
+			"a.md": "- 東京都に住んでいます\n",
+		}, logseq.WithInMemoryIndex(), logseq.WithLanguage("ja"))
+
+		Expect(countBlocks(logseq.ContentMatches("東京"))).To(Equal(1))
+		Expect(countBlocks(logseq.ContentMatches("京東"))).To(Equal(0))
+	})
+
+	It("uses analyzers per field", func() {
+		graph = open(map[string]string{
+			"Café.md": "- Crème brûlée\n",
+		},
+			logseq.WithInMemoryIndex(),
+			logseq.WithAnalyzer(logseq.TextFieldContent, logseq.StandardAnalyzer().WithASCIIFolding()),
+		)
+
+		Expect(countBlocks(logseq.ContentMatches("creme brulee"))).To(Equal(1))
+
+		results, err := graph.SearchPages(ctx, logseq.WithQuery(logseq.TitleMatches("cafe")))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results.Size()).To(Equal(0))
+	})
+
+	It("rebuilds the index when the analyzers change", func() {
+		indexDir := filepath.Join(GinkgoT().TempDir(), "index")
+
+		graph = open(map[string]string{
+			"a.md": "- Die Häuser am See\n",
+		}, logseq.WithIndex(indexDir))
+		Expect(countBlocks(logseq.ContentMatches("Haus"))).To(Equal(0))
+		Expect(graph.Close()).To(Succeed())
+
+		graph = open(nil, logseq.WithIndex(indexDir), logseq.WithLanguage("de"))
+		Expect(countBlocks(logseq.ContentMatches("Haus"))).To(Equal(1))
+	})
+
+	It("leaves files it did not create in the index directory", func() {
+		indexDir := GinkgoT().TempDir()
+		notes := filepath.Join(indexDir, "notes.txt")
+		Expect(os.WriteFile(notes, []byte("keep me"), 0o644)).To(Succeed())
+
+		graph = open(map[string]string{
+			"a.md": "- Die Häuser am See\n",
+		}, logseq.WithIndex(indexDir))
+		Expect(countBlocks(logseq.ContentMatches("Häuser"))).To(Equal(1))
+		Expect(graph.Close()).To(Succeed())
+
+		graph = open(nil, logseq.WithIndex(indexDir), logseq.WithLanguage("de"))
+		Expect(countBlocks(logseq.ContentMatches("Haus"))).To(Equal(1))
+
+		data, err := os.ReadFile(notes)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal("keep me"))
+	})
+
+	It("fails for languages that are not supported", func() {
+		_, err := logseq.Open(ctx, dir, logseq.WithInMemoryIndex(), logseq.WithLanguage("tlh"))
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -94,9 +94,7 @@ func (b *Block) WithID() *Block {
 // manipulation of properties. Use FindProperties to look up properties without
 // changing the block.
 func (b *Block) Properties() *Properties {
-	if b.properties == nil {
-		b.properties = b.FindProperties()
-	}
+	b.properties = b.FindProperties()
 
 	if b.properties == nil {
 		properties := NewProperties()
@@ -122,7 +120,9 @@ func (b *Block) Properties() *Properties {
 // FindProperties gets the properties node for this block, returning nil if the
 // block does not have any. Unlike Properties this never changes the block.
 func (b *Block) FindProperties() *Properties {
-	if b.properties != nil {
+	// The properties may have been removed from the block since they were
+	// looked up, such as by Rewrite
+	if b.properties != nil && b.properties.Parent() == HasChildren(b) {
 		return b.properties
 	}
 
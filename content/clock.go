@@ -0,0 +1,96 @@
+package content
+
+import "time"
+
+// ClockIn starts the clock on the task of a block at now, which is what
+// Logseq does when work on a task starts. A task that is not NOW or DOING
+// becomes NOW if it was LATER and DOING otherwise, to keep the marker in line
+// with the clock. Returns the running clock, which is the one that was
+// already running if there is one.
+func ClockIn(block *Block, now time.Time) *LogbookEntryClock {
+	task := block.Task()
+	if status := task.Status(); !isDoingStatus(status) {
+		task.SetStatus(doingStatus(status), now)
+	}
+
+	return startClock(block, now)
+}
+
+// ClockOut stops the running clock on the task of a block at now. A task that
+// is NOW becomes LATER and one that is DOING becomes TODO, as work on it has
+// stopped. Returns the clock that was stopped, or nil if no clock was running.
+func ClockOut(block *Block, now time.Time) *LogbookEntryClock {
+	clock := stopClock(block, now)
+
+	task := block.Task()
+	if status := task.Status(); isDoingStatus(status) {
+		task.SetStatus(todoStatus(status), now)
+	}
+
+	return clock
+}
+
+// RunningClock gets the clock in the logbook that has not been stopped, or
+// nil if all of them have.
+func (l *Logbook) RunningClock() *LogbookEntryClock {
+	for node := l.LastChild(); node != nil; node = node.PreviousSibling() {
+		if clock, ok := node.(*LogbookEntryClock); ok && clock.IsRunning() {
+			return clock
+		}
+	}
+
+	return nil
+}
+
+// startClock adds a running clock to the logbook of a block, unless one is
+// running already.
+func startClock(block *Block, now time.Time) *LogbookEntryClock {
+	logbook := block.Logbook(true)
+	if clock := logbook.RunningClock(); clock != nil {
+		return clock
+	}
+
+	clock := NewLogbookEntryClock(now, time.Time{})
+	logbook.AddChild(clock)
+	return clock
+}
+
+// stopClock stops the running clock in the logbook of a block, returning nil
+// if there is none.
+func stopClock(block *Block, now time.Time) *LogbookEntryClock {
+	logbook := block.Logbook(false)
+	if logbook == nil {
+		return nil
+	}
+
+	clock := logbook.RunningClock()
+	if clock != nil {
+		clock.WithEnd(now)
+	}
+
+	return clock
+}
+
+// isDoingStatus checks if a status is one that Logseq tracks the time of.
+func isDoingStatus(status TaskStatus) bool {
+	return status == TaskStatusNow || status == TaskStatusDoing
+}
+
+// doingStatus picks the status a task gets when work on it starts, keeping to
+// the workflow the task is in.
+func doingStatus(from TaskStatus) TaskStatus {
+	if from == TaskStatusLater {
+		return TaskStatusNow
+	}
+
+	return TaskStatusDoing
+}
+
+// todoStatus picks the status a task goes back to when work on it stops.
+func todoStatus(from TaskStatus) TaskStatus {
+	if from == TaskStatusNow {
+		return TaskStatusLater
+	}
+
+	return TaskStatusTodo
+}
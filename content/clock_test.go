@@ -0,0 +1,84 @@
+package content_test
+
+import (
+	"time"
+
+	"github.com/aholstenson/logseq-go/content"
+	"github.com/aholstenson/logseq-go/internal/markdown"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Clock", func() {
+	start := time.Date(2023, 6, 30, 9, 0, 0, 0, time.Local)
+	end := start.Add(90 * time.Minute)
+
+	parse := func(src string) *content.Block {
+		root, err := markdown.ParseString(src)
+		Expect(err).ToNot(HaveOccurred())
+		return root.Blocks()[0]
+	}
+
+	write := func(block *content.Block) string {
+		out, err := markdown.AsString(block.Parent())
+		Expect(err).ToNot(HaveOccurred())
+		return out
+	}
+
+	It("starts and stops the clock on a task", func() {
+		block := parse("- TODO Write report\n")
+
+		running := content.ClockIn(block, start)
+		Expect(running.IsRunning()).To(BeTrue())
+		Expect(block.Task().Status()).To(Equal(content.TaskStatusDoing))
+		Expect(write(block)).To(Equal(
+			"- DOING Write report\n" +
+				"  :LOGBOOK:\n" +
+				"  CLOCK: [2023-06-30 Fri 09:00:00]\n" +
+				"  :END:",
+		))
+
+		stopped := content.ClockOut(block, end)
+		Expect(stopped).To(BeIdenticalTo(running))
+		Expect(stopped.Duration()).To(Equal(90 * time.Minute))
+		Expect(write(block)).To(Equal(
+			"- TODO Write report\n" +
+				"  :LOGBOOK:\n" +
+				"  CLOCK: [2023-06-30 Fri 09:00:00]--[2023-06-30 Fri 10:30:00] =>  01:30:00\n" +
+				"  :END:",
+		))
+	})
+
+	It("keeps to the LATER/NOW workflow", func() {
+		block := parse("- LATER Read\n")
+
+		content.ClockIn(block, start)
+		Expect(block.Task().Status()).To(Equal(content.TaskStatusNow))
+
+		content.ClockOut(block, end)
+		Expect(block.Task().Status()).To(Equal(content.TaskStatusLater))
+	})
+
+	It("keeps the clock that is already running", func() {
+		block := parse("- NOW Read\n" +
+			"  :LOGBOOK:\n" +
+			"  CLOCK: [2023-06-30 Fri 08:00:00]\n" +
+			"  :END:\n")
+
+		running := content.ClockIn(block, start)
+		Expect(running.Start).To(Equal(start.Add(-time.Hour)))
+		Expect(block.Logbook(false).Children()).To(HaveLen(1))
+	})
+
+	It("stops the clock when a task is completed", func() {
+		block := parse("- TODO Write report\n")
+
+		block.Task().SetStatus(content.TaskStatusDoing, start)
+		Expect(block.Logbook(false).RunningClock()).ToNot(BeNil())
+
+		block.Task().SetStatus(content.TaskStatusDone, end)
+		Expect(block.Logbook(false).RunningClock()).To(BeNil())
+		Expect(content.ClockOut(block, end)).To(BeNil())
+		Expect(block.Task().Status()).To(Equal(content.TaskStatusDone))
+	})
+})
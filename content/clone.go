@@ -0,0 +1,300 @@
+package content
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+type cloneOptions struct {
+	newIDs bool
+}
+
+// CloneOption is an option for Clone.
+type CloneOption func(*cloneOptions)
+
+// WithNewIDs gives every block in the copy that has an `id::` property a new
+// id, so that the copy can live in the same graph as the original. Block
+// references and embeds within the copy are updated to point at the new ids.
+func WithNewIDs() CloneOption {
+	return func(o *cloneOptions) {
+		o.newIDs = true
+	}
+}
+
+// Clone returns a deep copy of a node and everything below it. The copy is
+// detached, it has no parent or siblings, so it can be changed or added
+// somewhere else without affecting the original.
+func Clone[N Node](node N, opts ...CloneOption) N {
+	options := cloneOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	clone := cloneNode(node)
+	if options.newIDs {
+		regenerateIDs(clone)
+	}
+
+	return clone.(N)
+}
+
+// regenerateIDs replaces the ids of all the blocks in a tree, and then points
+// references within the tree at the new ids.
+func regenerateIDs(root Node) {
+	ids := make(map[string]string)
+	Walk(root, func(node Node, entering bool) WalkStatus {
+		block, ok := node.(*Block)
+		if !ok || !entering || block.FindProperties() == nil {
+			return WalkContinue
+		}
+
+		if id := block.ID(); id != "" {
+			ids[id] = uuid.NewString()
+			block.Properties().Set("id", NewText(ids[id]))
+		}
+		return WalkContinue
+	})
+
+	if len(ids) == 0 {
+		return
+	}
+
+	Walk(root, func(node Node, entering bool) WalkStatus {
+		switch n := node.(type) {
+		case *BlockRef:
+			if id, ok := ids[n.ID]; ok {
+				n.ID = id
+			}
+		case *BlockEmbed:
+			if id, ok := ids[n.ID]; ok {
+				n.ID = id
+			}
+		}
+		return WalkContinue
+	})
+}
+
+func cloneNode(node Node) Node {
+	switch n := node.(type) {
+	case *Block:
+		c := *n
+		// The properties are looked up again from the copied children
+		c.properties = nil
+		c.copyChildren(&c, n)
+		return &c
+	case *Properties:
+		c := *n
+		c.copyChildren(&c, n)
+		return &c
+	case *Property:
+		c := *n
+		c.copyChildren(&c, n)
+		return &c
+	case *Paragraph:
+		c := *n
+		c.copyChildren(&c, n)
+		return &c
+	case *Heading:
+		c := *n
+		c.copyChildren(&c, n)
+		return &c
+	case *Blockquote:
+		c := *n
+		c.copyChildren(&c, n)
+		return &c
+	case *List:
+		c := *n
+		c.copyChildren(&c, n)
+		return &c
+	case *ListSection:
+		c := *n
+		c.copyChildren(&c, n)
+		return &c
+	case *ListItem:
+		c := *n
+		c.copyChildren(&c, n)
+		return &c
+	case *Table:
+		c := *n
+		c.Alignments = append([]TableAlignment(nil), n.Alignments...)
+		c.copyChildren(&c, n)
+		return &c
+	case *TableRow:
+		c := *n
+		c.copyChildren(&c, n)
+		return &c
+	case *TableCell:
+		c := *n
+		c.copyChildren(&c, n)
+		return &c
+	case *Logbook:
+		c := *n
+		c.copyChildren(&c, n)
+		return &c
+	case *FootnoteDefinition:
+		c := *n
+		c.copyChildren(&c, n)
+		return &c
+	case *Emphasis:
+		c := *n
+		c.copyChildren(&c, n)
+		return &c
+	case *Strong:
+		c := *n
+		c.copyChildren(&c, n)
+		return &c
+	case *Strikethrough:
+		c := *n
+		c.copyChildren(&c, n)
+		return &c
+	case *Highlight:
+		c := *n
+		c.copyChildren(&c, n)
+		return &c
+	case *Link:
+		c := *n
+		c.copyChildren(&c, n)
+		return &c
+	case *Image:
+		c := *n
+		c.copyChildren(&c, n)
+		return &c
+	case *Text:
+		c := *n
+		c.baseNode = baseNode{}
+		return &c
+	case *RawText:
+		c := *n
+		c.baseNode = baseNode{}
+		return &c
+	case *CodeSpan:
+		c := *n
+		c.baseNode = baseNode{}
+		return &c
+	case *CodeBlock:
+		c := *n
+		c.baseNode = baseNode{}
+		return &c
+	case *ThematicBreak:
+		c := *n
+		c.baseNode = baseNode{}
+		return &c
+	case *RawHTML:
+		c := *n
+		c.baseNode = baseNode{}
+		return &c
+	case *RawHTMLBlock:
+		c := *n
+		c.baseNode = baseNode{}
+		return &c
+	case *Math:
+		c := *n
+		c.baseNode = baseNode{}
+		return &c
+	case *MathBlock:
+		c := *n
+		c.baseNode = baseNode{}
+		return &c
+	case *AutoLink:
+		c := *n
+		c.baseNode = baseNode{}
+		return &c
+	case *PageLink:
+		c := *n
+		c.baseNode = baseNode{}
+		return &c
+	case *PageRefText:
+		c := *n
+		c.baseNode = baseNode{}
+		return &c
+	case *Hashtag:
+		c := *n
+		c.baseNode = baseNode{}
+		return &c
+	case *BlockRef:
+		c := *n
+		c.baseNode = baseNode{}
+		return &c
+	case *FootnoteRef:
+		c := *n
+		c.baseNode = baseNode{}
+		return &c
+	case *Macro:
+		c := *n
+		c.baseNode = baseNode{}
+		c.Arguments = append([]string(nil), n.Arguments...)
+		return &c
+	case *Query:
+		c := *n
+		c.baseNode = baseNode{}
+		return &c
+	case *PageEmbed:
+		c := *n
+		c.baseNode = baseNode{}
+		return &c
+	case *BlockEmbed:
+		c := *n
+		c.baseNode = baseNode{}
+		return &c
+	case *Cloze:
+		c := *n
+		c.baseNode = baseNode{}
+		return &c
+	case *AdvancedCommand:
+		c := *n
+		c.baseNode = baseNode{}
+		return &c
+	case *QueryCommand:
+		c := *n
+		c.baseNode = baseNode{}
+		return &c
+	case *QuoteCommand:
+		c := *n
+		c.baseNode = baseNode{}
+		return &c
+	case *TaskMarker:
+		c := *n
+		c.baseNode = baseNode{}
+		return &c
+	case *TaskPriority:
+		c := *n
+		c.baseNode = baseNode{}
+		return &c
+	case *TaskDate:
+		c := *n
+		c.baseNode = baseNode{}
+		if n.Repeater != nil {
+			repeater := *n.Repeater
+			c.Repeater = &repeater
+		}
+		return &c
+	case *LogbookEntryRaw:
+		c := *n
+		c.baseNode = baseNode{}
+		return &c
+	case *LogbookEntryClock:
+		c := *n
+		c.baseNode = baseNode{}
+		return &c
+	case *LogbookEntryStateChange:
+		c := *n
+		c.baseNode = baseNode{}
+		return &c
+	}
+
+	panic(fmt.Sprintf("content: can not clone %T", node))
+}
+
+// copyChildren detaches a shallow copy of a node and adds copies of the
+// children of the original to it.
+func (c *baseNodeWithChildren) copyChildren(self HasChildren, original HasChildren) {
+	c.baseNode = baseNode{}
+	c.firstChild = nil
+	c.lastChild = nil
+	c.self = self
+
+	for child := original.FirstChild(); child != nil; child = child.NextSibling() {
+		c.AddChild(cloneNode(child))
+	}
+}
@@ -0,0 +1,76 @@
+package content_test
+
+import (
+	"github.com/aholstenson/logseq-go/content"
+	. "github.com/aholstenson/logseq-go/internal/tests"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Clone", func() {
+	It("copies a tree of nodes", func() {
+		block := content.NewBlock(
+			content.NewParagraph(
+				content.NewText("Read "),
+				content.NewPageLink("Dune"),
+				content.NewStrong(content.NewText("now")),
+			),
+			content.NewProperties(
+				content.NewProperty("rating", content.NewText("5")),
+			),
+			content.NewBlock(content.NewParagraph(content.NewText("child"))),
+		)
+
+		Expect(content.Clone(block)).To(EqualNode(block))
+	})
+
+	It("detaches the copy from the parent of the original", func() {
+		child := content.NewBlock(content.NewParagraph(content.NewText("child")))
+		parent := content.NewBlock(child)
+
+		clone := content.Clone(child)
+		Expect(clone.Parent()).To(BeNil())
+		Expect(clone.NextSibling()).To(BeNil())
+
+		parent.AddChild(clone)
+		Expect(parent.Blocks()).To(HaveLen(2))
+		Expect(parent.Blocks()[0]).To(BeIdenticalTo(child))
+	})
+
+	It("leaves the original alone when the copy is changed", func() {
+		text := content.NewText("original")
+		block := content.NewBlock(
+			content.NewParagraph(text),
+			content.NewProperties(
+				content.NewProperty("status", content.NewText("open")),
+			),
+		)
+
+		clone := content.Clone(block)
+		clone.Properties().Set("status", content.NewText("closed"))
+		clone.Children().FindDeep(content.IsOfType[*content.Text]()).(*content.Text).Value = "changed"
+
+		Expect(text.Value).To(Equal("original"))
+		Expect(block.Properties().Get("status")).To(EqualsNodes(content.NewText("open")))
+	})
+
+	It("gives blocks new ids when asked to", func() {
+		child := content.NewBlock(content.NewParagraph(content.NewText("child"))).WithID()
+		plain := content.NewBlock(content.NewParagraph(content.NewText("plain")))
+		block := content.NewBlock(
+			content.NewParagraph(content.NewText("see "), content.NewBlockRef(child.ID())),
+			child,
+			plain,
+		)
+
+		clone := content.Clone(block, content.WithNewIDs())
+		clonedChild := clone.Blocks()[0]
+
+		Expect(clonedChild.ID()).ToNot(BeEmpty())
+		Expect(clonedChild.ID()).ToNot(Equal(child.ID()))
+		Expect(clone.Blocks()[1].FindProperties()).To(BeNil())
+
+		ref := clone.Children().FindDeep(content.IsOfType[*content.BlockRef]()).(*content.BlockRef)
+		Expect(ref.ID).To(Equal(clonedChild.ID()))
+	})
+})
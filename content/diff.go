@@ -0,0 +1,910 @@
+package content
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// BlockPath is where a block is in a tree, as the index of the block among the
+// blocks of its parent for every level from the root down to the block.
+type BlockPath []int
+
+// String returns the indexes of the path separated by dots, such as `0.2`.
+func (p BlockPath) String() string {
+	parts := make([]string, len(p))
+	for idx, position := range p {
+		parts[idx] = strconv.Itoa(position)
+	}
+	return strings.Join(parts, ".")
+}
+
+// less checks if the path comes before another path in the document.
+func (p BlockPath) less(other BlockPath) bool {
+	for idx := 0; idx < len(p) && idx < len(other); idx++ {
+		if p[idx] != other[idx] {
+			return p[idx] < other[idx]
+		}
+	}
+	return len(p) < len(other)
+}
+
+// ChangeType is the kind of change made to a block.
+type ChangeType int
+
+const (
+	// ChangeInsert is a block that was added.
+	ChangeInsert ChangeType = iota
+	// ChangeDelete is a block that was removed, along with its sub blocks.
+	ChangeDelete
+	// ChangeMove is a block that was moved to another place in the tree.
+	ChangeMove
+	// ChangeText is a block whose content changed.
+	ChangeText
+	// ChangeTaskStatus is a block whose task status changed.
+	ChangeTaskStatus
+	// ChangeProperty is a block where a property was added, changed or
+	// removed.
+	ChangeProperty
+)
+
+func (t ChangeType) String() string {
+	switch t {
+	case ChangeInsert:
+		return "insert"
+	case ChangeDelete:
+		return "delete"
+	case ChangeMove:
+		return "move"
+	case ChangeText:
+		return "text"
+	case ChangeTaskStatus:
+		return "task status"
+	case ChangeProperty:
+		return "property"
+	}
+
+	return ""
+}
+
+// Change is a single change between two trees of blocks, as found by Diff.
+type Change struct {
+	Type ChangeType
+
+	// OldPath is where the block was in the old tree. Not set for inserts.
+	OldPath BlockPath
+	// NewPath is where the block is in the new tree. Not set for deletes.
+	NewPath BlockPath
+
+	// Block is a copy of the block without its sub blocks. For inserts this
+	// is the block to insert, for deletes it is the block that was removed.
+	Block *Block
+
+	// OldText is the text of the block before a text change.
+	OldText string
+	// NewText is the text of the block after a text change.
+	NewText string
+	// Edits is how OldText was turned into NewText, word by word, for text
+	// changes.
+	Edits []TextEdit
+	// Content is a copy of everything in the block except its sub blocks
+	// after a text change.
+	Content NodeList
+
+	// OldStatus is the task status before a task status change.
+	OldStatus TaskStatus
+	// NewStatus is the task status after a task status change.
+	NewStatus TaskStatus
+
+	// Property is the name of the property for property changes.
+	Property string
+	// OldValue is the value of the property before it changed, nil if the
+	// property was added.
+	OldValue NodeList
+	// NewValue is the value of the property after it changed, nil if the
+	// property was removed.
+	NewValue NodeList
+}
+
+// TextEditType is the kind of a text edit.
+type TextEditType int
+
+const (
+	// TextEditKeep is text that is in both the old and the new text.
+	TextEditKeep TextEditType = iota
+	// TextEditInsert is text that was added.
+	TextEditInsert
+	// TextEditDelete is text that was removed.
+	TextEditDelete
+)
+
+func (t TextEditType) String() string {
+	switch t {
+	case TextEditKeep:
+		return "keep"
+	case TextEditInsert:
+		return "insert"
+	case TextEditDelete:
+		return "delete"
+	}
+
+	return ""
+}
+
+// TextEdit is a run of words in the text of a block that was kept, inserted
+// or deleted. The old text is the kept and deleted runs one after the other,
+// and the new text is the kept and inserted runs.
+type TextEdit struct {
+	Type TextEditType
+	Text string
+}
+
+// String describes the change.
+func (c Change) String() string {
+	switch c.Type {
+	case ChangeInsert:
+		return fmt.Sprintf("inserted %s: %q", c.NewPath, diffText(c.Block.Content()))
+	case ChangeDelete:
+		return fmt.Sprintf("deleted %s: %q", c.OldPath, diffText(c.Block.Content()))
+	case ChangeMove:
+		return fmt.Sprintf("moved %s to %s", c.OldPath, c.NewPath)
+	case ChangeText:
+		return fmt.Sprintf("changed text of %s from %q to %q", c.NewPath, c.OldText, c.NewText)
+	case ChangeTaskStatus:
+		return fmt.Sprintf("changed task status of %s from %s to %s", c.NewPath, taskStatusName(c.OldStatus), taskStatusName(c.NewStatus))
+	case ChangeProperty:
+		switch {
+		case c.OldValue == nil:
+			return fmt.Sprintf("added property %s to %s: %q", c.Property, c.NewPath, diffText(c.NewValue))
+		case c.NewValue == nil:
+			return fmt.Sprintf("removed property %s from %s", c.Property, c.NewPath)
+		default:
+			return fmt.Sprintf("changed property %s of %s from %q to %q", c.Property, c.NewPath, diffText(c.OldValue), diffText(c.NewValue))
+		}
+	}
+
+	return ""
+}
+
+func taskStatusName(status TaskStatus) string {
+	if status == TaskStatusNone {
+		return "none"
+	}
+	return status.String()
+}
+
+// Changes are the changes between two trees of blocks. They can be applied to
+// the old tree with Patch to turn it into the new tree.
+type Changes []Change
+
+// String describes the changes, one change per line.
+func (c Changes) String() string {
+	var b strings.Builder
+	for _, change := range c {
+		b.WriteString(change.String())
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Diff finds the changes between two trees of blocks, such as the root blocks
+// of two versions of a page.
+//
+// Blocks are first matched by their `id::` property, then by having the same
+// content, and last by having similar content in the same place. Blocks in the
+// new tree that do not match any block are inserts, and blocks in the old tree
+// that do not match any block are deletes. Blocks that match but ended up
+// somewhere else are moves, and for every matched block changes to its text,
+// task status and properties are reported.
+//
+// Deletes come first in the order of the old tree, followed by the other
+// changes in the order of the new tree.
+func Diff(oldRoot *Block, newRoot *Block) Changes {
+	d := &differ{
+		oldPaths:   map[*Block]BlockPath{oldRoot: {}},
+		newPaths:   map[*Block]BlockPath{newRoot: {}},
+		oldPartner: map[*Block]*Block{oldRoot: newRoot},
+		newPartner: map[*Block]*Block{newRoot: oldRoot},
+	}
+
+	d.oldOrder = collectBlocks(oldRoot, d.oldPaths)
+	d.newOrder = collectBlocks(newRoot, d.newPaths)
+
+	d.matchByID()
+	d.matchByContent()
+	d.matchBySimilarity(oldRoot, newRoot)
+
+	return d.changes(newRoot)
+}
+
+type differ struct {
+	oldPaths map[*Block]BlockPath
+	newPaths map[*Block]BlockPath
+
+	oldOrder []*Block
+	newOrder []*Block
+
+	// oldPartner maps blocks in the old tree to the blocks they match in the
+	// new tree, and newPartner does the opposite
+	oldPartner map[*Block]*Block
+	newPartner map[*Block]*Block
+}
+
+// collectBlocks finds all the blocks below the root in document order,
+// recording their paths.
+func collectBlocks(root *Block, paths map[*Block]BlockPath) []*Block {
+	order := make([]*Block, 0)
+
+	var collect func(block *Block, path BlockPath)
+	collect = func(block *Block, path BlockPath) {
+		for idx, child := range block.Blocks() {
+			childPath := make(BlockPath, len(path)+1)
+			copy(childPath, path)
+			childPath[len(path)] = idx
+
+			paths[child] = childPath
+			order = append(order, child)
+			collect(child, childPath)
+		}
+	}
+	collect(root, BlockPath{})
+
+	return order
+}
+
+func (d *differ) match(oldBlock *Block, newBlock *Block) {
+	d.oldPartner[oldBlock] = newBlock
+	d.newPartner[newBlock] = oldBlock
+}
+
+func (d *differ) matchByID() {
+	byID := make(map[string]*Block)
+	for _, block := range d.oldOrder {
+		if id := diffBlockID(block); id != "" {
+			if _, ok := byID[id]; !ok {
+				byID[id] = block
+			}
+		}
+	}
+
+	for _, block := range d.newOrder {
+		id := diffBlockID(block)
+		if id == "" {
+			continue
+		}
+
+		if oldBlock, ok := byID[id]; ok && d.oldPartner[oldBlock] == nil {
+			d.match(oldBlock, block)
+		}
+	}
+}
+
+func (d *differ) matchByContent() {
+	// Blocks are grouped by their text first, and then compared in full
+	type candidate struct {
+		block   *Block
+		content NodeList
+	}
+
+	byText := make(map[string][]candidate)
+	for _, block := range d.oldOrder {
+		if d.oldPartner[block] == nil {
+			content := diffContent(block)
+			text := diffText(content)
+			byText[text] = append(byText[text], candidate{block: block, content: content})
+		}
+	}
+
+	for _, block := range d.newOrder {
+		if d.newPartner[block] != nil {
+			continue
+		}
+
+		content := diffContent(block)
+		text := diffText(content)
+		candidates := byText[text]
+		for idx, c := range candidates {
+			if equalNodeLists(c.content, content) {
+				d.match(c.block, block)
+				byText[text] = append(candidates[:idx:idx], candidates[idx+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// matchBySimilarity pairs up the blocks that are left below matched parents
+// if their text is similar, which finds blocks that have been edited.
+func (d *differ) matchBySimilarity(oldRoot *Block, newRoot *Block) {
+	d.pairChildren(oldRoot, newRoot)
+
+	// Blocks are visited parent first, so children matched here are paired
+	// when they are reached
+	for _, block := range d.newOrder {
+		if oldBlock := d.newPartner[block]; oldBlock != nil {
+			d.pairChildren(oldBlock, block)
+		}
+	}
+}
+
+func (d *differ) pairChildren(oldParent *Block, newParent *Block) {
+	oldChildren := make([]*Block, 0)
+	for _, block := range oldParent.Blocks() {
+		if d.oldPartner[block] == nil {
+			oldChildren = append(oldChildren, block)
+		}
+	}
+
+	next := 0
+	for _, block := range newParent.Blocks() {
+		if d.newPartner[block] != nil {
+			continue
+		}
+
+		text := diffText(block.Content())
+		for idx := next; idx < len(oldChildren); idx++ {
+			if similarText(diffText(oldChildren[idx].Content()), text) {
+				d.match(oldChildren[idx], block)
+				next = idx + 1
+				break
+			}
+		}
+	}
+}
+
+// moved finds the matched blocks that are no longer in the same place. Blocks
+// that kept their parent are only moved if they are out of order compared to
+// the other blocks that kept their parent, so that inserting or deleting a
+// block does not move the blocks around it.
+func (d *differ) moved(newRoot *Block) map[*Block]bool {
+	moved := make(map[*Block]bool)
+
+	for _, parent := range append([]*Block{newRoot}, d.newOrder...) {
+		oldParent := d.newPartner[parent]
+
+		stayed := make([]*Block, 0)
+		for _, block := range parent.Blocks() {
+			oldBlock := d.newPartner[block]
+			if oldBlock == nil {
+				continue
+			}
+
+			if oldParent == nil || oldBlock.Parent() != HasChildren(oldParent) {
+				moved[block] = true
+				continue
+			}
+
+			stayed = append(stayed, block)
+		}
+
+		positions := make([]int, len(stayed))
+		for idx, block := range stayed {
+			path := d.oldPaths[d.newPartner[block]]
+			positions[idx] = path[len(path)-1]
+		}
+
+		inOrder := longestIncreasing(positions)
+		for idx, block := range stayed {
+			if !inOrder[idx] {
+				moved[block] = true
+			}
+		}
+	}
+
+	return moved
+}
+
+// longestIncreasing marks the values that make up the longest increasing
+// sequence of values.
+func longestIncreasing(values []int) []bool {
+	lengths := make([]int, len(values))
+	previous := make([]int, len(values))
+	best := -1
+	for i := range values {
+		lengths[i] = 1
+		previous[i] = -1
+		for j := 0; j < i; j++ {
+			if values[j] < values[i] && lengths[j]+1 > lengths[i] {
+				lengths[i] = lengths[j] + 1
+				previous[i] = j
+			}
+		}
+
+		if best < 0 || lengths[i] > lengths[best] {
+			best = i
+		}
+	}
+
+	marked := make([]bool, len(values))
+	for i := best; i >= 0; i = previous[i] {
+		marked[i] = true
+	}
+	return marked
+}
+
+func (d *differ) changes(newRoot *Block) Changes {
+	changes := make(Changes, 0)
+
+	for _, block := range d.oldOrder {
+		if d.oldPartner[block] != nil {
+			continue
+		}
+
+		// Blocks below a deleted block go with it
+		if parent, ok := block.Parent().(*Block); ok && d.oldPartner[parent] == nil {
+			continue
+		}
+
+		changes = append(changes, Change{
+			Type:    ChangeDelete,
+			OldPath: d.oldPaths[block],
+			Block:   withoutBlocks(block),
+		})
+	}
+
+	moved := d.moved(newRoot)
+	for _, block := range d.newOrder {
+		oldBlock := d.newPartner[block]
+		if oldBlock == nil {
+			changes = append(changes, Change{
+				Type:    ChangeInsert,
+				NewPath: d.newPaths[block],
+				Block:   withoutBlocks(block),
+			})
+			continue
+		}
+
+		oldPath := d.oldPaths[oldBlock]
+		newPath := d.newPaths[block]
+
+		if moved[block] {
+			changes = append(changes, Change{
+				Type:    ChangeMove,
+				OldPath: oldPath,
+				NewPath: newPath,
+			})
+		}
+
+		if !equalNodeLists(diffContent(oldBlock), diffContent(block)) {
+			oldText := diffText(oldBlock.Content())
+			newText := diffText(block.Content())
+			content := make(NodeList, 0)
+			for _, node := range block.Content() {
+				content = append(content, Clone(node))
+			}
+
+			changes = append(changes, Change{
+				Type:    ChangeText,
+				OldPath: oldPath,
+				NewPath: newPath,
+				OldText: oldText,
+				NewText: newText,
+				Edits:   textEdits(oldText, newText),
+				Content: content,
+			})
+		}
+
+		if oldStatus, newStatus := blockTaskStatus(oldBlock), blockTaskStatus(block); oldStatus != newStatus {
+			changes = append(changes, Change{
+				Type:      ChangeTaskStatus,
+				OldPath:   oldPath,
+				NewPath:   newPath,
+				OldStatus: oldStatus,
+				NewStatus: newStatus,
+			})
+		}
+
+		changes = append(changes, propertyChanges(oldBlock, block, oldPath, newPath)...)
+	}
+
+	return changes
+}
+
+func propertyChanges(oldBlock *Block, newBlock *Block, oldPath BlockPath, newPath BlockPath) Changes {
+	oldProperties := blockProperties(oldBlock)
+	newProperties := blockProperties(newBlock)
+
+	changes := make(Changes, 0)
+	change := func(name string, oldValue NodeList, newValue NodeList) {
+		changes = append(changes, Change{
+			Type:     ChangeProperty,
+			OldPath:  oldPath,
+			NewPath:  newPath,
+			Property: name,
+			OldValue: oldValue,
+			NewValue: newValue,
+		})
+	}
+
+	for _, property := range oldProperties {
+		newProperty := findProperty(newProperties, property.Name)
+		if newProperty == nil {
+			change(property.Name, cloneNodes(property.Children()), nil)
+		} else if !equalNodeLists(property.Children(), newProperty.Children()) {
+			change(property.Name, cloneNodes(property.Children()), cloneNodes(newProperty.Children()))
+		}
+	}
+
+	for _, property := range newProperties {
+		if findProperty(oldProperties, property.Name) == nil {
+			change(property.Name, nil, cloneNodes(property.Children()))
+		}
+	}
+
+	return changes
+}
+
+func blockProperties(block *Block) []*Property {
+	properties := make([]*Property, 0)
+	if p := block.FindProperties(); p != nil {
+		for node := p.FirstChild(); node != nil; node = node.NextSibling() {
+			if property, ok := node.(*Property); ok {
+				properties = append(properties, property)
+			}
+		}
+	}
+	return properties
+}
+
+func findProperty(properties []*Property, name string) *Property {
+	for _, property := range properties {
+		if property.Name == name {
+			return property
+		}
+	}
+	return nil
+}
+
+func cloneNodes(nodes NodeList) NodeList {
+	clones := make(NodeList, len(nodes))
+	for idx, node := range nodes {
+		clones[idx] = Clone(node)
+	}
+	return clones
+}
+
+// withoutBlocks returns a copy of a block without its sub blocks.
+func withoutBlocks(block *Block) *Block {
+	clone := Clone(block)
+	for _, child := range clone.Blocks() {
+		clone.RemoveChild(child)
+	}
+	return clone
+}
+
+func diffBlockID(block *Block) string {
+	if block.FindProperties() == nil {
+		return ""
+	}
+	return block.ID()
+}
+
+func blockTaskStatus(block *Block) TaskStatus {
+	if marker, ok := block.Content().FindDeep(IsOfType[*TaskMarker]()).(*TaskMarker); ok {
+		return marker.Status
+	}
+	return TaskStatusNone
+}
+
+// diffContent returns a copy of the content of a block without its
+// properties, task status and sub blocks, which is what decides if two blocks
+// have the same content.
+func diffContent(block *Block) NodeList {
+	content := make(NodeList, 0)
+	for _, node := range block.Content() {
+		switch node.(type) {
+		case *Properties, *TaskMarker:
+			continue
+		}
+
+		clone := Clone(node)
+		if children, ok := clone.(HasChildren); ok {
+			Rewrite(children, func(cursor *Cursor) WalkStatus {
+				if _, ok := cursor.Node().(*TaskMarker); ok {
+					cursor.Remove()
+				}
+				return WalkContinue
+			})
+		}
+		content = append(content, clone)
+	}
+
+	return content
+}
+
+// equalNodeLists checks if two lists of nodes are equal, ignoring how they
+// are separated from the lines before them.
+func equalNodeLists(a NodeList, b NodeList) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for idx := range a {
+		if !Equal(a[idx], b[idx], IgnorePreviousLineType()) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// diffText returns the text of nodes as it is shown in changes.
+func diffText(nodes NodeList) string {
+	return nodes.PlainText(WithPageRefBrackets(), WithoutProperties(), WithoutLogbooks())
+}
+
+// maxTextEditCells limits how large the table of kept words in textEdits can
+// get, texts with more words than this between them are replaced as a whole.
+const maxTextEditCells = 1 << 20
+
+// textEdits finds the words to keep, insert and delete to turn one text into
+// another, keeping as many words as possible. Spaces are kept with the words,
+// so that the edits put together give back the texts.
+func textEdits(oldText string, newText string) []TextEdit {
+	oldWords := splitWords(oldText)
+	newWords := splitWords(newText)
+
+	edits := make([]TextEdit, 0)
+	add := func(editType TextEditType, word string) {
+		if last := len(edits) - 1; last >= 0 && edits[last].Type == editType {
+			edits[last].Text += word
+			return
+		}
+
+		edits = append(edits, TextEdit{Type: editType, Text: word})
+	}
+
+	// Words at the start and the end that are the same are always kept, so
+	// only the words between them need to be compared
+	prefix := 0
+	for prefix < len(oldWords) && prefix < len(newWords) && oldWords[prefix] == newWords[prefix] {
+		add(TextEditKeep, oldWords[prefix])
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(oldWords)-prefix && suffix < len(newWords)-prefix &&
+		oldWords[len(oldWords)-1-suffix] == newWords[len(newWords)-1-suffix] {
+		suffix++
+	}
+
+	oldMiddle := oldWords[prefix : len(oldWords)-suffix]
+	newMiddle := newWords[prefix : len(newWords)-suffix]
+
+	if (len(oldMiddle)+1)*(len(newMiddle)+1) > maxTextEditCells {
+		add(TextEditDelete, strings.Join(oldMiddle, ""))
+		add(TextEditInsert, strings.Join(newMiddle, ""))
+	} else {
+		keptWords(oldMiddle, newMiddle, add)
+	}
+
+	for _, word := range oldWords[len(oldWords)-suffix:] {
+		add(TextEditKeep, word)
+	}
+
+	return edits
+}
+
+// keptWords calls add with the words to keep, insert and delete to turn the
+// old words into the new words, keeping as many words as possible.
+func keptWords(oldWords []string, newWords []string, add func(TextEditType, string)) {
+	// kept[i][j] is how many words can be kept between the words from i on
+	// in the old text and the words from j on in the new text
+	kept := make([][]int, len(oldWords)+1)
+	for i := range kept {
+		kept[i] = make([]int, len(newWords)+1)
+	}
+
+	for i := len(oldWords) - 1; i >= 0; i-- {
+		for j := len(newWords) - 1; j >= 0; j-- {
+			if oldWords[i] == newWords[j] {
+				kept[i][j] = kept[i+1][j+1] + 1
+			} else if kept[i+1][j] >= kept[i][j+1] {
+				kept[i][j] = kept[i+1][j]
+			} else {
+				kept[i][j] = kept[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(oldWords) || j < len(newWords) {
+		switch {
+		case i < len(oldWords) && j < len(newWords) && oldWords[i] == newWords[j]:
+			add(TextEditKeep, oldWords[i])
+			i++
+			j++
+		case i < len(oldWords) && (j == len(newWords) || kept[i+1][j] >= kept[i][j+1]):
+			add(TextEditDelete, oldWords[i])
+			i++
+		default:
+			add(TextEditInsert, newWords[j])
+			j++
+		}
+	}
+}
+
+// splitWords splits a text into words and the spaces between them.
+func splitWords(text string) []string {
+	words := make([]string, 0)
+	start := 0
+	for idx, r := range text {
+		if idx == start {
+			continue
+		}
+
+		previous, _ := utf8.DecodeLastRuneInString(text[:idx])
+		if unicode.IsSpace(previous) != unicode.IsSpace(r) {
+			words = append(words, text[start:idx])
+			start = idx
+		}
+	}
+
+	if start < len(text) {
+		words = append(words, text[start:])
+	}
+
+	return words
+}
+
+// similarText checks if two texts share at least half of their words, counted
+// across both texts.
+func similarText(a string, b string) bool {
+	wordsA := strings.Fields(a)
+	wordsB := strings.Fields(b)
+	if len(wordsA) == 0 || len(wordsB) == 0 {
+		return len(wordsA) == len(wordsB)
+	}
+
+	counts := make(map[string]int)
+	for _, word := range wordsA {
+		counts[word]++
+	}
+
+	shared := 0
+	for _, word := range wordsB {
+		if counts[word] > 0 {
+			counts[word]--
+			shared++
+		}
+	}
+
+	return 4*shared >= len(wordsA)+len(wordsB)
+}
+
+// Patch applies changes found by Diff to the old tree, turning it into the
+// new tree. Either all of the changes are applied or, if any of them can not
+// be, such as when a block they refer to does not exist, none of them are and
+// the tree is left as it was.
+func Patch(root *Block, changes Changes) error {
+	// The changes are tried on a copy first, as a change that fails may come
+	// after others that have already been applied
+	err := applyChanges(Clone(root), changes)
+	if err != nil {
+		return err
+	}
+
+	return applyChanges(root, changes)
+}
+
+// applyChanges applies changes to a tree, stopping at the first change that
+// can not be applied.
+func applyChanges(root *Block, changes Changes) error {
+	blocks := make([]*Block, len(changes))
+	for idx, change := range changes {
+		if change.Type == ChangeInsert {
+			continue
+		}
+
+		block, err := blockAtPath(root, change.OldPath)
+		if err != nil {
+			return err
+		}
+		blocks[idx] = block
+	}
+
+	for idx, change := range changes {
+		block := blocks[idx]
+		switch change.Type {
+		case ChangeText:
+			for _, node := range block.Content() {
+				block.RemoveChild(node)
+			}
+
+			first := block.FirstChild()
+			for _, node := range change.Content {
+				if first == nil {
+					block.AddChild(Clone(node))
+				} else {
+					block.InsertChildBefore(Clone(node), first)
+				}
+			}
+		}
+	}
+
+	for idx, change := range changes {
+		block := blocks[idx]
+		switch change.Type {
+		case ChangeTaskStatus:
+			setBlockTaskStatus(block, change.NewStatus)
+		case ChangeProperty:
+			if change.NewValue == nil {
+				if properties := block.FindProperties(); properties != nil {
+					properties.Remove(change.Property)
+				}
+			} else {
+				block.Properties().Set(change.Property, cloneNodes(change.NewValue)...)
+			}
+		}
+	}
+
+	// Moved blocks are taken out before blocks are deleted, as they may have
+	// been below a deleted block
+	for idx, change := range changes {
+		if change.Type == ChangeMove {
+			blocks[idx].RemoveSelf()
+		}
+	}
+
+	for idx, change := range changes {
+		if change.Type == ChangeDelete {
+			blocks[idx].RemoveSelf()
+		}
+	}
+
+	// Blocks are placed in document order, so that the blocks before them and
+	// their parents are in place when they are placed
+	placed := make([]int, 0)
+	for idx, change := range changes {
+		if change.Type == ChangeInsert || change.Type == ChangeMove {
+			placed = append(placed, idx)
+		}
+	}
+
+	sort.SliceStable(placed, func(i, j int) bool {
+		return changes[placed[i]].NewPath.less(changes[placed[j]].NewPath)
+	})
+
+	for _, idx := range placed {
+		change := changes[idx]
+		if len(change.NewPath) == 0 {
+			return fmt.Errorf("no path to place block at")
+		}
+
+		block := blocks[idx]
+		if change.Type == ChangeInsert {
+			block = Clone(change.Block)
+		}
+
+		parentPath := change.NewPath[:len(change.NewPath)-1]
+		parent, err := blockAtPath(root, parentPath)
+		if err != nil {
+			return err
+		}
+
+		siblings := parent.Blocks()
+		position := change.NewPath[len(change.NewPath)-1]
+		if position < len(siblings) {
+			parent.InsertChildBefore(block, siblings[position])
+		} else {
+			parent.AddChild(block)
+		}
+	}
+
+	return nil
+}
+
+func blockAtPath(root *Block, path BlockPath) (*Block, error) {
+	block := root
+	for idx, position := range path {
+		blocks := block.Blocks()
+		if position < 0 || position >= len(blocks) {
+			return nil, fmt.Errorf("no block at %s", path[:idx+1])
+		}
+		block = blocks[position]
+	}
+	return block, nil
+}
@@ -0,0 +1,195 @@
+package content_test
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/aholstenson/logseq-go/content"
+	"github.com/aholstenson/logseq-go/internal/markdown"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Diff", func() {
+	parse := func(src string) *content.Block {
+		block, err := markdown.ParseString(src)
+		Expect(err).ToNot(HaveOccurred())
+		return block
+	}
+
+	write := func(block *content.Block) string {
+		src, err := markdown.AsString(block)
+		Expect(err).ToNot(HaveOccurred())
+		return src
+	}
+
+	// expectPatch checks that patching the old tree gives the new tree.
+	expectPatch := func(oldSrc string, newSrc string, changes content.Changes) {
+		root := parse(oldSrc)
+		Expect(content.Patch(root, changes)).To(Succeed())
+		Expect(write(root)).To(Equal(write(parse(newSrc))))
+	}
+
+	It("finds nothing in trees that are the same", func() {
+		src := "- a\n- b\n\t- c\n"
+		Expect(content.Diff(parse(src), parse(src))).To(BeEmpty())
+	})
+
+	It("finds inserted and deleted blocks", func() {
+		oldSrc := "- first\n- second\n\t- below\n- third\n"
+		newSrc := "- first\n- third\n- fourth\n"
+
+		changes := content.Diff(parse(oldSrc), parse(newSrc))
+		Expect(changes.String()).To(Equal(
+			"deleted 1: \"second\"\n" +
+				"inserted 2: \"fourth\"\n",
+		))
+		expectPatch(oldSrc, newSrc, changes)
+	})
+
+	It("finds moved blocks", func() {
+		oldSrc := "- a\n- b\n- c\n\t- d\n"
+		newSrc := "- c\n- a\n\t- d\n- b\n"
+
+		changes := content.Diff(parse(oldSrc), parse(newSrc))
+		Expect(changes.String()).To(Equal(
+			"moved 2 to 0\n" +
+				"moved 2.0 to 1.0\n",
+		))
+		expectPatch(oldSrc, newSrc, changes)
+	})
+
+	It("matches blocks by id before content", func() {
+		oldSrc := "- one\n  id:: 6579b2a0-4e4e-4b0f-9d5c-ae8d6b0f3a1b\n- two\n"
+		newSrc := "- two\n- changed entirely\n  id:: 6579b2a0-4e4e-4b0f-9d5c-ae8d6b0f3a1b\n"
+
+		changes := content.Diff(parse(oldSrc), parse(newSrc))
+		Expect(changes).To(HaveLen(2))
+		Expect(changes[0].Type).To(Equal(content.ChangeMove))
+		Expect(changes[1].Type).To(Equal(content.ChangeText))
+		Expect(changes[1].OldText).To(Equal("one"))
+		Expect(changes[1].NewText).To(Equal("changed entirely"))
+		expectPatch(oldSrc, newSrc, changes)
+	})
+
+	It("finds edited text, task status and properties", func() {
+		oldSrc := "- TODO write the report\n  owner:: alice\n  due:: friday\n- other\n"
+		newSrc := "- DONE write the final report\n  owner:: bob\n  done:: true\n- other\n"
+
+		changes := content.Diff(parse(oldSrc), parse(newSrc))
+		Expect(changes.String()).To(Equal(
+			"changed text of 0 from \"write the report\" to \"write the final report\"\n" +
+				"changed task status of 0 from TODO to DONE\n" +
+				"changed property owner of 0 from \"alice\" to \"bob\"\n" +
+				"removed property due from 0\n" +
+				"added property done to 0: \"true\"\n",
+		))
+		expectPatch(oldSrc, newSrc, changes)
+	})
+
+	It("finds the words that were edited", func() {
+		changes := content.Diff(parse("- write the report today\n"), parse("- write the final report now\n"))
+		Expect(changes).To(HaveLen(1))
+		Expect(changes[0].Edits).To(Equal([]content.TextEdit{
+			{Type: content.TextEditKeep, Text: "write the "},
+			{Type: content.TextEditInsert, Text: "final "},
+			{Type: content.TextEditKeep, Text: "report "},
+			{Type: content.TextEditDelete, Text: "today"},
+			{Type: content.TextEditInsert, Text: "now"},
+		}))
+	})
+
+	It("replaces the edited words of long texts as a whole", func() {
+		words := func(word string) string {
+			return strings.TrimSpace(strings.Repeat(word+" ", 2000))
+		}
+		oldText := "start " + words("old") + " end"
+		newText := "start " + words("new") + " end"
+
+		id := "\n  id:: 6579b2a0-4e4e-4b0f-9d5c-ae8d6b0f3a1b\n"
+		changes := content.Diff(parse("- "+oldText+id), parse("- "+newText+id))
+		Expect(changes).To(HaveLen(1))
+		Expect(changes[0].Edits).To(Equal([]content.TextEdit{
+			{Type: content.TextEditKeep, Text: "start "},
+			{Type: content.TextEditDelete, Text: words("old")},
+			{Type: content.TextEditInsert, Text: words("new")},
+			{Type: content.TextEditKeep, Text: " end"},
+		}))
+	})
+
+	It("writes changes as JSON that can be patched with", func() {
+		oldSrc := "- TODO write the report\n  owner:: alice\n  due:: friday\n- gone\n- other\n"
+		newSrc := "- other\n- DONE write the final report\n  owner:: bob\n  done:: true\n- added [[Page]]\n"
+
+		changes := content.Diff(parse(oldSrc), parse(newSrc))
+		data, err := json.Marshal(changes)
+		Expect(err).ToNot(HaveOccurred())
+
+		var read content.Changes
+		Expect(json.Unmarshal(data, &read)).To(Succeed())
+		Expect(read.String()).To(Equal(changes.String()))
+		Expect(read).To(HaveLen(len(changes)))
+		for idx := range read {
+			Expect(read[idx].Edits).To(Equal(changes[idx].Edits))
+			Expect(read[idx].NewValue == nil).To(Equal(changes[idx].NewValue == nil))
+		}
+		expectPatch(oldSrc, newSrc, read)
+	})
+
+	It("fails to read changes of an unknown type", func() {
+		var read content.Changes
+		err := json.Unmarshal([]byte(`{"version":1,"changes":[{"type":"rename"}]}`), &read)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("finds edited arguments of macros", func() {
+		oldSrc := "- {{x \"a, b\"}}\n"
+		newSrc := "- {{x a, b}}\n"
+
+		changes := content.Diff(parse(oldSrc), parse(newSrc))
+		Expect(changes).To(HaveLen(1))
+		Expect(changes[0].Type).To(Equal(content.ChangeText))
+		expectPatch(oldSrc, newSrc, changes)
+	})
+
+	It("patches changes to only the task status or properties", func() {
+		oldSrc := "- TODO task\n  owner:: alice\n"
+		newSrc := "- DONE task\n  owner:: bob\n"
+
+		changes := content.Diff(parse(oldSrc), parse(newSrc))
+		Expect(changes).To(HaveLen(2))
+		expectPatch(oldSrc, newSrc, changes)
+	})
+
+	It("keeps blocks moved out of a deleted block", func() {
+		oldSrc := "- parent\n\t- kept\n\t- dropped\n- sibling\n"
+		newSrc := "- sibling\n\t- kept\n"
+
+		changes := content.Diff(parse(oldSrc), parse(newSrc))
+		expectPatch(oldSrc, newSrc, changes)
+	})
+
+	It("places blocks moved into an inserted block", func() {
+		oldSrc := "- a\n- b\n"
+		newSrc := "- group\n\t- a\n\t- b\n"
+
+		changes := content.Diff(parse(oldSrc), parse(newSrc))
+		expectPatch(oldSrc, newSrc, changes)
+	})
+
+	It("refuses patches for blocks that do not exist", func() {
+		changes := content.Diff(parse("- a\n- b\n- c\n"), parse("- a\n"))
+		Expect(content.Patch(parse("- a\n"), changes)).ToNot(Succeed())
+	})
+
+	It("leaves the tree as it was when a change can not be applied", func() {
+		root := parse("- TODO a\n- b\n")
+		changes := content.Changes{
+			{Type: content.ChangeTaskStatus, OldPath: content.BlockPath{0}, NewPath: content.BlockPath{0}, NewStatus: content.TaskStatusDone},
+			{Type: content.ChangeMove, OldPath: content.BlockPath{1}, NewPath: content.BlockPath{3, 0}},
+		}
+
+		Expect(content.Patch(root, changes)).ToNot(Succeed())
+		Expect(write(root)).To(Equal("- TODO a\n- b"))
+	})
+})
@@ -0,0 +1,279 @@
+package content
+
+import "fmt"
+
+type equalOptions struct {
+	ignoreIDs              bool
+	ignorePreviousLineType bool
+}
+
+// EqualOption is an option for Equal.
+type EqualOption func(*equalOptions)
+
+// IgnoreIDs makes Equal ignore the `id::` property of blocks, such as when
+// looking for copies of a block across pages.
+func IgnoreIDs() EqualOption {
+	return func(o *equalOptions) {
+		o.ignoreIDs = true
+	}
+}
+
+// IgnorePreviousLineType makes Equal ignore the type of the line before block
+// nodes, which only affects how the nodes are written as Markdown.
+func IgnorePreviousLineType() EqualOption {
+	return func(o *equalOptions) {
+		o.ignorePreviousLineType = true
+	}
+}
+
+// Equal checks if two nodes have the same structure, meaning that they are of
+// the same type, have the same values and have children that are equal. Where
+// the nodes are in their trees does not matter.
+func Equal(a Node, b Node, opts ...EqualOption) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	options := equalOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.ignoreIDs {
+		a = withoutIDs(a)
+		b = withoutIDs(b)
+	}
+
+	return equalNodes(a, b, &options)
+}
+
+// equalNodes compares two nodes and their children, one level at a time.
+func equalNodes(a Node, b Node, options *equalOptions) bool {
+	if !equalValues(a, b) {
+		return false
+	}
+
+	if !options.ignorePreviousLineType {
+		if aware, ok := a.(PreviousLineAware); ok && aware.PreviousLineType() != b.(PreviousLineAware).PreviousLineType() {
+			return false
+		}
+	}
+
+	parentA, ok := a.(HasChildren)
+	if !ok {
+		return true
+	}
+
+	childA := parentA.FirstChild()
+	childB := b.(HasChildren).FirstChild()
+	for childA != nil && childB != nil {
+		if !equalNodes(childA, childB, options) {
+			return false
+		}
+
+		childA = childA.NextSibling()
+		childB = childB.NextSibling()
+	}
+
+	return childA == nil && childB == nil
+}
+
+// equalValues checks that two nodes are of the same type and that the values
+// of their fields are the same, leaving out their children.
+func equalValues(a Node, b Node) bool {
+	switch a := a.(type) {
+	case *Block:
+		b, ok := b.(*Block)
+		return ok && a.preBlock == b.preBlock
+	case *Properties:
+		_, ok := b.(*Properties)
+		return ok
+	case *Property:
+		b, ok := b.(*Property)
+		return ok && a.Name == b.Name && a.PageRefsIgnored == b.PageRefsIgnored && a.SeparatedByCommas == b.SeparatedByCommas
+	case *Paragraph:
+		_, ok := b.(*Paragraph)
+		return ok
+	case *Heading:
+		b, ok := b.(*Heading)
+		return ok && a.Level == b.Level
+	case *Blockquote:
+		_, ok := b.(*Blockquote)
+		return ok
+	case *List:
+		b, ok := b.(*List)
+		return ok && a.Type == b.Type && a.Marker == b.Marker
+	case *ListSection:
+		b, ok := b.(*ListSection)
+		return ok && a.marker == b.marker
+	case *ListItem:
+		_, ok := b.(*ListItem)
+		return ok
+	case *Table:
+		b, ok := b.(*Table)
+		return ok && equalSlices(a.Alignments, b.Alignments)
+	case *TableRow:
+		_, ok := b.(*TableRow)
+		return ok
+	case *TableCell:
+		_, ok := b.(*TableCell)
+		return ok
+	case *Logbook:
+		_, ok := b.(*Logbook)
+		return ok
+	case *FootnoteDefinition:
+		b, ok := b.(*FootnoteDefinition)
+		return ok && a.Label == b.Label
+	case *Emphasis:
+		_, ok := b.(*Emphasis)
+		return ok
+	case *Strong:
+		_, ok := b.(*Strong)
+		return ok
+	case *Strikethrough:
+		_, ok := b.(*Strikethrough)
+		return ok
+	case *Highlight:
+		_, ok := b.(*Highlight)
+		return ok
+	case *Link:
+		b, ok := b.(*Link)
+		return ok && a.URL == b.URL && a.Title == b.Title
+	case *Image:
+		b, ok := b.(*Image)
+		return ok && a.URL == b.URL && a.Title == b.Title
+	case *Text:
+		b, ok := b.(*Text)
+		return ok && a.Value == b.Value && a.HardLineBreak == b.HardLineBreak && a.SoftLineBreak == b.SoftLineBreak
+	case *RawText:
+		b, ok := b.(*RawText)
+		return ok && a.Value == b.Value
+	case *CodeSpan:
+		b, ok := b.(*CodeSpan)
+		return ok && a.Value == b.Value
+	case *CodeBlock:
+		b, ok := b.(*CodeBlock)
+		return ok && a.Language == b.Language && a.Code == b.Code
+	case *ThematicBreak:
+		_, ok := b.(*ThematicBreak)
+		return ok
+	case *RawHTML:
+		b, ok := b.(*RawHTML)
+		return ok && a.HTML == b.HTML
+	case *RawHTMLBlock:
+		b, ok := b.(*RawHTMLBlock)
+		return ok && a.HTML == b.HTML
+	case *Math:
+		b, ok := b.(*Math)
+		return ok && a.Value == b.Value && a.Displayed == b.Displayed
+	case *MathBlock:
+		b, ok := b.(*MathBlock)
+		return ok && a.Value == b.Value
+	case *AutoLink:
+		b, ok := b.(*AutoLink)
+		return ok && a.URL == b.URL
+	case *PageLink:
+		b, ok := b.(*PageLink)
+		return ok && a.To == b.To
+	case *PageRefText:
+		b, ok := b.(*PageRefText)
+		return ok && a.To == b.To
+	case *Hashtag:
+		b, ok := b.(*Hashtag)
+		return ok && a.To == b.To
+	case *BlockRef:
+		b, ok := b.(*BlockRef)
+		return ok && a.ID == b.ID
+	case *FootnoteRef:
+		b, ok := b.(*FootnoteRef)
+		return ok && a.Label == b.Label
+	case *Macro:
+		b, ok := b.(*Macro)
+		return ok && a.Name == b.Name && equalSlices(a.Arguments, b.Arguments)
+	case *Query:
+		b, ok := b.(*Query)
+		return ok && a.Query == b.Query
+	case *PageEmbed:
+		b, ok := b.(*PageEmbed)
+		return ok && a.To == b.To
+	case *BlockEmbed:
+		b, ok := b.(*BlockEmbed)
+		return ok && a.ID == b.ID
+	case *Cloze:
+		b, ok := b.(*Cloze)
+		return ok && a.Answer == b.Answer && a.Cue == b.Cue
+	case *AdvancedCommand:
+		b, ok := b.(*AdvancedCommand)
+		return ok && a.Type == b.Type && a.Value == b.Value
+	case *QueryCommand:
+		b, ok := b.(*QueryCommand)
+		return ok && a.Query == b.Query
+	case *QuoteCommand:
+		b, ok := b.(*QuoteCommand)
+		return ok && a.Quote == b.Quote
+	case *TaskMarker:
+		b, ok := b.(*TaskMarker)
+		return ok && a.Status == b.Status
+	case *TaskPriority:
+		b, ok := b.(*TaskPriority)
+		return ok && a.Priority == b.Priority
+	case *TaskDate:
+		b, ok := b.(*TaskDate)
+		if !ok || a.Type != b.Type || !a.Date.Equal(b.Date) || a.HasTime != b.HasTime {
+			return false
+		}
+
+		if a.Repeater == nil || b.Repeater == nil {
+			return a.Repeater == nil && b.Repeater == nil
+		}
+
+		return *a.Repeater == *b.Repeater
+	case *LogbookEntryRaw:
+		b, ok := b.(*LogbookEntryRaw)
+		return ok && a.Value == b.Value
+	case *LogbookEntryClock:
+		b, ok := b.(*LogbookEntryClock)
+		return ok && a.Start.Equal(b.Start) && a.End.Equal(b.End)
+	case *LogbookEntryStateChange:
+		b, ok := b.(*LogbookEntryStateChange)
+		return ok && a.From == b.From && a.To == b.To && a.Time.Equal(b.Time)
+	}
+
+	panic(fmt.Sprintf("content: can not compare %T", a))
+}
+
+func equalSlices[T comparable](a []T, b []T) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for idx := range a {
+		if a[idx] != b[idx] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// withoutIDs returns a copy of a tree where the `id::` property of every block
+// has been removed, along with properties that only held an id.
+func withoutIDs(node Node) Node {
+	clone := Clone(node)
+	Walk(clone, func(node Node, entering bool) WalkStatus {
+		block, ok := node.(*Block)
+		if !ok || !entering {
+			return WalkContinue
+		}
+
+		if properties := block.FindProperties(); properties != nil {
+			properties.Remove("id")
+			if properties.FirstChild() == nil {
+				block.RemoveChild(properties)
+			}
+		}
+		return WalkContinue
+	})
+
+	return clone
+}
@@ -0,0 +1,56 @@
+package content_test
+
+import (
+	"github.com/aholstenson/logseq-go/content"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Equal", func() {
+	It("compares the structure of nodes", func() {
+		a := content.NewBlock(content.NewParagraph(content.NewText("a "), content.NewPageLink("Page")))
+		b := content.NewBlock(content.NewParagraph(content.NewText("a "), content.NewPageLink("Page")))
+		c := content.NewBlock(content.NewParagraph(content.NewText("a "), content.NewPageLink("Other")))
+
+		Expect(content.Equal(a, b)).To(BeTrue())
+		Expect(content.Equal(a, c)).To(BeFalse())
+		Expect(content.Equal(a, nil)).To(BeFalse())
+	})
+
+	It("compares the arguments of macros one by one", func() {
+		Expect(content.Equal(content.NewMacro("x", "a, b"), content.NewMacro("x", "a", "b"))).To(BeFalse())
+		Expect(content.Equal(content.NewMacro("x", "a", "b"), content.NewMacro("x", "a", "b"))).To(BeTrue())
+	})
+
+	It("compares pre-blocks with regular blocks", func() {
+		a := content.NewPreBlock(content.NewParagraph(content.NewText("text")))
+		b := content.NewBlock(content.NewParagraph(content.NewText("text")))
+
+		Expect(content.Equal(a, b)).To(BeFalse())
+	})
+
+	It("compares text without mixing it up with other nodes", func() {
+		a := content.NewParagraph(content.NewText("a"), content.NewText("b"))
+		b := content.NewParagraph(content.NewText("a'\n  Text 'b"))
+
+		Expect(content.Equal(a, b)).To(BeFalse())
+		Expect(content.Equal(a, content.NewParagraph(content.NewText("ab")))).To(BeFalse())
+	})
+
+	It("ignores ids when asked to", func() {
+		a := content.NewBlock(content.NewParagraph(content.NewText("text"))).WithID()
+		b := content.NewBlock(content.NewParagraph(content.NewText("text")))
+
+		Expect(content.Equal(a, b)).To(BeFalse())
+		Expect(content.Equal(a, b, content.IgnoreIDs())).To(BeTrue())
+		Expect(a.ID()).ToNot(BeEmpty())
+	})
+
+	It("ignores the previous line type when asked to", func() {
+		a := content.NewParagraph(content.NewText("text")).WithPreviousLineType(content.PreviousLineTypeBlank)
+		b := content.NewParagraph(content.NewText("text"))
+
+		Expect(content.Equal(a, b)).To(BeFalse())
+		Expect(content.Equal(a, b, content.IgnorePreviousLineType())).To(BeTrue())
+	})
+})
@@ -0,0 +1,684 @@
+// Package html renders content as HTML, such as for publishing pages on a
+// website.
+//
+// Text is escaped and links are limited to safe schemes, so content from a
+// graph can not inject markup. Raw HTML in the content is written as text
+// unless WithRawHTML is used.
+package html
+
+import (
+	"fmt"
+	stdhtml "html"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/aholstenson/logseq-go/content"
+)
+
+// PageResolver returns the URL of a page, used for page links, tags and page
+// embeds. An empty URL writes the reference without a link.
+type PageResolver func(title string) string
+
+// BlockResolver returns the URL of a block and the text to show for it, used
+// for block references and block embeds. An empty URL writes the reference
+// without a link, and an empty text shows the id of the block.
+type BlockResolver func(id string) (url string, text string)
+
+// Option changes how content is rendered.
+type Option func(*options)
+
+type options struct {
+	pageResolver  PageResolver
+	blockResolver BlockResolver
+	rawHTML       bool
+}
+
+// WithPageResolver sets how page references are turned into links. Without
+// it page references are written without links.
+func WithPageResolver(resolver PageResolver) Option {
+	return func(o *options) {
+		o.pageResolver = resolver
+	}
+}
+
+// WithBlockResolver sets how block references are turned into links. Without
+// it block references are written as their id without links.
+func WithBlockResolver(resolver BlockResolver) Option {
+	return func(o *options) {
+		o.blockResolver = resolver
+	}
+}
+
+// WithRawHTML writes the raw HTML in the content as it is. Only use this for
+// content that is trusted, as the HTML is not checked in any way.
+func WithRawHTML() Option {
+	return func(o *options) {
+		o.rawHTML = true
+	}
+}
+
+// renderer keeps track of the HTML written so far.
+type renderer struct {
+	out  strings.Builder
+	opts options
+}
+
+// AsString renders a node and everything below it as HTML.
+func AsString(n content.Node, opts ...Option) (string, error) {
+	w := &renderer{}
+	for _, opt := range opts {
+		opt(&w.opts)
+	}
+
+	if err := w.write(n); err != nil {
+		return "", err
+	}
+
+	return w.out.String(), nil
+}
+
+// Write renders a node and everything below it as HTML to a writer.
+func Write(n content.Node, out io.Writer, opts ...Option) error {
+	html, err := AsString(n, opts...)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(out, html)
+	return err
+}
+
+func (w *renderer) write(n content.Node) error {
+	switch node := n.(type) {
+	case *content.Block:
+		return w.writeBlock(node)
+	case *content.Text:
+		w.writeText(node)
+	case *content.RawText:
+		w.writeEscaped(node.Value)
+	case *content.RawHTML:
+		w.writeRawHTML(node.HTML)
+	case *content.RawHTMLBlock:
+		w.writeRawHTML(node.HTML)
+	case *content.Emphasis:
+		return w.writeElement("em", "", node)
+	case *content.Strong:
+		return w.writeElement("strong", "", node)
+	case *content.Strikethrough:
+		return w.writeElement("del", "", node)
+	case *content.Highlight:
+		return w.writeElement("mark", "", node)
+	case *content.CodeSpan:
+		w.writeRaw("<code>")
+		w.writeEscaped(node.Value)
+		w.writeRaw("</code>")
+	case *content.CodeBlock:
+		w.writeCodeBlock(node)
+	case *content.Math:
+		w.writeMath(node.Value, node.Displayed)
+	case *content.MathBlock:
+		w.writeMath(node.Value, true)
+	case *content.Link:
+		return w.writeLink(node)
+	case *content.AutoLink:
+		w.writeAnchor("", node.URL, "")
+		w.writeEscaped(node.URL)
+		w.writeRaw("</a>")
+	case *content.Image:
+		w.writeImage(node)
+	case *content.PageLink:
+		w.writePageRef("page-ref", node.To, node.To)
+	case *content.PageRefText:
+		w.writePageRef("page-ref", node.To, node.To)
+	case *content.Hashtag:
+		w.writePageRef("tag", node.To, "#"+node.To)
+	case *content.BlockRef:
+		w.writeBlockRef("block-ref", node.ID)
+	case *content.FootnoteRef:
+		w.writeFootnoteRef(node)
+	case *content.FootnoteDefinition:
+		return w.writeFootnoteDefinition(node)
+	case *content.Macro:
+		w.writeMacro(node)
+	case *content.Query:
+		w.writeRaw(`<div class="query"><code>`)
+		w.writeEscaped(node.Query)
+		w.writeRaw("</code></div>")
+	case *content.PageEmbed:
+		w.writeRaw(`<div class="embed page-embed">`)
+		w.writePageRef("page-ref", node.To, node.To)
+		w.writeRaw("</div>")
+	case *content.BlockEmbed:
+		w.writeRaw(`<div class="embed block-embed">`)
+		w.writeBlockRef("block-ref", node.ID)
+		w.writeRaw("</div>")
+	case *content.Cloze:
+		w.writeCloze(node)
+	case *content.Heading:
+		return w.writeHeading(node)
+	case *content.Paragraph:
+		return w.writeElement("p", "", node)
+	case *content.Blockquote:
+		return w.writeElement("blockquote", "", node)
+	case *content.List:
+		return w.writeList(node)
+	case *content.ListSection:
+		return w.writeChildren(node)
+	case *content.ListItem:
+		return w.writeElement("li", "", node)
+	case *content.Table:
+		return w.writeTable(node)
+	case *content.ThematicBreak:
+		w.writeRaw("<hr>")
+	case *content.Properties:
+		return w.writeProperties(node)
+	case *content.Property:
+		return w.writeProperty(node)
+	case *content.AdvancedCommand:
+		w.writeRaw(`<pre class="command command-` + escapeClass(node.Type) + `">`)
+		w.writeEscaped(node.Value)
+		w.writeRaw("</pre>")
+	case *content.QueryCommand:
+		w.writeRaw(`<div class="query"><code>`)
+		w.writeEscaped(node.Query)
+		w.writeRaw("</code></div>")
+	case *content.QuoteCommand:
+		w.writeRaw("<blockquote>")
+		w.writeEscaped(node.Quote)
+		w.writeRaw("</blockquote>")
+	case *content.TaskMarker:
+		w.writeTaskMarker(node)
+	case *content.TaskPriority:
+		w.writeTaskPriority(node)
+	case *content.TaskDate:
+		return w.writeTaskDate(node)
+	case *content.Logbook:
+		return w.writeLogbook(node)
+	default:
+		return fmt.Errorf("unsupported node: %T", node)
+	}
+
+	return nil
+}
+
+func (w *renderer) writeRaw(s string) {
+	w.out.WriteString(s)
+}
+
+func (w *renderer) writeEscaped(s string) {
+	w.out.WriteString(stdhtml.EscapeString(s))
+}
+
+func (w *renderer) writeChildren(node content.HasChildren) error {
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		if err := w.write(child); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeElement writes a node as an element around its children.
+func (w *renderer) writeElement(tag string, class string, node content.HasChildren) error {
+	w.writeRaw("<" + tag)
+	if class != "" {
+		w.writeRaw(` class="` + class + `"`)
+	}
+	w.writeRaw(">")
+
+	if err := w.writeChildren(node); err != nil {
+		return err
+	}
+
+	w.writeRaw("</" + tag + ">")
+	return nil
+}
+
+// writeBlock writes the content of a block followed by its sub blocks as a
+// list. The root block of a page has no content, so it is written as just the
+// list of the blocks on the page.
+func (w *renderer) writeBlock(node *content.Block) error {
+	inList := false
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		block, ok := child.(*content.Block)
+		if !ok {
+			if err := w.write(child); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !inList {
+			w.writeRaw(`<ul class="blocks">`)
+			inList = true
+		}
+
+		w.writeRaw(`<li class="` + blockClasses(block) + `">`)
+		if err := w.writeBlock(block); err != nil {
+			return err
+		}
+		w.writeRaw("</li>")
+
+		if _, ok := child.NextSibling().(*content.Block); !ok {
+			w.writeRaw("</ul>")
+			inList = false
+		}
+	}
+
+	return nil
+}
+
+// blockClasses returns the classes of the list item of a block, which tell
+// if the block is a task and what its status is.
+func blockClasses(block *content.Block) string {
+	classes := "block"
+	if block.IsPreBlock() {
+		classes += " pre-block"
+	}
+
+	marker, ok := block.Content().FindDeep(content.IsOfType[*content.TaskMarker]()).(*content.TaskMarker)
+	if ok && marker.Status != content.TaskStatusNone {
+		classes += " task " + taskStatusClass(marker.Status)
+	}
+
+	return classes
+}
+
+func taskStatusClass(status content.TaskStatus) string {
+	return "task-" + strings.ToLower(status.String())
+}
+
+func (w *renderer) writeText(node *content.Text) {
+	w.writeEscaped(node.Value)
+	if node.HardLineBreak {
+		w.writeRaw("<br>\n")
+	} else if node.SoftLineBreak {
+		w.writeRaw("\n")
+	}
+}
+
+func (w *renderer) writeRawHTML(html string) {
+	if w.opts.rawHTML {
+		w.writeRaw(html)
+	} else {
+		w.writeEscaped(html)
+	}
+}
+
+func (w *renderer) writeCodeBlock(node *content.CodeBlock) {
+	w.writeRaw("<pre><code")
+	if node.Language != "" {
+		w.writeRaw(` class="language-` + escapeClass(node.Language) + `"`)
+	}
+	w.writeRaw(">")
+	w.writeEscaped(node.Code)
+	w.writeRaw("</code></pre>")
+}
+
+// writeMath writes math with the delimiters that the auto-render extension of
+// KaTeX looks for.
+func (w *renderer) writeMath(value string, displayed bool) {
+	if displayed {
+		w.writeRaw(`<div class="math display">\[`)
+		w.writeEscaped(value)
+		w.writeRaw(`\]</div>`)
+	} else {
+		w.writeRaw(`<span class="math inline">\(`)
+		w.writeEscaped(value)
+		w.writeRaw(`\)</span>`)
+	}
+}
+
+// writeAnchor starts a link, leaving it to the caller to write what is linked
+// and to close it.
+func (w *renderer) writeAnchor(class string, href string, title string) {
+	w.writeRaw("<a")
+	if class != "" {
+		w.writeRaw(` class="` + class + `"`)
+	}
+	w.writeRaw(` href="` + stdhtml.EscapeString(safeURL(href)) + `"`)
+	if title != "" {
+		w.writeRaw(` title="` + stdhtml.EscapeString(title) + `"`)
+	}
+	w.writeRaw(">")
+}
+
+func (w *renderer) writeLink(node *content.Link) error {
+	w.writeAnchor("", node.URL, node.Title)
+	if err := w.writeChildren(node); err != nil {
+		return err
+	}
+	w.writeRaw("</a>")
+	return nil
+}
+
+func (w *renderer) writeImage(node *content.Image) {
+	alt := content.PlainText(node)
+
+	w.writeRaw(`<img src="` + stdhtml.EscapeString(safeURL(node.URL)) + `"`)
+	w.writeRaw(` alt="` + stdhtml.EscapeString(alt) + `"`)
+	if node.Title != "" {
+		w.writeRaw(` title="` + stdhtml.EscapeString(node.Title) + `"`)
+	}
+	w.writeRaw(">")
+}
+
+func (w *renderer) writePageRef(class string, title string, text string) {
+	href := ""
+	if w.opts.pageResolver != nil {
+		href = w.opts.pageResolver(title)
+	}
+
+	w.writeReference(class, href, text)
+}
+
+func (w *renderer) writeBlockRef(class string, id string) {
+	href, text := "", ""
+	if w.opts.blockResolver != nil {
+		href, text = w.opts.blockResolver(id)
+	}
+
+	if text == "" {
+		text = id
+	}
+
+	w.writeReference(class, href, text)
+}
+
+// writeReference writes a reference as a link, or as a span if there is
+// nowhere to link to.
+func (w *renderer) writeReference(class string, href string, text string) {
+	if href == "" {
+		w.writeRaw(`<span class="` + class + `">`)
+		w.writeEscaped(text)
+		w.writeRaw("</span>")
+		return
+	}
+
+	w.writeAnchor(class, href, "")
+	w.writeEscaped(text)
+	w.writeRaw("</a>")
+}
+
+func (w *renderer) writeFootnoteRef(node *content.FootnoteRef) {
+	label := stdhtml.EscapeString(node.Label)
+	w.writeRaw(`<sup class="footnote-ref"><a href="#fn-` + label + `" id="fnref-` + label + `">`)
+	w.writeRaw(label)
+	w.writeRaw("</a></sup>")
+}
+
+func (w *renderer) writeFootnoteDefinition(node *content.FootnoteDefinition) error {
+	label := stdhtml.EscapeString(node.Label)
+	w.writeRaw(`<div class="footnote" id="fn-` + label + `">`)
+	w.writeRaw(`<a class="footnote-backref" href="#fnref-` + label + `">` + label + "</a> ")
+	if err := w.writeChildren(node); err != nil {
+		return err
+	}
+	w.writeRaw("</div>")
+	return nil
+}
+
+func (w *renderer) writeMacro(node *content.Macro) {
+	w.writeRaw(`<span class="macro" data-name="` + stdhtml.EscapeString(node.Name) + `">`)
+	w.writeEscaped("{{" + node.Name)
+	for idx, argument := range node.Arguments {
+		if idx == 0 {
+			w.writeRaw(" ")
+		} else {
+			w.writeRaw(", ")
+		}
+		w.writeEscaped(argument)
+	}
+	w.writeEscaped("}}")
+	w.writeRaw("</span>")
+}
+
+func (w *renderer) writeCloze(node *content.Cloze) {
+	w.writeRaw(`<span class="cloze"`)
+	if node.Cue != "" {
+		w.writeRaw(` title="` + stdhtml.EscapeString(node.Cue) + `"`)
+	}
+	w.writeRaw(">")
+	w.writeEscaped(node.Answer)
+	w.writeRaw("</span>")
+}
+
+func (w *renderer) writeHeading(node *content.Heading) error {
+	level := node.Level
+	if level < 1 {
+		level = 1
+	} else if level > 6 {
+		level = 6
+	}
+
+	return w.writeElement("h"+strconv.Itoa(level), "", node)
+}
+
+func (w *renderer) writeList(node *content.List) error {
+	if node.Type == content.ListTypeOrdered {
+		return w.writeElement("ol", "", node)
+	}
+
+	return w.writeElement("ul", "", node)
+}
+
+func (w *renderer) writeTable(node *content.Table) error {
+	w.writeRaw("<table>")
+
+	for row := node.FirstChild(); row != nil; row = row.NextSibling() {
+		header := row == node.FirstChild()
+		if header {
+			w.writeRaw("<thead>")
+		} else if row.PreviousSibling() == node.FirstChild() {
+			w.writeRaw("<tbody>")
+		}
+
+		if err := w.writeTableRow(node, row, header); err != nil {
+			return err
+		}
+
+		if header {
+			w.writeRaw("</thead>")
+		} else if row.NextSibling() == nil {
+			w.writeRaw("</tbody>")
+		}
+	}
+
+	w.writeRaw("</table>")
+	return nil
+}
+
+func (w *renderer) writeTableRow(table *content.Table, node content.Node, header bool) error {
+	row, ok := node.(*content.TableRow)
+	if !ok {
+		return fmt.Errorf("unsupported table child: %T", node)
+	}
+
+	tag := "td"
+	if header {
+		tag = "th"
+	}
+
+	w.writeRaw("<tr>")
+
+	column := 0
+	for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+		w.writeRaw("<" + tag)
+		if column < len(table.Alignments) {
+			switch table.Alignments[column] {
+			case content.TableAlignmentLeft:
+				w.writeRaw(` style="text-align: left"`)
+			case content.TableAlignmentRight:
+				w.writeRaw(` style="text-align: right"`)
+			case content.TableAlignmentCenter:
+				w.writeRaw(` style="text-align: center"`)
+			}
+		}
+		w.writeRaw(">")
+
+		if children, ok := cell.(content.HasChildren); ok {
+			if err := w.writeChildren(children); err != nil {
+				return err
+			}
+		}
+
+		w.writeRaw("</" + tag + ">")
+		column++
+	}
+
+	w.writeRaw("</tr>")
+	return nil
+}
+
+func (w *renderer) writeProperties(node *content.Properties) error {
+	if node.FirstChild() == nil {
+		return nil
+	}
+
+	return w.writeElement("dl", "properties", node)
+}
+
+func (w *renderer) writeProperty(node *content.Property) error {
+	w.writeRaw("<dt>")
+	w.writeEscaped(node.Name)
+	w.writeRaw("</dt><dd>")
+
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		if child != node.FirstChild() {
+			w.writeRaw(", ")
+		}
+
+		if err := w.write(child); err != nil {
+			return err
+		}
+	}
+
+	w.writeRaw("</dd>")
+	return nil
+}
+
+func (w *renderer) writeTaskMarker(node *content.TaskMarker) {
+	if node.Status == content.TaskStatusNone {
+		return
+	}
+
+	w.writeRaw(`<span class="task-marker ` + taskStatusClass(node.Status) + `">`)
+	w.writeEscaped(node.Status.String())
+	w.writeRaw("</span> ")
+}
+
+func (w *renderer) writeTaskPriority(node *content.TaskPriority) {
+	var name string
+	switch node.Priority {
+	case content.PriorityA:
+		name = "A"
+	case content.PriorityB:
+		name = "B"
+	case content.PriorityC:
+		name = "C"
+	default:
+		return
+	}
+
+	w.writeRaw(`<span class="priority priority-` + strings.ToLower(name) + `">`)
+	w.writeRaw(name)
+	w.writeRaw("</span> ")
+}
+
+func (w *renderer) writeTaskDate(node *content.TaskDate) error {
+	var class, label string
+	switch node.Type {
+	case content.TaskDateTypeScheduled:
+		class, label = "scheduled", "SCHEDULED"
+	case content.TaskDateTypeDeadline:
+		class, label = "deadline", "DEADLINE"
+	default:
+		return fmt.Errorf("unsupported task date type: %d", node.Type)
+	}
+
+	datetime := node.Date.Format("2006-01-02")
+	text := node.Date.Format("2006-01-02 Mon")
+	if node.HasTime {
+		datetime = node.Date.Format("2006-01-02T15:04")
+		text += node.Date.Format(" 15:04")
+	}
+
+	if node.Repeater != nil {
+		text += " " + node.Repeater.String()
+	}
+
+	w.writeRaw(`<div class="task-date ` + class + `">` + label + `: `)
+	w.writeRaw(`<time datetime="` + datetime + `">`)
+	w.writeEscaped(text)
+	w.writeRaw("</time></div>")
+	return nil
+}
+
+func (w *renderer) writeLogbook(node *content.Logbook) error {
+	w.writeRaw(`<details class="logbook"><summary>Logbook</summary><ul>`)
+
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		w.writeRaw("<li>")
+		switch entry := child.(type) {
+		case *content.LogbookEntryRaw:
+			w.writeEscaped(entry.Value)
+		case *content.LogbookEntryClock:
+			w.writeRaw(`<time datetime="` + entry.Start.Format("2006-01-02T15:04:05") + `">`)
+			w.writeEscaped(entry.Start.Format("2006-01-02 Mon 15:04:05"))
+			w.writeRaw("</time>")
+			if !entry.IsRunning() {
+				w.writeRaw(` – <time datetime="` + entry.End.Format("2006-01-02T15:04:05") + `">`)
+				w.writeEscaped(entry.End.Format("2006-01-02 Mon 15:04:05"))
+				w.writeRaw("</time>")
+				w.writeEscaped(" (" + entry.Duration().String() + ")")
+			}
+		case *content.LogbookEntryStateChange:
+			w.writeEscaped(entry.To.String())
+			if entry.From != content.TaskStatusNone {
+				w.writeEscaped(" from " + entry.From.String())
+			}
+			w.writeRaw(` <time datetime="` + entry.Time.Format("2006-01-02T15:04:05") + `">`)
+			w.writeEscaped(entry.Time.Format("2006-01-02 Mon 15:04:05"))
+			w.writeRaw("</time>")
+		default:
+			return fmt.Errorf("unsupported logbook entry: %T", child)
+		}
+		w.writeRaw("</li>")
+	}
+
+	w.writeRaw("</ul></details>")
+	return nil
+}
+
+// safeURL returns the URL if it is relative or uses a scheme that can not run
+// code, such as `javascript:`, and `#` otherwise.
+func safeURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "#"
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https", "mailto", "ftp":
+		return raw
+	}
+
+	return "#"
+}
+
+// escapeClass makes a value safe to use as part of a class name.
+func escapeClass(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,106 @@
+package html_test
+
+import (
+	"github.com/aholstenson/logseq-go/content"
+	"github.com/aholstenson/logseq-go/content/html"
+	"github.com/aholstenson/logseq-go/internal/markdown"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HTML", func() {
+	render := func(src string, opts ...html.Option) string {
+		root, err := markdown.ParseString(src)
+		Expect(err).ToNot(HaveOccurred())
+
+		out, err := html.AsString(root, opts...)
+		Expect(err).ToNot(HaveOccurred())
+		return out
+	}
+
+	It("writes blocks as nested lists", func() {
+		Expect(render("- first\n\t- child\n- second\n")).To(Equal(
+			`<ul class="blocks">` +
+				`<li class="block"><p>first</p><ul class="blocks"><li class="block"><p>child</p></li></ul></li>` +
+				`<li class="block"><p>second</p></li>` +
+				`</ul>`,
+		))
+	})
+
+	It("marks tasks with their status", func() {
+		Expect(render("- DONE ship it\n")).To(Equal(
+			`<ul class="blocks"><li class="block task task-done">` +
+				`<p><span class="task-marker task-done">DONE</span> ship it</p>` +
+				`</li></ul>`,
+		))
+	})
+
+	It("writes inline formatting", func() {
+		Expect(render("- **bold** *em* ~~del~~ ^^mark^^ `code`\n")).To(ContainSubstring(
+			`<p><strong>bold</strong> <em>em</em> <del>del</del> <mark>mark</mark> <code>code</code></p>`,
+		))
+	})
+
+	It("writes math for KaTeX", func() {
+		Expect(render("- $x^2$\n")).To(ContainSubstring(`<span class="math inline">\(x^2\)</span>`))
+	})
+
+	It("aligns the columns of tables", func() {
+		out := render("- | a | b |\n  | :-- | --: |\n  | 1 | 2 |\n")
+		Expect(out).To(ContainSubstring(
+			`<table><thead><tr><th style="text-align: left">a</th><th style="text-align: right">b</th></tr></thead>` +
+				`<tbody><tr><td style="text-align: left">1</td><td style="text-align: right">2</td></tr></tbody></table>`,
+		))
+	})
+
+	It("resolves page and block references", func() {
+		out := render("- [[Some Page]] #tag ((abc))\n",
+			html.WithPageResolver(func(title string) string {
+				return "/pages/" + title
+			}),
+			html.WithBlockResolver(func(id string) (string, string) {
+				return "/blocks/" + id, "Referenced"
+			}),
+		)
+
+		Expect(out).To(ContainSubstring(`<a class="page-ref" href="/pages/Some Page">Some Page</a>`))
+		Expect(out).To(ContainSubstring(`<a class="tag" href="/pages/tag">#tag</a>`))
+		Expect(out).To(ContainSubstring(`<a class="block-ref" href="/blocks/abc">Referenced</a>`))
+	})
+
+	It("writes references without links by default", func() {
+		Expect(render("- [[Some Page]]\n")).To(ContainSubstring(`<span class="page-ref">Some Page</span>`))
+	})
+
+	It("escapes text and raw HTML by default", func() {
+		paragraph := content.NewParagraph(
+			content.NewText("<script>"),
+			content.NewRawHTML("<b>bold</b>"),
+		)
+
+		out, err := html.AsString(paragraph)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out).To(Equal("<p>&lt;script&gt;&lt;b&gt;bold&lt;/b&gt;</p>"))
+
+		out, err = html.AsString(paragraph, html.WithRawHTML())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out).To(Equal("<p>&lt;script&gt;<b>bold</b></p>"))
+	})
+
+	It("does not link to scripts", func() {
+		link := content.NewLink("javascript:alert(1)", content.NewText("click"))
+
+		out, err := html.AsString(link)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out).To(Equal(`<a href="#">click</a>`))
+	})
+
+	It("writes properties, footnotes and macros", func() {
+		out := render("- text[^1] {{youtube abc}}\n  rating:: 5\n- [^1]: The note\n")
+
+		Expect(out).To(ContainSubstring(`<sup class="footnote-ref"><a href="#fn-1" id="fnref-1">1</a></sup>`))
+		Expect(out).To(ContainSubstring(`<span class="macro" data-name="youtube">{{youtube abc}}</span>`))
+		Expect(out).To(ContainSubstring(`<dl class="properties"><dt>rating</dt><dd>5</dd></dl>`))
+		Expect(out).To(ContainSubstring(`<div class="footnote" id="fn-1">`))
+	})
+})
@@ -0,0 +1,987 @@
+package content
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// JSONVersion is the version of the JSON format written by MarshalJSON. It is
+// increased when the format changes in a way that older readers can not read.
+const JSONVersion = 1
+
+// jsonDocument is the top level of the JSON format.
+type jsonDocument struct {
+	Version int       `json:"version"`
+	Node    *jsonNode `json:"node"`
+}
+
+// jsonNode is a node in the JSON format. Every node has a type, and the other
+// fields that are used depend on the type.
+type jsonNode struct {
+	Type             string `json:"type"`
+	PreviousLineType string `json:"previousLineType,omitempty"`
+
+	Value     string   `json:"value,omitempty"`
+	LineBreak string   `json:"lineBreak,omitempty"`
+	Language  string   `json:"language,omitempty"`
+	Code      string   `json:"code,omitempty"`
+	Displayed bool     `json:"displayed,omitempty"`
+	HTML      string   `json:"html,omitempty"`
+	Level     int      `json:"level,omitempty"`
+	ListType  string   `json:"listType,omitempty"`
+	Marker    string   `json:"marker,omitempty"`
+	Align     []string `json:"alignments,omitempty"`
+	URL       string   `json:"url,omitempty"`
+	Title     string   `json:"title,omitempty"`
+	To        string   `json:"to,omitempty"`
+	ID        string   `json:"id,omitempty"`
+	Label     string   `json:"label,omitempty"`
+	Name      string   `json:"name,omitempty"`
+	Arguments []string `json:"arguments,omitempty"`
+	Query     string   `json:"query,omitempty"`
+	Quote     string   `json:"quote,omitempty"`
+	Command   string   `json:"command,omitempty"`
+	Answer    string   `json:"answer,omitempty"`
+	Cue       string   `json:"cue,omitempty"`
+
+	PreBlock          bool `json:"preBlock,omitempty"`
+	PageRefsIgnored   bool `json:"pageRefsIgnored,omitempty"`
+	SeparatedByCommas bool `json:"separatedByCommas,omitempty"`
+
+	Status     string        `json:"status,omitempty"`
+	FromStatus string        `json:"fromStatus,omitempty"`
+	Priority   string        `json:"priority,omitempty"`
+	DateType   string        `json:"dateType,omitempty"`
+	Date       string        `json:"date,omitempty"`
+	HasTime    bool          `json:"hasTime,omitempty"`
+	Repeater   *jsonRepeater `json:"repeater,omitempty"`
+	Start      string        `json:"start,omitempty"`
+	End        string        `json:"end,omitempty"`
+	Time       string        `json:"time,omitempty"`
+
+	Children []*jsonNode `json:"children,omitempty"`
+}
+
+type jsonRepeater struct {
+	Type  string `json:"type"`
+	Value int    `json:"value"`
+	Unit  string `json:"unit"`
+}
+
+// MarshalJSON writes a node and everything below it as JSON. The node can be
+// read back with UnmarshalJSON, and writing the result as Markdown gives the
+// same Markdown as the original node.
+//
+// The JSON is an object with the version of the format, see JSONVersion, and
+// the node:
+//
+//	{"version": 1, "node": {"type": "block", "children": [...]}}
+//
+// Every node is an object with a `type`, such as `block`, `paragraph`, `text`
+// or `pageLink`, which is the name of the node type starting with a lower case
+// letter. Nodes with children have them in `children`, and the values of a
+// node are in fields named after the fields of its type, such as `value` for
+// text and `to` for page links. Fields with empty values are left out.
+//
+// Task statuses are written as their markers, such as `TODO`, priorities as
+// `A`, `B` or `C`, and times in RFC 3339. Block nodes that are not separated
+// from the line before them in the usual way have a `previousLineType` of
+// `blank` or `non-blank`.
+func MarshalJSON(node Node) ([]byte, error) {
+	n, err := toJSONNode(node)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(jsonDocument{
+		Version: JSONVersion,
+		Node:    n,
+	})
+}
+
+// UnmarshalJSON reads a node written by MarshalJSON.
+func UnmarshalJSON(data []byte) (Node, error) {
+	var doc jsonDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	if doc.Version != JSONVersion {
+		return nil, fmt.Errorf("unsupported version: %d", doc.Version)
+	}
+
+	if doc.Node == nil {
+		return nil, fmt.Errorf("no node")
+	}
+
+	return fromJSONNode(doc.Node)
+}
+
+func toJSONNode(node Node) (*jsonNode, error) {
+	n := &jsonNode{}
+
+	switch v := node.(type) {
+	case *Block:
+		n.Type = "block"
+		n.PreBlock = v.preBlock
+	case *Properties:
+		n.Type = "properties"
+	case *Property:
+		n.Type = "property"
+		n.Name = v.Name
+		n.PageRefsIgnored = v.PageRefsIgnored
+		n.SeparatedByCommas = v.SeparatedByCommas
+	case *Paragraph:
+		n.Type = "paragraph"
+	case *Heading:
+		n.Type = "heading"
+		n.Level = v.Level
+	case *Blockquote:
+		n.Type = "blockquote"
+	case *List:
+		n.Type = "list"
+		n.ListType = listTypeName(v.Type)
+		n.Marker = string(v.Marker)
+	case *ListSection:
+		n.Type = "listSection"
+		n.Marker = string(v.marker)
+	case *ListItem:
+		n.Type = "listItem"
+	case *Table:
+		n.Type = "table"
+		for _, alignment := range v.Alignments {
+			n.Align = append(n.Align, tableAlignmentName(alignment))
+		}
+	case *TableRow:
+		n.Type = "tableRow"
+	case *TableCell:
+		n.Type = "tableCell"
+	case *Logbook:
+		n.Type = "logbook"
+	case *FootnoteDefinition:
+		n.Type = "footnoteDefinition"
+		n.Label = v.Label
+	case *Emphasis:
+		n.Type = "emphasis"
+	case *Strong:
+		n.Type = "strong"
+	case *Strikethrough:
+		n.Type = "strikethrough"
+	case *Highlight:
+		n.Type = "highlight"
+	case *Link:
+		n.Type = "link"
+		n.URL = v.URL
+		n.Title = v.Title
+	case *Image:
+		n.Type = "image"
+		n.URL = v.URL
+		n.Title = v.Title
+	case *Text:
+		n.Type = "text"
+		n.Value = v.Value
+		if v.HardLineBreak {
+			n.LineBreak = "hard"
+		} else if v.SoftLineBreak {
+			n.LineBreak = "soft"
+		}
+	case *RawText:
+		n.Type = "rawText"
+		n.Value = v.Value
+	case *CodeSpan:
+		n.Type = "codeSpan"
+		n.Value = v.Value
+	case *CodeBlock:
+		n.Type = "codeBlock"
+		n.Language = v.Language
+		n.Code = v.Code
+	case *ThematicBreak:
+		n.Type = "thematicBreak"
+	case *RawHTML:
+		n.Type = "rawHTML"
+		n.HTML = v.HTML
+	case *RawHTMLBlock:
+		n.Type = "rawHTMLBlock"
+		n.HTML = v.HTML
+	case *Math:
+		n.Type = "math"
+		n.Value = v.Value
+		n.Displayed = v.Displayed
+	case *MathBlock:
+		n.Type = "mathBlock"
+		n.Value = v.Value
+	case *AutoLink:
+		n.Type = "autoLink"
+		n.URL = v.URL
+	case *PageLink:
+		n.Type = "pageLink"
+		n.To = v.To
+	case *PageRefText:
+		n.Type = "pageRefText"
+		n.To = v.To
+	case *Hashtag:
+		n.Type = "hashtag"
+		n.To = v.To
+	case *BlockRef:
+		n.Type = "blockRef"
+		n.ID = v.ID
+	case *FootnoteRef:
+		n.Type = "footnoteRef"
+		n.Label = v.Label
+	case *Macro:
+		n.Type = "macro"
+		n.Name = v.Name
+		n.Arguments = v.Arguments
+	case *Query:
+		n.Type = "query"
+		n.Query = v.Query
+	case *PageEmbed:
+		n.Type = "pageEmbed"
+		n.To = v.To
+	case *BlockEmbed:
+		n.Type = "blockEmbed"
+		n.ID = v.ID
+	case *Cloze:
+		n.Type = "cloze"
+		n.Answer = v.Answer
+		n.Cue = v.Cue
+	case *AdvancedCommand:
+		n.Type = "advancedCommand"
+		n.Command = v.Type
+		n.Value = v.Value
+	case *QueryCommand:
+		n.Type = "queryCommand"
+		n.Query = v.Query
+	case *QuoteCommand:
+		n.Type = "quoteCommand"
+		n.Quote = v.Quote
+	case *TaskMarker:
+		n.Type = "taskMarker"
+		n.Status = v.Status.String()
+	case *TaskPriority:
+		n.Type = "taskPriority"
+		n.Priority = priorityName(v.Priority)
+	case *TaskDate:
+		n.Type = "taskDate"
+		n.DateType = taskDateTypeName(v.Type)
+		n.Date = v.Date.Format(time.RFC3339)
+		n.HasTime = v.HasTime
+		if v.Repeater != nil {
+			n.Repeater = &jsonRepeater{
+				Type:  repeaterTypeName(v.Repeater.Type),
+				Value: v.Repeater.Value,
+				Unit:  repeaterUnitName(v.Repeater.Unit),
+			}
+		}
+	case *LogbookEntryRaw:
+		n.Type = "logbookEntryRaw"
+		n.Value = v.Value
+	case *LogbookEntryClock:
+		n.Type = "logbookEntryClock"
+		n.Start = v.Start.Format(time.RFC3339)
+		if !v.IsRunning() {
+			n.End = v.End.Format(time.RFC3339)
+		}
+	case *LogbookEntryStateChange:
+		n.Type = "logbookEntryStateChange"
+		n.FromStatus = v.From.String()
+		n.Status = v.To.String()
+		n.Time = v.Time.Format(time.RFC3339)
+	default:
+		return nil, fmt.Errorf("unsupported node: %T", node)
+	}
+
+	if aware, ok := node.(PreviousLineAware); ok {
+		n.PreviousLineType = previousLineTypeName(aware.PreviousLineType())
+	}
+
+	if parent, ok := node.(HasChildren); ok {
+		for child := parent.FirstChild(); child != nil; child = child.NextSibling() {
+			c, err := toJSONNode(child)
+			if err != nil {
+				return nil, err
+			}
+			n.Children = append(n.Children, c)
+		}
+	}
+
+	return n, nil
+}
+
+func fromJSONNode(n *jsonNode) (Node, error) {
+	var node Node
+	var err error
+
+	switch n.Type {
+	case "block":
+		block := NewBlock()
+		block.preBlock = n.PreBlock
+		node = block
+	case "properties":
+		node = NewProperties()
+	case "property":
+		property := NewProperty(n.Name)
+		property.PageRefsIgnored = n.PageRefsIgnored
+		property.SeparatedByCommas = n.SeparatedByCommas
+		node = property
+	case "paragraph":
+		node = NewParagraph()
+	case "heading":
+		node = NewHeading(n.Level)
+	case "blockquote":
+		node = NewBlockquote()
+	case "list":
+		list := NewList(ListTypeUnordered)
+		if list.Type, err = parseListType(n.ListType); err != nil {
+			return nil, err
+		}
+		if len(n.Marker) == 1 {
+			list.Marker = n.Marker[0]
+		}
+		node = list
+	case "listSection":
+		section := &ListSection{}
+		section.self = section
+		if len(n.Marker) == 1 {
+			section.marker = n.Marker[0]
+		}
+		node = section
+	case "listItem":
+		node = NewListItem()
+	case "table":
+		table := NewTable()
+		for _, name := range n.Align {
+			alignment, err := parseTableAlignment(name)
+			if err != nil {
+				return nil, err
+			}
+			table.Alignments = append(table.Alignments, alignment)
+		}
+		node = table
+	case "tableRow":
+		node = NewTableRow()
+	case "tableCell":
+		node = NewTableCell()
+	case "logbook":
+		node = NewLogbook()
+	case "footnoteDefinition":
+		node = NewFootnoteDefinition(n.Label)
+	case "emphasis":
+		node = NewEmphasis()
+	case "strong":
+		node = NewStrong()
+	case "strikethrough":
+		node = NewStrikethrough()
+	case "highlight":
+		node = NewHighlight()
+	case "link":
+		link := NewLink(n.URL)
+		link.Title = n.Title
+		node = link
+	case "image":
+		image := NewImage(n.URL)
+		image.Title = n.Title
+		node = image
+	case "text":
+		text := NewText(n.Value)
+		text.HardLineBreak = n.LineBreak == "hard"
+		text.SoftLineBreak = n.LineBreak == "soft"
+		node = text
+	case "rawText":
+		node = NewRawText(n.Value)
+	case "codeSpan":
+		node = NewCodeSpan(n.Value)
+	case "codeBlock":
+		code := NewCodeBlock(n.Code)
+		code.Language = n.Language
+		node = code
+	case "thematicBreak":
+		node = NewThematicBreak()
+	case "rawHTML":
+		node = NewRawHTML(n.HTML)
+	case "rawHTMLBlock":
+		node = NewRawHTMLBlock(n.HTML)
+	case "math":
+		math := NewMath(n.Value)
+		math.Displayed = n.Displayed
+		node = math
+	case "mathBlock":
+		node = NewMathBlock(n.Value)
+	case "autoLink":
+		node = NewAutoLink(n.URL)
+	case "pageLink":
+		node = NewPageLink(n.To)
+	case "pageRefText":
+		node = NewPageRefText(n.To)
+	case "hashtag":
+		node = NewHashtag(n.To)
+	case "blockRef":
+		node = NewBlockRef(n.ID)
+	case "footnoteRef":
+		node = NewFootnoteRef(n.Label)
+	case "macro":
+		node = NewMacro(n.Name, n.Arguments...)
+	case "query":
+		node = NewQuery(n.Query)
+	case "pageEmbed":
+		node = NewPageEmbed(n.To)
+	case "blockEmbed":
+		node = NewBlockEmbed(n.ID)
+	case "cloze":
+		node = NewClozeWithCue(n.Answer, n.Cue)
+	case "advancedCommand":
+		node = NewAdvancedCommand(n.Command, n.Value)
+	case "queryCommand":
+		node = NewQueryCommand(n.Query)
+	case "quoteCommand":
+		node = &QuoteCommand{Quote: n.Quote}
+	case "taskMarker":
+		status, err := parseTaskStatus(n.Status)
+		if err != nil {
+			return nil, err
+		}
+		node = NewTaskMarker(status)
+	case "taskPriority":
+		priority, err := parsePriority(n.Priority)
+		if err != nil {
+			return nil, err
+		}
+		node = NewTaskPriority(priority)
+	case "taskDate":
+		node, err = taskDateFromJSON(n)
+		if err != nil {
+			return nil, err
+		}
+	case "logbookEntryRaw":
+		node = NewLogbookEntryRaw(n.Value)
+	case "logbookEntryClock":
+		start, err := parseJSONTime(n.Start)
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseJSONTime(n.End)
+		if err != nil {
+			return nil, err
+		}
+		node = NewLogbookEntryClock(start, end)
+	case "logbookEntryStateChange":
+		from, err := parseTaskStatus(n.FromStatus)
+		if err != nil {
+			return nil, err
+		}
+		to, err := parseTaskStatus(n.Status)
+		if err != nil {
+			return nil, err
+		}
+		at, err := parseJSONTime(n.Time)
+		if err != nil {
+			return nil, err
+		}
+		node = NewLogbookEntryStateChange(from, to, at)
+	default:
+		return nil, fmt.Errorf("unsupported node type: %q", n.Type)
+	}
+
+	if aware, ok := node.(PreviousLineAware); ok {
+		previousLineType, err := parsePreviousLineType(n.PreviousLineType)
+		if err != nil {
+			return nil, err
+		}
+		aware.SetPreviousLineType(previousLineType)
+	}
+
+	if len(n.Children) > 0 {
+		parent, ok := node.(HasChildren)
+		if !ok {
+			return nil, fmt.Errorf("node of type %q can not have children", n.Type)
+		}
+
+		for _, c := range n.Children {
+			child, err := fromJSONNode(c)
+			if err != nil {
+				return nil, err
+			}
+
+			parent.AddChild(child)
+			if child.Parent() == nil {
+				return nil, fmt.Errorf("node of type %q can not be a child of %q", c.Type, n.Type)
+			}
+		}
+	}
+
+	return node, nil
+}
+
+func taskDateFromJSON(n *jsonNode) (*TaskDate, error) {
+	date, err := parseJSONTime(n.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	var taskDate *TaskDate
+	switch n.DateType {
+	case "scheduled":
+		taskDate = NewTaskDate(TaskDateTypeScheduled, date)
+	case "deadline":
+		taskDate = NewTaskDate(TaskDateTypeDeadline, date)
+	default:
+		return nil, fmt.Errorf("unsupported task date type: %q", n.DateType)
+	}
+
+	if n.HasTime {
+		taskDate.WithDateAndTime(date)
+	}
+
+	if n.Repeater != nil {
+		repeater := &Repeater{Value: n.Repeater.Value}
+		switch n.Repeater.Type {
+		case "cumulate":
+			repeater.Type = RepeaterTypeCumulate
+		case "catchUp":
+			repeater.Type = RepeaterTypeCatchUp
+		case "restart":
+			repeater.Type = RepeaterTypeRestart
+		default:
+			return nil, fmt.Errorf("unsupported repeater type: %q", n.Repeater.Type)
+		}
+
+		switch n.Repeater.Unit {
+		case "hour":
+			repeater.Unit = RepeaterUnitHour
+		case "day":
+			repeater.Unit = RepeaterUnitDay
+		case "week":
+			repeater.Unit = RepeaterUnitWeek
+		case "month":
+			repeater.Unit = RepeaterUnitMonth
+		case "year":
+			repeater.Unit = RepeaterUnitYear
+		default:
+			return nil, fmt.Errorf("unsupported repeater unit: %q", n.Repeater.Unit)
+		}
+
+		taskDate.Repeater = repeater
+	}
+
+	return taskDate, nil
+}
+
+func parseJSONTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
+
+func previousLineTypeName(t PreviousLineType) string {
+	switch t {
+	case PreviousLineTypeBlank:
+		return "blank"
+	case PreviousLineTypeNonBlank:
+		return "non-blank"
+	}
+	return ""
+}
+
+func parsePreviousLineType(name string) (PreviousLineType, error) {
+	switch name {
+	case "":
+		return PreviousLineTypeAutomatic, nil
+	case "blank":
+		return PreviousLineTypeBlank, nil
+	case "non-blank":
+		return PreviousLineTypeNonBlank, nil
+	}
+	return PreviousLineTypeAutomatic, fmt.Errorf("unsupported previous line type: %q", name)
+}
+
+func listTypeName(t ListType) string {
+	if t == ListTypeOrdered {
+		return "ordered"
+	}
+	return "unordered"
+}
+
+func parseListType(name string) (ListType, error) {
+	switch name {
+	case "ordered":
+		return ListTypeOrdered, nil
+	case "unordered":
+		return ListTypeUnordered, nil
+	}
+	return ListTypeUnordered, fmt.Errorf("unsupported list type: %q", name)
+}
+
+func tableAlignmentName(alignment TableAlignment) string {
+	switch alignment {
+	case TableAlignmentLeft:
+		return "left"
+	case TableAlignmentRight:
+		return "right"
+	case TableAlignmentCenter:
+		return "center"
+	}
+	return "none"
+}
+
+func parseTableAlignment(name string) (TableAlignment, error) {
+	switch name {
+	case "none":
+		return TableAlignmentNone, nil
+	case "left":
+		return TableAlignmentLeft, nil
+	case "right":
+		return TableAlignmentRight, nil
+	case "center":
+		return TableAlignmentCenter, nil
+	}
+	return TableAlignmentNone, fmt.Errorf("unsupported table alignment: %q", name)
+}
+
+func parseTaskStatus(marker string) (TaskStatus, error) {
+	if marker == "" {
+		return TaskStatusNone, nil
+	}
+
+	for status := TaskStatusTodo; status <= TaskStatusWaiting; status++ {
+		if status.String() == marker {
+			return status, nil
+		}
+	}
+	return TaskStatusNone, fmt.Errorf("unsupported task status: %q", marker)
+}
+
+func priorityName(priority Priority) string {
+	switch priority {
+	case PriorityA:
+		return "A"
+	case PriorityB:
+		return "B"
+	case PriorityC:
+		return "C"
+	}
+	return ""
+}
+
+func parsePriority(name string) (Priority, error) {
+	switch name {
+	case "":
+		return PriorityNone, nil
+	case "A":
+		return PriorityA, nil
+	case "B":
+		return PriorityB, nil
+	case "C":
+		return PriorityC, nil
+	}
+	return PriorityNone, fmt.Errorf("unsupported priority: %q", name)
+}
+
+func taskDateTypeName(t TaskDateType) string {
+	if t == TaskDateTypeDeadline {
+		return "deadline"
+	}
+	return "scheduled"
+}
+
+func repeaterTypeName(t RepeaterType) string {
+	switch t {
+	case RepeaterTypeCatchUp:
+		return "catchUp"
+	case RepeaterTypeRestart:
+		return "restart"
+	}
+	return "cumulate"
+}
+
+func repeaterUnitName(unit RepeaterUnit) string {
+	switch unit {
+	case RepeaterUnitHour:
+		return "hour"
+	case RepeaterUnitWeek:
+		return "week"
+	case RepeaterUnitMonth:
+		return "month"
+	case RepeaterUnitYear:
+		return "year"
+	}
+	return "day"
+}
+
+// jsonChanges is the top level of the JSON format of changes.
+type jsonChanges struct {
+	Version int           `json:"version"`
+	Changes []*jsonChange `json:"changes"`
+}
+
+// jsonChange is a change in the JSON format. Nodes are written the same way
+// as by MarshalJSON.
+type jsonChange struct {
+	Type    string    `json:"type"`
+	OldPath BlockPath `json:"oldPath,omitempty"`
+	NewPath BlockPath `json:"newPath,omitempty"`
+
+	Block *jsonNode `json:"block,omitempty"`
+
+	OldText string         `json:"oldText,omitempty"`
+	NewText string         `json:"newText,omitempty"`
+	Edits   []jsonTextEdit `json:"edits,omitempty"`
+	Content []*jsonNode    `json:"content,omitempty"`
+
+	OldStatus string `json:"oldStatus,omitempty"`
+	NewStatus string `json:"newStatus,omitempty"`
+
+	// The values of properties are pointers, as a property without a value
+	// is written as an empty list while a missing one is left out
+	Property string       `json:"property,omitempty"`
+	OldValue *[]*jsonNode `json:"oldValue,omitempty"`
+	NewValue *[]*jsonNode `json:"newValue,omitempty"`
+}
+
+type jsonTextEdit struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+var jsonChangeTypes = map[ChangeType]string{
+	ChangeInsert:     "insert",
+	ChangeDelete:     "delete",
+	ChangeMove:       "move",
+	ChangeText:       "text",
+	ChangeTaskStatus: "taskStatus",
+	ChangeProperty:   "property",
+}
+
+// MarshalJSON writes the changes as JSON, so that they can be stored or sent
+// somewhere else and applied with Patch later on. The JSON is an object with
+// the version of the format, the same as for nodes, and the changes:
+//
+//	{"version": 1, "changes": [{"type": "move", "oldPath": [2], "newPath": [0]}]}
+//
+// Every change has a `type`, which is `insert`, `delete`, `move`, `text`,
+// `taskStatus` or `property`, and the fields of Change that are set for it
+// named in the same way, such as `oldPath` and `newText`. Blocks and other
+// content are written as described by MarshalJSON, and text edits as objects
+// with a `type` of `keep`, `insert` or `delete` and their `text`.
+func (c Changes) MarshalJSON() ([]byte, error) {
+	doc := jsonChanges{
+		Version: JSONVersion,
+		Changes: make([]*jsonChange, 0, len(c)),
+	}
+
+	for _, change := range c {
+		n, err := toJSONChange(change)
+		if err != nil {
+			return nil, err
+		}
+
+		doc.Changes = append(doc.Changes, n)
+	}
+
+	return json.Marshal(doc)
+}
+
+// UnmarshalJSON reads changes written by MarshalJSON.
+func (c *Changes) UnmarshalJSON(data []byte) error {
+	var doc jsonChanges
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+
+	if doc.Version != JSONVersion {
+		return fmt.Errorf("unsupported version: %d", doc.Version)
+	}
+
+	changes := make(Changes, 0, len(doc.Changes))
+	for _, n := range doc.Changes {
+		change, err := fromJSONChange(n)
+		if err != nil {
+			return err
+		}
+
+		changes = append(changes, change)
+	}
+
+	*c = changes
+	return nil
+}
+
+func toJSONChange(change Change) (*jsonChange, error) {
+	changeType, ok := jsonChangeTypes[change.Type]
+	if !ok {
+		return nil, fmt.Errorf("unsupported change type: %d", change.Type)
+	}
+
+	n := &jsonChange{
+		Type:     changeType,
+		OldPath:  change.OldPath,
+		NewPath:  change.NewPath,
+		OldText:  change.OldText,
+		NewText:  change.NewText,
+		Property: change.Property,
+	}
+
+	if change.Type == ChangeTaskStatus {
+		n.OldStatus = change.OldStatus.String()
+		n.NewStatus = change.NewStatus.String()
+	}
+
+	if change.Block != nil {
+		block, err := toJSONNode(change.Block)
+		if err != nil {
+			return nil, err
+		}
+		n.Block = block
+	}
+
+	for _, edit := range change.Edits {
+		n.Edits = append(n.Edits, jsonTextEdit{Type: edit.Type.String(), Text: edit.Text})
+	}
+
+	var err error
+	if n.Content, err = toJSONNodes(change.Content); err != nil {
+		return nil, err
+	}
+
+	if n.OldValue, err = toJSONValue(change.OldValue); err != nil {
+		return nil, err
+	}
+
+	if n.NewValue, err = toJSONValue(change.NewValue); err != nil {
+		return nil, err
+	}
+
+	return n, nil
+}
+
+func fromJSONChange(n *jsonChange) (Change, error) {
+	change := Change{
+		OldPath:  n.OldPath,
+		NewPath:  n.NewPath,
+		OldText:  n.OldText,
+		NewText:  n.NewText,
+		Property: n.Property,
+	}
+
+	found := false
+	for changeType, name := range jsonChangeTypes {
+		if name == n.Type {
+			change.Type = changeType
+			found = true
+		}
+	}
+
+	if !found {
+		return Change{}, fmt.Errorf("unsupported change type: %q", n.Type)
+	}
+
+	var err error
+	if change.OldStatus, err = parseTaskStatus(n.OldStatus); err != nil {
+		return Change{}, err
+	}
+
+	if change.NewStatus, err = parseTaskStatus(n.NewStatus); err != nil {
+		return Change{}, err
+	}
+
+	if n.Block != nil {
+		node, err := fromJSONNode(n.Block)
+		if err != nil {
+			return Change{}, err
+		}
+
+		block, ok := node.(*Block)
+		if !ok {
+			return Change{}, fmt.Errorf("block of change is a %s", n.Block.Type)
+		}
+		change.Block = block
+	}
+
+	for _, edit := range n.Edits {
+		editType, err := parseTextEditType(edit.Type)
+		if err != nil {
+			return Change{}, err
+		}
+
+		change.Edits = append(change.Edits, TextEdit{Type: editType, Text: edit.Text})
+	}
+
+	if n.Content != nil {
+		if change.Content, err = fromJSONNodes(n.Content); err != nil {
+			return Change{}, err
+		}
+	}
+
+	if n.OldValue != nil {
+		if change.OldValue, err = fromJSONNodes(*n.OldValue); err != nil {
+			return Change{}, err
+		}
+	}
+
+	if n.NewValue != nil {
+		if change.NewValue, err = fromJSONNodes(*n.NewValue); err != nil {
+			return Change{}, err
+		}
+	}
+
+	return change, nil
+}
+
+// toJSONValue writes the value of a property, which is left out if the
+// property is not set but is an empty list if the property has no value.
+func toJSONValue(nodes NodeList) (*[]*jsonNode, error) {
+	if nodes == nil {
+		return nil, nil
+	}
+
+	value, err := toJSONNodes(nodes)
+	if err != nil {
+		return nil, err
+	}
+
+	if value == nil {
+		value = make([]*jsonNode, 0)
+	}
+	return &value, nil
+}
+
+func toJSONNodes(nodes NodeList) ([]*jsonNode, error) {
+	var result []*jsonNode
+	for _, node := range nodes {
+		n, err := toJSONNode(node)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, n)
+	}
+
+	return result, nil
+}
+
+// fromJSONNodes reads a list of nodes, which is never nil so that an empty
+// list stays apart from a missing one.
+func fromJSONNodes(nodes []*jsonNode) (NodeList, error) {
+	result := make(NodeList, 0, len(nodes))
+	for _, n := range nodes {
+		node, err := fromJSONNode(n)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, node)
+	}
+
+	return result, nil
+}
+
+func parseTextEditType(name string) (TextEditType, error) {
+	for editType := TextEditKeep; editType <= TextEditDelete; editType++ {
+		if editType.String() == name {
+			return editType, nil
+		}
+	}
+	return TextEditKeep, fmt.Errorf("unsupported text edit type: %q", name)
+}
@@ -0,0 +1,102 @@
+package content_test
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/aholstenson/logseq-go/content"
+	"github.com/aholstenson/logseq-go/internal/markdown"
+	. "github.com/aholstenson/logseq-go/internal/tests"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("JSON", func() {
+	roundTrip := func(node content.Node) content.Node {
+		data, err := content.MarshalJSON(node)
+		Expect(err).ToNot(HaveOccurred())
+
+		read, err := content.UnmarshalJSON(data)
+		Expect(err).ToNot(HaveOccurred())
+		return read
+	}
+
+	It("writes the version and type of nodes", func() {
+		data, err := content.MarshalJSON(content.NewParagraph(content.NewText("Hello")))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(MatchJSON(`{
+			"version": 1,
+			"node": {
+				"type": "paragraph",
+				"children": [{"type": "text", "value": "Hello"}]
+			}
+		}`))
+	})
+
+	It("round-trips Markdown", func() {
+		src := "title:: Example\ntags:: [[a]], b\n\n" +
+			"- TODO [#A] Read **bold** *em* ~~del~~ ^^mark^^ `code` $x^2$ [[Page]] #tag ((block-id))\n" +
+			"  SCHEDULED: <2023-06-26 Mon 10:00 .+1w>\n" +
+			"  :LOGBOOK:\n" +
+			"  CLOCK: [2023-06-26 Mon 17:25:56]--[2023-06-26 Mon 17:25:58] =>  00:00:02\n" +
+			"  :END:\n" +
+			"  id:: 6579b2a0-4e4e-4b0f-9d5c-ae8d6b0f3a1b\n" +
+			"\t- ## Heading\n" +
+			"\t  > quote\n" +
+			"\t- ```go\n" +
+			"\t  fmt.Println()\n" +
+			"\t  ```\n" +
+			"- {{embed [[Other]]}} {{query (todo now)}} [link](https://example.com \"Title\") text[^1]\n" +
+			"- | a | b |\n" +
+			"  | :-- | --: |\n" +
+			"  | 1 | 2 |\n" +
+			"- 1. one\n" +
+			"  2. two\n" +
+			"- [^1]: The note\n"
+
+		root, err := markdown.ParseString(src)
+		Expect(err).ToNot(HaveOccurred())
+
+		expected, err := markdown.AsString(root)
+		Expect(err).ToNot(HaveOccurred())
+
+		actual, err := markdown.AsString(roundTrip(root))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(actual).To(Equal(expected))
+		Expect(roundTrip(root)).To(EqualNode(root))
+	})
+
+	It("round-trips task dates and logbook entries", func() {
+		at := time.Date(2023, 6, 26, 17, 25, 56, 0, time.UTC)
+		block := content.NewBlock(
+			content.NewParagraph(content.NewTaskMarker(content.TaskStatusDone), content.NewText("task")),
+			content.NewDeadline(at).WithRepeater(content.NewRepeater(content.RepeaterTypeCatchUp, 2, content.RepeaterUnitMonth)),
+			content.NewLogbook(
+				content.NewLogbookEntryStateChange(content.TaskStatusTodo, content.TaskStatusDone, at),
+				content.NewLogbookEntryClock(at, time.Time{}),
+				content.NewLogbookEntryRaw("custom"),
+			),
+		)
+
+		Expect(roundTrip(block)).To(EqualNode(block))
+	})
+
+	It("refuses other versions", func() {
+		_, err := content.UnmarshalJSON([]byte(`{"version": 2, "node": {"type": "text"}}`))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("refuses children that are not allowed", func() {
+		data, err := json.Marshal(map[string]any{
+			"version": 1,
+			"node": map[string]any{
+				"type":     "properties",
+				"children": []any{map[string]any{"type": "text", "value": "x"}},
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = content.UnmarshalJSON(data)
+		Expect(err).To(HaveOccurred())
+	})
+})
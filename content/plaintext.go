@@ -0,0 +1,232 @@
+package content
+
+import (
+	"strings"
+	"unicode"
+)
+
+type plainTextOptions struct {
+	pageRefBrackets bool
+	hashtagsAsWords bool
+	dropProperties  bool
+	dropLogbooks    bool
+	maxLength       int
+}
+
+// PlainTextOption is an option for PlainText.
+type PlainTextOption func(*plainTextOptions)
+
+// WithPageRefBrackets keeps the brackets around page references, so that
+// `[[Example]]` is written as `[[Example]]` instead of `Example`.
+func WithPageRefBrackets() PlainTextOption {
+	return func(o *plainTextOptions) {
+		o.pageRefBrackets = true
+	}
+}
+
+// WithHashtagsAsWords writes tags as the title of the page they point to, so
+// that `#Example` and `#[[Example]]` are written as `Example`.
+func WithHashtagsAsWords() PlainTextOption {
+	return func(o *plainTextOptions) {
+		o.hashtagsAsWords = true
+	}
+}
+
+// WithoutProperties leaves out the properties of blocks.
+func WithoutProperties() PlainTextOption {
+	return func(o *plainTextOptions) {
+		o.dropProperties = true
+	}
+}
+
+// WithoutLogbooks leaves out the logbooks of tasks.
+func WithoutLogbooks() PlainTextOption {
+	return func(o *plainTextOptions) {
+		o.dropLogbooks = true
+	}
+}
+
+// WithMaxLength limits the text to a number of characters. Longer text is cut
+// at the last word that fits and ends with `…`.
+func WithMaxLength(length int) PlainTextOption {
+	return func(o *plainTextOptions) {
+		o.maxLength = length
+	}
+}
+
+// PlainText returns the text of a node and everything below it without any
+// markup, such as for showing content in notifications or indexing it for
+// search. Block nodes are separated by blank lines and task markers, block
+// references and macros are left out.
+func PlainText(node Node, opts ...PlainTextOption) string {
+	return NodeList{node}.PlainText(opts...)
+}
+
+// PlainText returns the text of the nodes without any markup. See PlainText
+// for details.
+func (n NodeList) PlainText(opts ...PlainTextOption) string {
+	options := plainTextOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	var builder strings.Builder
+	writePlainText(n, &builder, &options)
+	return truncateText(strings.TrimSpace(builder.String()), options.maxLength)
+}
+
+func writePlainText(nodes NodeList, builder *strings.Builder, options *plainTextOptions) {
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case *Text:
+			builder.WriteString(n.Value)
+			if n.SoftLineBreak || n.HardLineBreak {
+				builder.WriteRune('\n')
+			}
+		case *RawText:
+			builder.WriteString(n.Value)
+		case *Hashtag:
+			if !options.hashtagsAsWords {
+				builder.WriteString("#")
+			}
+			builder.WriteString(n.To)
+		case *PageLink:
+			writePageRef(n.To, builder, options)
+		case *PageRefText:
+			writePageRef(n.To, builder, options)
+		case *CodeSpan:
+			builder.WriteString(n.Value)
+		case *CodeBlock:
+			if builder.Len() > 0 {
+				builder.WriteString("\n\n")
+			}
+
+			builder.WriteString(n.Code)
+		case *Math:
+			builder.WriteString(n.Value)
+		case *MathBlock:
+			if builder.Len() > 0 {
+				builder.WriteString("\n\n")
+			}
+
+			builder.WriteString(n.Value)
+		case *TableRow:
+			writePlainText(n.Children(), builder, options)
+			builder.WriteRune('\n')
+		case *TableCell:
+			writePlainText(n.Children(), builder, options)
+			builder.WriteRune(' ')
+		case *Properties:
+			if options.dropProperties {
+				continue
+			}
+
+			if builder.Len() > 0 {
+				builder.WriteString("\n\n")
+			}
+
+			for idx, child := range n.Children() {
+				if property, ok := child.(*Property); ok {
+					if idx > 0 {
+						builder.WriteRune('\n')
+					}
+
+					builder.WriteString(property.Name)
+					builder.WriteString(": ")
+					writePlainText(property.Children(), builder, options)
+				}
+			}
+		case *Logbook:
+			if options.dropLogbooks {
+				continue
+			}
+
+			if builder.Len() > 0 {
+				builder.WriteString("\n\n")
+			}
+
+			for idx, child := range n.Children() {
+				if idx > 0 {
+					builder.WriteRune('\n')
+				}
+
+				writeLogbookEntry(child, builder)
+			}
+		case HasChildren:
+			if _, blockNode := n.(BlockNode); blockNode && builder.Len() > 0 {
+				builder.WriteString("\n\n")
+			}
+
+			writePlainText(n.Children(), builder, options)
+		}
+	}
+}
+
+func writePageRef(to string, builder *strings.Builder, options *plainTextOptions) {
+	if options.pageRefBrackets {
+		builder.WriteString("[[")
+		builder.WriteString(to)
+		builder.WriteString("]]")
+	} else {
+		builder.WriteString(to)
+	}
+}
+
+// writeLogbookEntry writes an entry of a logbook in the same form Logseq uses
+// for it.
+func writeLogbookEntry(node Node, builder *strings.Builder) {
+	const timeFormat = "2006-01-02 Mon 15:04:05"
+
+	switch n := node.(type) {
+	case *LogbookEntryRaw:
+		builder.WriteString(n.Value)
+	case *LogbookEntryClock:
+		builder.WriteString("CLOCK: [")
+		builder.WriteString(n.Start.Format(timeFormat))
+		builder.WriteString("]")
+		if !n.IsRunning() {
+			builder.WriteString("--[")
+			builder.WriteString(n.End.Format(timeFormat))
+			builder.WriteString("]")
+		}
+	case *LogbookEntryStateChange:
+		builder.WriteString("State \"")
+		builder.WriteString(n.To.String())
+		builder.WriteString("\"")
+		if n.From != TaskStatusNone {
+			builder.WriteString(" from \"")
+			builder.WriteString(n.From.String())
+			builder.WriteString("\"")
+		}
+		builder.WriteString(" [")
+		builder.WriteString(n.Time.Format(timeFormat))
+		builder.WriteString("]")
+	}
+}
+
+// truncateText cuts text that is longer than the maximum length at the last
+// word that fits, leaving room for an ellipsis. A maximum length of zero or
+// less leaves the text as it is.
+func truncateText(text string, maxLength int) string {
+	runes := []rune(text)
+	if maxLength <= 0 || len(runes) <= maxLength {
+		return text
+	}
+
+	if maxLength == 1 {
+		return "…"
+	}
+
+	cut := maxLength - 1
+	end := cut
+	for end > 0 && !unicode.IsSpace(runes[end]) {
+		end--
+	}
+
+	// A single word longer than the limit is cut where the limit is
+	if end == 0 {
+		end = cut
+	}
+
+	return strings.TrimRightFunc(string(runes[:end]), unicode.IsSpace) + "…"
+}
@@ -0,0 +1,65 @@
+package content_test
+
+import (
+	"time"
+
+	"github.com/aholstenson/logseq-go/content"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PlainText", func() {
+	var block *content.Block
+
+	BeforeEach(func() {
+		block = content.NewBlock(
+			content.NewParagraph(
+				content.NewTaskMarker(content.TaskStatusTodo),
+				content.NewText("Read "),
+				content.NewStrong(content.NewPageLink("Dune")),
+				content.NewText(" "),
+				content.NewHashtag("books"),
+			),
+			content.NewProperties(
+				content.NewProperty("rating", content.NewText("5")),
+			),
+			content.NewLogbook(
+				content.NewLogbookEntryRaw("CLOCK: running"),
+			),
+		)
+	})
+
+	It("writes text without markup", func() {
+		Expect(content.PlainText(block)).To(Equal("Read Dune #books\n\nrating: 5\n\nCLOCK: running"))
+	})
+
+	It("keeps the brackets of page references", func() {
+		Expect(block.Content().PlainText(
+			content.WithPageRefBrackets(),
+			content.WithoutProperties(),
+			content.WithoutLogbooks(),
+		)).To(Equal("Read [[Dune]] #books"))
+	})
+
+	It("writes tags as words", func() {
+		text := content.PlainText(block, content.WithHashtagsAsWords(), content.WithoutProperties(), content.WithoutLogbooks())
+		Expect(text).To(Equal("Read Dune books"))
+	})
+
+	It("writes the entries of logbooks", func() {
+		start := time.Date(2023, 6, 26, 17, 25, 56, 0, time.UTC)
+		logbook := content.NewLogbook(
+			content.NewLogbookEntryClock(start, start.Add(2*time.Second)),
+		)
+
+		Expect(content.PlainText(logbook)).To(Equal("CLOCK: [2023-06-26 Mon 17:25:56]--[2023-06-26 Mon 17:25:58]"))
+	})
+
+	It("cuts long text at a word", func() {
+		paragraph := content.NewParagraph(content.NewText("one two three four"))
+
+		Expect(content.PlainText(paragraph, content.WithMaxLength(12))).To(Equal("one two…"))
+		Expect(content.PlainText(paragraph, content.WithMaxLength(18))).To(Equal("one two three four"))
+		Expect(content.PlainText(content.NewText("abcdefgh"), content.WithMaxLength(5))).To(Equal("abcd…"))
+	})
+})
@@ -1,5 +1,7 @@
 package content
 
+import "strings"
+
 // Properties is a collection of Property nodes.
 type Properties struct {
 	baseNodeWithChildren
@@ -45,13 +47,7 @@ func (p *Properties) Get(key string) NodeList {
 
 // Set a Property node by name. If a Property with the given name already exists, it will be replaced.
 func (p *Properties) Set(key string, nodes ...Node) {
-	property := p.GetAsNode(key)
-	if property == nil {
-		property = NewProperty(key)
-		p.AddChild(property)
-	}
-
-	property.SetChildren(nodes...)
+	p.property(key).SetChildren(nodes...)
 }
 
 // Remove a Property node by name. If a Property with the given name does not exist this does nothing.
@@ -86,6 +82,36 @@ type Property struct {
 	// `:ignored-page-references-keywords` sets up. A page is not found via the
 	// value of such a property, not even where the value is written as a link.
 	PageRefsIgnored bool
+
+	// SeparatedByCommas is whether the value of this property is a list of
+	// values separated by commas, which is what
+	// `:property/separated-by-commas` sets up. Strings splits such a value into
+	// its parts. The properties that IsAlwaysSeparatedByCommas names are
+	// split even if this is not set.
+	SeparatedByCommas bool
+}
+
+// alwaysSeparatedByCommas are the properties whose value Logseq always reads as
+// a list of pages separated by commas, no matter what the graph configures.
+var alwaysSeparatedByCommas = []string{"alias", "aliases", "tags"}
+
+// IsAlwaysSeparatedByCommas checks if Logseq always reads the value of the
+// property with the given name as a list separated by commas.
+func IsAlwaysSeparatedByCommas(name string) bool {
+	name = strings.ToLower(name)
+	for _, separated := range alwaysSeparatedByCommas {
+		if name == separated {
+			return true
+		}
+	}
+
+	return false
+}
+
+// separatedByCommas checks if the value of the property is a list of values
+// separated by commas.
+func (p *Property) separatedByCommas() bool {
+	return p.SeparatedByCommas || IsAlwaysSeparatedByCommas(p.Name)
 }
 
 // NewProperty creates a new Property node with the given name and values.
@@ -110,12 +136,22 @@ func (p *Property) WithPageRefsIgnored(ignored bool) *Property {
 	return p
 }
 
+// WithSeparatedByCommas sets whether the value of this property is a list of
+// values separated by commas.
+func (p *Property) WithSeparatedByCommas(separated bool) *Property {
+	p.SeparatedByCommas = separated
+	return p
+}
+
 func (p *Property) debug(p2 *debugPrinter) {
 	p2.StartType("Property")
 	p2.Field("Name", p.Name)
 	if p.PageRefsIgnored {
 		p2.Field("PageRefsIgnored", "true")
 	}
+	if p.SeparatedByCommas {
+		p2.Field("SeparatedByCommas", "true")
+	}
 	p2.Children(p)
 	p2.EndType()
 }
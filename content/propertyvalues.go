@@ -0,0 +1,446 @@
+package content
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aholstenson/logseq-go/internal/utils"
+)
+
+// DefaultDateFormat is the format Logseq titles journals with unless the graph
+// is configured to use another one, and so the format dates in properties are
+// usually written in.
+const DefaultDateFormat = "MMM do, yyyy"
+
+// String gets the value of the property as text without any markup, so that
+// `[[Example]]` is read as `Example`.
+func (p *Property) String() string {
+	return p.Children().PlainText()
+}
+
+// Strings gets the values of a property that can hold several of them. If the
+// property is separated by commas, as `alias::` and `tags::` always are, the
+// values are those that Split returns. Other properties hold several values
+// only when they are written as page references, such as
+// `related:: [[A]] [[B]]`, and otherwise hold the single value that String
+// returns.
+func (p *Property) Strings() []string {
+	if p.separatedByCommas() {
+		return p.Split()
+	}
+
+	if titles := p.PageRefs(); len(titles) > 0 && onlyPageRefs(p.Children()) {
+		return titles
+	}
+
+	value := strings.TrimSpace(p.String())
+	if value == "" {
+		return nil
+	}
+
+	return []string{value}
+}
+
+// Split gets the values of the property as a list separated by commas, even if
+// the property is not marked as separated by commas. Page references are taken
+// as they are, while the rest of the value is split on commas.
+func (p *Property) Split() []string {
+	return splitPropertyValues(p.Children())
+}
+
+// PageRefs gets the titles of the pages the value of the property refers to.
+// A property whose references are ignored does not refer to any pages.
+func (p *Property) PageRefs() []string {
+	var titles []string
+	for _, ref := range (NodeList{p}).PageReferences() {
+		titles = append(titles, ref.(PageRef).GetTo())
+	}
+
+	return titles
+}
+
+// Int gets the value of the property as a whole number, returning false if
+// the value is not one.
+func (p *Property) Int() (int64, bool) {
+	value, err := strconv.ParseInt(strings.TrimSpace(p.String()), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return value, true
+}
+
+// Float gets the value of the property as a number, returning false if the
+// value is not one. See ParseNumber for the numbers that are read.
+func (p *Property) Float() (float64, bool) {
+	return ParseNumber(strings.TrimSpace(p.String()))
+}
+
+// decimalNumber matches a plain decimal number, such as `-12` or `4.5`.
+var decimalNumber = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)$`)
+
+// ParseNumber reads a plain decimal number, such as `-12` or `4.5`, returning
+// false if the value is not one. Values such as `NaN`, `Inf`, `1e3` and hex
+// numbers are not read, as they are not numbers as Logseq writes them.
+func ParseNumber(value string) (float64, bool) {
+	if !decimalNumber.MatchString(value) {
+		return 0, false
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsInf(number, 0) {
+		return 0, false
+	}
+
+	return number, true
+}
+
+// Bool gets the value of the property as a boolean, which Logseq writes as
+// `true` or `false`. Returns false as the second value for anything else.
+func (p *Property) Bool() (bool, bool) {
+	switch strings.TrimSpace(p.String()) {
+	case "true":
+		return true, true
+	case "false":
+		return false, true
+	}
+
+	return false, false
+}
+
+// Date gets the value of the property as a date. Dates are either ISO 8601
+// dates such as `2023-06-26`, RFC 3339 timestamps, or links to journals, such
+// as `[[Jun 26th, 2023]]`. The format is the Logseq format journals are
+// titled with in the graph, with an empty format meaning DefaultDateFormat.
+func (p *Property) Date(format string) (time.Time, bool) {
+	if format == "" {
+		format = DefaultDateFormat
+	}
+
+	journalFormat := utils.NewDateFormat(format)
+
+	candidates := []string{strings.TrimSpace(p.String())}
+	candidates = append(candidates, p.PageRefs()...)
+
+	for _, candidate := range candidates {
+		if date, err := time.ParseInLocation("2006-01-02", candidate, time.Local); err == nil {
+			return date, true
+		}
+
+		if date, err := time.Parse(time.RFC3339, candidate); err == nil {
+			return date, true
+		}
+
+		if date, err := journalFormat.Parse(candidate); err == nil {
+			return date, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// Duration gets the value of the property as a duration. Durations are either
+// written the way Go writes them, such as `1h30m`, or as a clock, such as
+// `01:30` or `01:30:00`, which is how Logseq writes the time spent on tasks.
+func (p *Property) Duration() (time.Duration, bool) {
+	value := strings.TrimSpace(p.String())
+	if duration, err := time.ParseDuration(value); err == nil {
+		return duration, true
+	}
+
+	parts := strings.Split(value, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, false
+	}
+
+	units := []time.Duration{time.Hour, time.Minute, time.Second}
+	var duration time.Duration
+	for idx, part := range parts {
+		number, err := strconv.ParseUint(part, 10, 32)
+		if err != nil || (idx > 0 && (len(part) != 2 || number >= 60)) {
+			return 0, false
+		}
+
+		duration += time.Duration(number) * units[idx]
+	}
+
+	return duration, true
+}
+
+// SetString sets the value of the property to text.
+func (p *Property) SetString(value string) {
+	p.SetChildren(NewText(value))
+}
+
+// SetStrings sets the values of a property that can hold several of them,
+// separated by commas. If the property is separated by commas the values are
+// references to the pages they name, which is how Logseq reads them.
+func (p *Property) SetStrings(values ...string) {
+	if !p.separatedByCommas() {
+		p.SetString(strings.Join(values, ", "))
+		return
+	}
+
+	nodes := make([]Node, 0, len(values)*2)
+	for idx, value := range values {
+		if idx > 0 {
+			nodes = append(nodes, NewText(", "))
+		}
+
+		nodes = append(nodes, NewPageRefText(value))
+	}
+
+	p.SetChildren(nodes...)
+}
+
+// SetPageRefs sets the value of the property to links to pages, separated by
+// commas, such as `tags:: [[a]], [[b]]`.
+func (p *Property) SetPageRefs(titles ...string) {
+	nodes := make([]Node, 0, len(titles)*2)
+	for idx, title := range titles {
+		if idx > 0 {
+			nodes = append(nodes, NewText(", "))
+		}
+
+		nodes = append(nodes, NewPageLink(title))
+	}
+
+	p.SetChildren(nodes...)
+}
+
+// SetInt sets the value of the property to a whole number.
+func (p *Property) SetInt(value int64) {
+	p.SetString(strconv.FormatInt(value, 10))
+}
+
+// SetFloat sets the value of the property to a number, written as short as it
+// can be without losing precision.
+func (p *Property) SetFloat(value float64) {
+	p.SetString(strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+// SetBool sets the value of the property to `true` or `false`.
+func (p *Property) SetBool(value bool) {
+	p.SetString(strconv.FormatBool(value))
+}
+
+// SetDate sets the value of the property to a link to the journal of the
+// date, such as `[[Jun 26th, 2023]]`, which is what Logseq writes when a date
+// is picked for a property. The format is the Logseq format journals are
+// titled with in the graph, with an empty format meaning DefaultDateFormat.
+func (p *Property) SetDate(date time.Time, format string) {
+	if format == "" {
+		format = DefaultDateFormat
+	}
+
+	p.SetChildren(NewPageLink(utils.NewDateFormat(format).Format(date)))
+}
+
+// SetDuration sets the value of the property to a duration, written the way
+// Go writes it without trailing zero units, such as `1h30m`.
+func (p *Property) SetDuration(duration time.Duration) {
+	value := duration.String()
+	if duration%time.Minute == 0 && duration != 0 {
+		value = strings.TrimSuffix(value, "0s")
+		if duration%time.Hour == 0 {
+			value = strings.TrimSuffix(value, "0m")
+		}
+	}
+
+	p.SetString(value)
+}
+
+// splitPropertyValues splits the value of a property that is separated by
+// commas into the values it holds. Page references are taken as they are,
+// while the text around them is split on commas, both the ASCII one and the
+// full width one Logseq also accepts.
+func splitPropertyValues(nodes NodeList) []string {
+	var values []string
+
+	var text strings.Builder
+	takeText := func() {
+		parts := strings.FieldsFunc(text.String(), isPropertyValueSeparator)
+		for _, part := range parts {
+			part = strings.TrimSpace(part)
+			if part != "" {
+				values = append(values, part)
+			}
+		}
+
+		text.Reset()
+	}
+
+	for _, node := range nodes {
+		if ref, ok := node.(PageRef); ok {
+			takeText()
+			values = append(values, ref.GetTo())
+			continue
+		}
+
+		text.WriteString(NodeList{node}.PlainText())
+	}
+
+	takeText()
+	return values
+}
+
+// onlyPageRefs checks if nodes are page references with nothing but
+// separators and space between them.
+func onlyPageRefs(nodes NodeList) bool {
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case PageRef:
+		case *Text:
+			if strings.TrimFunc(n.Value, func(r rune) bool {
+				return isPropertyValueSeparator(r) || r == ' ' || r == '\t'
+			}) != "" {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+func isPropertyValueSeparator(r rune) bool {
+	return r == ',' || r == '，'
+}
+
+// String gets the value of a property as text, or an empty string if the
+// property does not exist. See Property.String.
+func (p *Properties) String(key string) string {
+	if property := p.GetAsNode(key); property != nil {
+		return property.String()
+	}
+
+	return ""
+}
+
+// Strings gets the values of a property, or nil if the property does not
+// exist. See Property.Strings.
+func (p *Properties) Strings(key string) []string {
+	if property := p.GetAsNode(key); property != nil {
+		return property.Strings()
+	}
+
+	return nil
+}
+
+// PageRefs gets the titles of the pages a property refers to, or nil if the
+// property does not exist. See Property.PageRefs.
+func (p *Properties) PageRefs(key string) []string {
+	if property := p.GetAsNode(key); property != nil {
+		return property.PageRefs()
+	}
+
+	return nil
+}
+
+// Int gets the value of a property as a whole number, returning false if the
+// property does not exist or is not a number.
+func (p *Properties) Int(key string) (int64, bool) {
+	if property := p.GetAsNode(key); property != nil {
+		return property.Int()
+	}
+
+	return 0, false
+}
+
+// Float gets the value of a property as a number, returning false if the
+// property does not exist or is not a number.
+func (p *Properties) Float(key string) (float64, bool) {
+	if property := p.GetAsNode(key); property != nil {
+		return property.Float()
+	}
+
+	return 0, false
+}
+
+// Bool gets the value of a property as a boolean, returning false as the
+// second value if the property does not exist or is not a boolean.
+func (p *Properties) Bool(key string) (bool, bool) {
+	if property := p.GetAsNode(key); property != nil {
+		return property.Bool()
+	}
+
+	return false, false
+}
+
+// Date gets the value of a property as a date, returning false if the
+// property does not exist or is not a date. See Property.Date.
+func (p *Properties) Date(key string, format string) (time.Time, bool) {
+	if property := p.GetAsNode(key); property != nil {
+		return property.Date(format)
+	}
+
+	return time.Time{}, false
+}
+
+// Duration gets the value of a property as a duration, returning false if the
+// property does not exist or is not a duration. See Property.Duration.
+func (p *Properties) Duration(key string) (time.Duration, bool) {
+	if property := p.GetAsNode(key); property != nil {
+		return property.Duration()
+	}
+
+	return 0, false
+}
+
+// property gets a Property node by name, adding it if it does not exist.
+func (p *Properties) property(key string) *Property {
+	property := p.GetAsNode(key)
+	if property == nil {
+		property = NewProperty(key)
+		p.AddChild(property)
+	}
+
+	return property
+}
+
+// SetString sets the value of a property to text.
+func (p *Properties) SetString(key string, value string) {
+	p.property(key).SetString(value)
+}
+
+// SetStrings sets the values of a property. See Property.SetStrings.
+func (p *Properties) SetStrings(key string, values ...string) {
+	p.property(key).SetStrings(values...)
+}
+
+// SetPageRefs sets the value of a property to links to pages, such as
+// `tags:: [[a]], [[b]]`.
+func (p *Properties) SetPageRefs(key string, titles ...string) {
+	p.property(key).SetPageRefs(titles...)
+}
+
+// SetInt sets the value of a property to a whole number.
+func (p *Properties) SetInt(key string, value int64) {
+	p.property(key).SetInt(value)
+}
+
+// SetFloat sets the value of a property to a number.
+func (p *Properties) SetFloat(key string, value float64) {
+	p.property(key).SetFloat(value)
+}
+
+// SetBool sets the value of a property to `true` or `false`.
+func (p *Properties) SetBool(key string, value bool) {
+	p.property(key).SetBool(value)
+}
+
+// SetDate sets the value of a property to a link to the journal of a date.
+// See Property.SetDate.
+func (p *Properties) SetDate(key string, date time.Time, format string) {
+	p.property(key).SetDate(date, format)
+}
+
+// SetDuration sets the value of a property to a duration. See
+// Property.SetDuration.
+func (p *Properties) SetDuration(key string, duration time.Duration) {
+	p.property(key).SetDuration(duration)
+}
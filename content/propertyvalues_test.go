@@ -0,0 +1,146 @@
+package content_test
+
+import (
+	"time"
+
+	"github.com/aholstenson/logseq-go/content"
+	"github.com/aholstenson/logseq-go/internal/markdown"
+	. "github.com/aholstenson/logseq-go/internal/tests"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Property values", func() {
+	parse := func(src string, opts ...markdown.ParseOption) *content.Properties {
+		block, err := markdown.ParseString(src, opts...)
+		Expect(err).ToNot(HaveOccurred())
+		return block.Properties()
+	}
+
+	write := func(properties *content.Properties) string {
+		out, err := markdown.AsString(content.NewBlock(properties))
+		Expect(err).ToNot(HaveOccurred())
+		return out
+	}
+
+	It("reads text without markup", func() {
+		properties := parse("author:: [[Frank Herbert]]\n")
+		Expect(properties.String("author")).To(Equal("Frank Herbert"))
+		Expect(properties.String("missing")).To(Equal(""))
+	})
+
+	It("splits the values of properties separated by commas", func() {
+		properties := parse("tags:: [[a]], b，c\nkey:: First, Second\n")
+		Expect(properties.Strings("tags")).To(Equal([]string{"a", "b", "c"}))
+		Expect(properties.Strings("key")).To(Equal([]string{"First, Second"}))
+
+		properties = parse("key:: First, Second\n", markdown.WithPropertiesSeparatedByCommas("key"))
+		Expect(properties.Strings("key")).To(Equal([]string{"First", "Second"}))
+	})
+
+	It("splits alias and tags on commas in properties made in code", func() {
+		properties := content.NewProperties(
+			content.NewProperty("tags", content.NewText("a, b")),
+			content.NewProperty("alias", content.NewPageLink("A"), content.NewText(", B")),
+			content.NewProperty("key", content.NewText("First, Second")),
+		)
+		Expect(properties.Strings("tags")).To(Equal([]string{"a", "b"}))
+		Expect(properties.Strings("alias")).To(Equal([]string{"A", "B"}))
+		Expect(properties.Strings("key")).To(Equal([]string{"First, Second"}))
+		Expect(properties.GetAsNode("key").Split()).To(Equal([]string{"First", "Second"}))
+	})
+
+	It("reads page references", func() {
+		properties := parse("related:: [[A]] [[B]]\nauthor:: [[Someone]]\n", markdown.WithIgnoredPageReferences("author"))
+		Expect(properties.PageRefs("related")).To(Equal([]string{"A", "B"}))
+		Expect(properties.Strings("related")).To(Equal([]string{"A", "B"}))
+		Expect(properties.PageRefs("author")).To(BeNil())
+	})
+
+	It("reads numbers and booleans", func() {
+		properties := parse("pages:: 412\nrating:: 4.5\nread:: true\nname:: Dune\n")
+
+		pages, ok := properties.Int("pages")
+		Expect(ok).To(BeTrue())
+		Expect(pages).To(Equal(int64(412)))
+
+		rating, ok := properties.Float("rating")
+		Expect(ok).To(BeTrue())
+		Expect(rating).To(Equal(4.5))
+
+		for _, value := range []string{"NaN", "Inf", "1e3", "0x1p4", "4.5 stars"} {
+			_, ok = content.NewProperty("rating", content.NewText(value)).Float()
+			Expect(ok).To(BeFalse(), value)
+		}
+
+		read, ok := properties.Bool("read")
+		Expect(ok).To(BeTrue())
+		Expect(read).To(BeTrue())
+
+		_, ok = properties.Int("name")
+		Expect(ok).To(BeFalse())
+		_, ok = properties.Bool("name")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("reads dates written as journal titles and ISO dates", func() {
+		properties := parse("due:: [[Jun 26th, 2023]]\nstart:: 2023-06-26\nother:: [[26.06.2023]]\n")
+		expected := time.Date(2023, 6, 26, 0, 0, 0, 0, time.Local)
+
+		for _, key := range []string{"due", "start"} {
+			date, ok := properties.Date(key, "")
+			Expect(ok).To(BeTrue())
+			Expect(date).To(Equal(expected))
+		}
+
+		date, ok := properties.Date("other", "dd.MM.yyyy")
+		Expect(ok).To(BeTrue())
+		Expect(date).To(Equal(expected))
+
+		_, ok = properties.Date("other", "")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("reads durations", func() {
+		properties := parse("estimate:: 1h30m\nspent:: 01:15:30\nbad:: 1:75\n")
+
+		estimate, ok := properties.Duration("estimate")
+		Expect(ok).To(BeTrue())
+		Expect(estimate).To(Equal(90 * time.Minute))
+
+		spent, ok := properties.Duration("spent")
+		Expect(ok).To(BeTrue())
+		Expect(spent).To(Equal(time.Hour + 15*time.Minute + 30*time.Second))
+
+		_, ok = properties.Duration("bad")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("writes values the way Logseq does", func() {
+		properties := content.NewProperties()
+		properties.SetPageRefs("tags", "a", "b")
+		properties.SetInt("pages", 412)
+		properties.SetFloat("rating", 4.5)
+		properties.SetBool("read", true)
+		properties.SetDate("due", time.Date(2023, 6, 26, 0, 0, 0, 0, time.Local), "")
+		properties.SetDuration("estimate", 90*time.Minute)
+
+		Expect(write(properties)).To(Equal(
+			"tags:: [[a]], [[b]]\n" +
+				"pages:: 412\n" +
+				"rating:: 4.5\n" +
+				"read:: true\n" +
+				"due:: [[Jun 26th, 2023]]\n" +
+				"estimate:: 1h30m",
+		))
+	})
+
+	It("writes the values of properties separated by commas as it reads them", func() {
+		properties := parse("tags:: old\n")
+		properties.SetStrings("tags", "a", "b")
+
+		Expect(properties.Strings("tags")).To(Equal([]string{"a", "b"}))
+		Expect(write(properties)).To(Equal("tags:: a, b"))
+		Expect(properties).To(EqualNode(parse("tags:: a, b\n")))
+	})
+})
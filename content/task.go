@@ -0,0 +1,304 @@
+package content
+
+import "time"
+
+// Task is a handle to the task of a block, which is the task marker at the
+// start of its content together with the priority, dates and logbook that
+// belong to it. The handle reads the block every time, so it stays valid
+// while the block is changed in other ways.
+type Task struct {
+	block *Block
+}
+
+// Task gets a handle to the task of this block. Blocks that are not tasks
+// have the status TaskStatusNone, and become tasks when a status is set.
+func (b *Block) Task() *Task {
+	return &Task{block: b}
+}
+
+// Block gets the block the task belongs to.
+func (t *Task) Block() *Block {
+	return t.block
+}
+
+// Status gets the status of the task, or TaskStatusNone if the block is not
+// a task.
+func (t *Task) Status() TaskStatus {
+	if marker := t.marker(); marker != nil {
+		return marker.Status
+	}
+
+	return TaskStatusNone
+}
+
+// Priority gets the priority of the task, or PriorityNone if it does not have
+// one.
+func (t *Task) Priority() Priority {
+	if priority := t.priority(); priority != nil {
+		return priority.Priority
+	}
+
+	return PriorityNone
+}
+
+// SetPriority sets the priority of the task, which is written directly after
+// the task marker. PriorityNone removes the priority.
+func (t *Task) SetPriority(priority Priority) {
+	existing := t.priority()
+	switch {
+	case existing != nil && priority == PriorityNone:
+		existing.RemoveSelf()
+	case existing != nil:
+		existing.Priority = priority
+	case priority != PriorityNone:
+		node := NewTaskPriority(priority)
+		if marker := t.marker(); marker != nil {
+			marker.Parent().InsertChildAfter(node, marker)
+		} else {
+			t.paragraph().PrependChild(node)
+		}
+	}
+}
+
+// Scheduled gets the `SCHEDULED` date of the task, or nil if it does not have
+// one.
+func (t *Task) Scheduled() *TaskDate {
+	return t.block.Scheduled()
+}
+
+// Deadline gets the `DEADLINE` date of the task, or nil if it does not have
+// one.
+func (t *Task) Deadline() *TaskDate {
+	return t.block.Deadline()
+}
+
+// IsRepeating returns true if the scheduled date or deadline of the task
+// repeats.
+func (t *Task) IsRepeating() bool {
+	for _, date := range []*TaskDate{t.Scheduled(), t.Deadline()} {
+		if date != nil && date.Repeater != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SetStatus changes the status of the task the way Logseq does when the
+// marker is changed in the app, with now being the time of the change.
+// Setting TaskStatusNone makes the block a regular block again.
+//
+// Time is tracked while a task is NOW or DOING, so a clock is started in the
+// logbook when the task becomes one of them and stopped when it no longer is.
+//
+// Completing a repeating task does not leave it done. Instead the change is
+// recorded in the logbook as `* State "DONE" from "TODO" [...]`, the
+// scheduled date and deadline move to their next occurrence as described by
+// their repeaters, and the task goes back to TODO, or LATER for tasks that
+// were LATER or NOW.
+func (t *Task) SetStatus(status TaskStatus, now time.Time) {
+	from := t.Status()
+	if status == from {
+		return
+	}
+
+	if isDoingStatus(from) && !isDoingStatus(status) {
+		stopClock(t.block, now)
+	}
+
+	if status != TaskStatusDone || !t.IsRepeating() {
+		setBlockTaskStatus(t.block, status)
+
+		if isDoingStatus(status) && !isDoingStatus(from) {
+			startClock(t.block, now)
+		}
+		return
+	}
+
+	t.block.Logbook(true).AddChild(NewLogbookEntryStateChange(from, status, now))
+
+	for _, date := range []*TaskDate{t.Scheduled(), t.Deadline()} {
+		if date != nil {
+			date.Advance(now)
+		}
+	}
+
+	setBlockTaskStatus(t.block, repeatedTaskStatus(from))
+}
+
+// repeatedTaskStatus picks the status a repeating task goes back to once it
+// is completed, keeping to the workflow the task was in.
+func repeatedTaskStatus(from TaskStatus) TaskStatus {
+	switch from {
+	case TaskStatusLater, TaskStatusNow:
+		return TaskStatusLater
+	}
+
+	return TaskStatusTodo
+}
+
+func (t *Task) marker() *TaskMarker {
+	marker, _ := t.block.Content().FindDeep(IsOfType[*TaskMarker]()).(*TaskMarker)
+	return marker
+}
+
+func (t *Task) priority() *TaskPriority {
+	priority, _ := t.block.Content().FindDeep(IsOfType[*TaskPriority]()).(*TaskPriority)
+	return priority
+}
+
+// paragraph gets the first paragraph of the block, adding one if there is
+// none.
+func (t *Task) paragraph() *Paragraph {
+	paragraph, _ := t.block.Content().Find(IsOfType[*Paragraph]()).(*Paragraph)
+	if paragraph == nil {
+		paragraph = NewParagraph()
+		t.block.PrependChild(paragraph)
+	}
+
+	return paragraph
+}
+
+// setBlockTaskStatus sets the status of the task marker of a block, adding a
+// marker to its first paragraph if it does not have one and removing the
+// marker for TaskStatusNone.
+func setBlockTaskStatus(block *Block, status TaskStatus) {
+	task := block.Task()
+	marker := task.marker()
+	switch {
+	case marker != nil && status == TaskStatusNone:
+		marker.RemoveSelf()
+	case marker != nil:
+		marker.Status = status
+	case status != TaskStatusNone:
+		task.paragraph().PrependChild(NewTaskMarker(status))
+	}
+}
+
+// Logbook gets the logbook of this block. If the block does not have one and
+// create is set, an empty logbook is added after the content, properties and
+// dates of the task, which is where Logseq writes it. Otherwise nil is
+// returned.
+func (b *Block) Logbook(create bool) *Logbook {
+	for node := b.FirstChild(); node != nil; node = node.NextSibling() {
+		if logbook, ok := node.(*Logbook); ok {
+			return logbook
+		}
+	}
+
+	if !create {
+		return nil
+	}
+
+	logbook := NewLogbook()
+
+	// Logseq keeps the logbook directly after the content of the task, its
+	// properties and its dates, so place it after the first paragraph and any
+	// properties or dates around it.
+	var after Node
+	seenParagraph := false
+nodes:
+	for node := b.FirstChild(); node != nil; node = node.NextSibling() {
+		switch node.(type) {
+		case *Paragraph:
+			if seenParagraph {
+				break nodes
+			}
+			seenParagraph = true
+		case *Properties, *TaskDate:
+		default:
+			break nodes
+		}
+
+		after = node
+	}
+
+	if after == nil {
+		b.PrependChild(logbook)
+	} else {
+		b.InsertChildAfter(logbook, after)
+	}
+
+	return logbook
+}
+
+// next gets the next occurrence of a date that repeats with this repeater,
+// once the task it belongs to is completed at now. Dates without a time of
+// day are compared by day.
+func (r *Repeater) next(date time.Time, hasTime bool, now time.Time) time.Time {
+	if r.Value <= 0 {
+		return date
+	}
+
+	if !hasTime {
+		now = truncateToDay(now)
+	}
+
+	switch r.Type {
+	case RepeaterTypeCatchUp:
+		next := r.add(date)
+		for !next.After(now) {
+			next = r.add(next)
+		}
+		return next
+	case RepeaterTypeRestart:
+		start := now
+		if hasTime && r.Unit != RepeaterUnitHour {
+			// The time of day stays the same, only the day moves
+			start = time.Date(now.Year(), now.Month(), now.Day(), date.Hour(), date.Minute(), 0, 0, now.Location())
+		}
+		return r.add(start)
+	}
+
+	return r.add(date)
+}
+
+// add moves a date forward by one interval of the repeater.
+func (r *Repeater) add(date time.Time) time.Time {
+	switch r.Unit {
+	case RepeaterUnitHour:
+		return date.Add(time.Duration(r.Value) * time.Hour)
+	case RepeaterUnitDay:
+		return date.AddDate(0, 0, r.Value)
+	case RepeaterUnitWeek:
+		return date.AddDate(0, 0, 7*r.Value)
+	case RepeaterUnitMonth:
+		return addMonths(date, r.Value)
+	case RepeaterUnitYear:
+		return addMonths(date, 12*r.Value)
+	}
+
+	return date
+}
+
+// addMonths moves a date forward by a number of months. Dates at the end of a
+// month that the target month does not have, such as the 31st or February 29,
+// move to the last day of the target month rather than overflowing into the
+// month after it.
+func addMonths(date time.Time, months int) time.Time {
+	target := time.Date(date.Year(), date.Month()+time.Month(months), 1, 0, 0, 0, 0, date.Location())
+
+	day := date.Day()
+	if last := target.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+
+	return time.Date(target.Year(), target.Month(), day, date.Hour(), date.Minute(), date.Second(), date.Nanosecond(), date.Location())
+}
+
+// Advance moves the date to its next occurrence, for a task completed at now.
+// Returns false and leaves the date as it is if the date does not repeat.
+func (t *TaskDate) Advance(now time.Time) bool {
+	if t.Repeater == nil {
+		return false
+	}
+
+	next := t.Repeater.next(t.Date, t.HasTime, now)
+	if t.HasTime {
+		t.Date = truncateToMinute(next)
+	} else {
+		t.Date = truncateToDay(next)
+	}
+
+	return true
+}
@@ -0,0 +1,144 @@
+package content_test
+
+import (
+	"time"
+
+	"github.com/aholstenson/logseq-go/content"
+	"github.com/aholstenson/logseq-go/internal/markdown"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Task", func() {
+	now := time.Date(2023, 6, 30, 10, 15, 30, 0, time.Local)
+
+	parse := func(src string) *content.Block {
+		root, err := markdown.ParseString(src)
+		Expect(err).ToNot(HaveOccurred())
+		return root.Blocks()[0]
+	}
+
+	write := func(block *content.Block) string {
+		out, err := markdown.AsString(block.Parent())
+		Expect(err).ToNot(HaveOccurred())
+		return out
+	}
+
+	It("reads the status, priority and dates of a task", func() {
+		task := parse("- DOING [#B] Write report\n  SCHEDULED: <2023-06-26 Mon>\n  DEADLINE: <2023-07-01 Sat>\n").Task()
+
+		Expect(task.Status()).To(Equal(content.TaskStatusDoing))
+		Expect(task.Priority()).To(Equal(content.PriorityB))
+		Expect(task.Scheduled().Date).To(Equal(time.Date(2023, 6, 26, 0, 0, 0, 0, time.Local)))
+		Expect(task.Deadline().Date).To(Equal(time.Date(2023, 7, 1, 0, 0, 0, 0, time.Local)))
+		Expect(task.IsRepeating()).To(BeFalse())
+	})
+
+	It("reads a block without a marker as not being a task", func() {
+		task := parse("- Just text\n").Task()
+
+		Expect(task.Status()).To(Equal(content.TaskStatusNone))
+		Expect(task.Priority()).To(Equal(content.PriorityNone))
+		Expect(task.Scheduled()).To(BeNil())
+	})
+
+	It("changes the status and priority of a task", func() {
+		block := parse("- Water the plants\n")
+		task := block.Task()
+
+		task.SetStatus(content.TaskStatusTodo, now)
+		task.SetPriority(content.PriorityA)
+		Expect(write(block)).To(Equal("- TODO [#A] Water the plants"))
+
+		task.SetStatus(content.TaskStatusDone, now)
+		task.SetPriority(content.PriorityNone)
+		Expect(write(block)).To(Equal("- DONE Water the plants"))
+
+		task.SetStatus(content.TaskStatusNone, now)
+		Expect(write(block)).To(Equal("- Water the plants"))
+	})
+
+	It("restarts a repeating task from the day it is completed", func() {
+		block := parse("- TODO Water the plants\n  SCHEDULED: <2023-06-26 Mon .+2d>\n")
+
+		block.Task().SetStatus(content.TaskStatusDone, now)
+		Expect(write(block)).To(Equal(
+			"- TODO Water the plants\n" +
+				"  SCHEDULED: <2023-07-02 Sun .+2d>\n" +
+				"  :LOGBOOK:\n" +
+				"  * State \"DONE\" from \"TODO\" [2023-06-30 Fri 10:15:30]\n" +
+				"  :END:",
+		))
+	})
+
+	It("moves a cumulating date one interval", func() {
+		block := parse("- LATER Pay rent\n  DEADLINE: <2023-05-01 Mon +1m>\n")
+
+		block.Task().SetStatus(content.TaskStatusDone, now)
+
+		task := block.Task()
+		Expect(task.Status()).To(Equal(content.TaskStatusLater))
+		Expect(task.Deadline().Date).To(Equal(time.Date(2023, 6, 1, 0, 0, 0, 0, time.Local)))
+	})
+
+	It("moves a date at the end of a month to the end of a shorter month", func() {
+		block := parse("- TODO Send invoice\n  SCHEDULED: <2024-01-31 Wed 16:00 +1m>\n")
+
+		block.Task().SetStatus(content.TaskStatusDone, now)
+
+		Expect(block.Scheduled().Date).To(Equal(time.Date(2024, 2, 29, 16, 0, 0, 0, time.Local)))
+		Expect(write(block)).To(HavePrefix("- TODO Send invoice\n  SCHEDULED: <2024-02-29 Thu 16:00 +1m>\n"))
+	})
+
+	It("moves a yearly date from February 29 to February 28", func() {
+		block := parse("- TODO Celebrate\n  DEADLINE: <2024-02-29 Thu +1y>\n")
+
+		block.Task().SetStatus(content.TaskStatusDone, now)
+
+		Expect(block.Deadline().Date).To(Equal(time.Date(2025, 2, 28, 0, 0, 0, 0, time.Local)))
+	})
+
+	It("catches a date up until it is in the future", func() {
+		block := parse("- NOW Weekly review\n  SCHEDULED: <2023-06-02 Fri 09:00 ++1w>\n")
+
+		block.Task().SetStatus(content.TaskStatusDone, now)
+
+		task := block.Task()
+		Expect(task.Status()).To(Equal(content.TaskStatusLater))
+		Expect(task.Scheduled().Date).To(Equal(time.Date(2023, 7, 7, 9, 0, 0, 0, time.Local)))
+		Expect(task.Scheduled().HasTime).To(BeTrue())
+	})
+
+	It("adds the logbook after the properties and dates of the task", func() {
+		block := parse("- TODO Write report\n" +
+			"  id:: 64a0c3f6-0000-4000-8000-000000000002\n" +
+			"  SCHEDULED: <2023-06-26 Mon>\n" +
+			"  - Outline\n")
+
+		block.Task().SetStatus(content.TaskStatusNow, now)
+		Expect(write(block)).To(Equal(
+			"- NOW Write report\n" +
+				"  id:: 64a0c3f6-0000-4000-8000-000000000002\n" +
+				"  SCHEDULED: <2023-06-26 Mon>\n" +
+				"  :LOGBOOK:\n" +
+				"  CLOCK: [2023-06-30 Fri 10:15:30]\n" +
+				"  :END:\n" +
+				"\t- Outline",
+		))
+	})
+
+	It("adds to the logbook that is already there", func() {
+		block := parse("- TODO Stretch\n" +
+			"  SCHEDULED: <2023-06-29 Thu .+1d>\n" +
+			"  :LOGBOOK:\n" +
+			"  * State \"DONE\" from \"TODO\" [2023-06-28 Wed 08:00:00]\n" +
+			"  :END:\n" +
+			"  id:: 64a0c3f6-0000-4000-8000-000000000001\n")
+
+		block.Task().SetStatus(content.TaskStatusDone, now)
+
+		Expect(block.Logbook(false).Children()).To(HaveLen(2))
+		Expect(block.Properties().Get("id")).ToNot(BeEmpty())
+		Expect(block.Scheduled().Date).To(Equal(time.Date(2023, 7, 1, 0, 0, 0, 0, time.Local)))
+	})
+})
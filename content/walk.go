@@ -0,0 +1,158 @@
+package content
+
+// WalkStatus tells Walk and Rewrite how to continue after visiting a node.
+type WalkStatus int
+
+const (
+	// WalkContinue continues with the children of the node and then with the
+	// rest of the tree.
+	WalkContinue WalkStatus = iota
+	// WalkSkipChildren skips the children of the node, but continues with the
+	// rest of the tree.
+	WalkSkipChildren
+	// WalkStop stops the walk.
+	WalkStop
+)
+
+// Walker is called by Walk for every node in the tree. It is called with
+// entering set to true when a node is reached and with entering set to false
+// once its children have been visited.
+type Walker func(node Node, entering bool) WalkStatus
+
+// Walk visits a node and everything below it, depth first and in the order the
+// nodes appear in the document. The returned status is WalkStop if the walker
+// stopped the walk.
+//
+// Walk is not meant for changing the tree, use Rewrite to replace, remove or
+// insert nodes.
+func Walk(node Node, walker Walker) WalkStatus {
+	status := walker(node, true)
+	if status == WalkStop {
+		return WalkStop
+	}
+
+	if status != WalkSkipChildren {
+		if children, ok := node.(HasChildren); ok {
+			for child := children.FirstChild(); child != nil; {
+				next := child.NextSibling()
+				if Walk(child, walker) == WalkStop {
+					return WalkStop
+				}
+				child = next
+			}
+		}
+	}
+
+	if walker(node, false) == WalkStop {
+		return WalkStop
+	}
+
+	return WalkContinue
+}
+
+// Cursor is the node currently visited by Rewrite, with methods for changing
+// the tree around it.
+type Cursor struct {
+	node    Node
+	parent  HasChildren
+	next    Node
+	removed bool
+}
+
+// Node returns the node being visited.
+func (c *Cursor) Node() Node {
+	return c.node
+}
+
+// Parent returns the parent of the node being visited.
+func (c *Cursor) Parent() HasChildren {
+	return c.parent
+}
+
+// Replace replaces the node with the given nodes. Replacing the node with no
+// nodes removes it. Nodes that are not allowed in the parent are ignored.
+func (c *Cursor) Replace(nodes ...Node) {
+	c.InsertBefore(nodes...)
+	c.Remove()
+}
+
+// Remove removes the node from the tree.
+func (c *Cursor) Remove() {
+	if c.removed {
+		return
+	}
+
+	c.parent.RemoveChild(c.node)
+	c.removed = true
+}
+
+// InsertBefore inserts nodes before the node being visited. Nodes that are
+// not allowed in the parent are ignored.
+func (c *Cursor) InsertBefore(nodes ...Node) {
+	if c.removed {
+		// The node is gone, so the nodes go where it used to be
+		c.insertBeforeNext(nodes)
+		return
+	}
+
+	for _, node := range nodes {
+		c.parent.InsertChildBefore(node, c.node)
+	}
+}
+
+// InsertAfter inserts nodes after the node being visited, and after any nodes
+// previously inserted after it. Nodes that are not allowed in the parent are
+// ignored.
+func (c *Cursor) InsertAfter(nodes ...Node) {
+	c.insertBeforeNext(nodes)
+}
+
+// insertBeforeNext inserts nodes before the sibling that followed the node
+// when it was reached, which keeps them in order after the node.
+func (c *Cursor) insertBeforeNext(nodes []Node) {
+	for _, node := range nodes {
+		if c.next == nil {
+			c.parent.AddChild(node)
+		} else {
+			c.parent.InsertChildBefore(node, c.next)
+		}
+	}
+}
+
+// Rewriter is called by Rewrite for every node in the tree, and can change the
+// tree around the node using the cursor.
+type Rewriter func(cursor *Cursor) WalkStatus
+
+// Rewrite visits everything below a node, depth first and in the order the
+// nodes appear in the document, and lets the rewriter replace, remove or insert
+// nodes as it goes.
+//
+// Nodes inserted by the rewriter are not visited, and neither are the children
+// of nodes that were replaced or removed. The rewriter may change the children
+// of the node it visits, but should not change other parts of the tree.
+func Rewrite(root HasChildren, rewriter Rewriter) WalkStatus {
+	for child := root.FirstChild(); child != nil; {
+		cursor := &Cursor{
+			node:   child,
+			parent: root,
+			next:   child.NextSibling(),
+		}
+
+		status := rewriter(cursor)
+		if status == WalkStop {
+			return WalkStop
+		}
+
+		if status != WalkSkipChildren && !cursor.removed {
+			if children, ok := child.(HasChildren); ok {
+				if Rewrite(children, rewriter) == WalkStop {
+					return WalkStop
+				}
+			}
+		}
+
+		child = cursor.next
+	}
+
+	return WalkContinue
+}
@@ -0,0 +1,157 @@
+package content_test
+
+import (
+	"github.com/aholstenson/logseq-go/content"
+	. "github.com/aholstenson/logseq-go/internal/tests"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Walking", func() {
+	var paragraph *content.Paragraph
+
+	BeforeEach(func() {
+		paragraph = content.NewParagraph(
+			content.NewText("a "),
+			content.NewStrong(content.NewText("b")),
+			content.NewText(" c"),
+		)
+	})
+
+	Describe("Walk", func() {
+		It("visits nodes when entering and leaving them", func() {
+			visits := make([]string, 0)
+			content.Walk(paragraph, func(node content.Node, entering bool) content.WalkStatus {
+				name := "?"
+				switch n := node.(type) {
+				case *content.Paragraph:
+					name = "paragraph"
+				case *content.Strong:
+					name = "strong"
+				case *content.Text:
+					name = n.Value
+				}
+
+				if !entering {
+					name = "/" + name
+				}
+				visits = append(visits, name)
+				return content.WalkContinue
+			})
+
+			Expect(visits).To(Equal([]string{
+				"paragraph",
+				"a ", "/a ",
+				"strong", "b", "/b", "/strong",
+				" c", "/ c",
+				"/paragraph",
+			}))
+		})
+
+		It("skips children", func() {
+			texts := make([]string, 0)
+			content.Walk(paragraph, func(node content.Node, entering bool) content.WalkStatus {
+				if _, ok := node.(*content.Strong); ok {
+					return content.WalkSkipChildren
+				}
+
+				if text, ok := node.(*content.Text); ok && entering {
+					texts = append(texts, text.Value)
+				}
+				return content.WalkContinue
+			})
+
+			Expect(texts).To(Equal([]string{"a ", " c"}))
+		})
+
+		It("stops", func() {
+			texts := make([]string, 0)
+			status := content.Walk(paragraph, func(node content.Node, entering bool) content.WalkStatus {
+				if text, ok := node.(*content.Text); ok && entering {
+					texts = append(texts, text.Value)
+					if text.Value == "b" {
+						return content.WalkStop
+					}
+				}
+				return content.WalkContinue
+			})
+
+			Expect(status).To(Equal(content.WalkStop))
+			Expect(texts).To(Equal([]string{"a ", "b"}))
+		})
+	})
+
+	Describe("Rewrite", func() {
+		It("replaces nodes with their children", func() {
+			content.Rewrite(paragraph, func(cursor *content.Cursor) content.WalkStatus {
+				if strong, ok := cursor.Node().(*content.Strong); ok {
+					cursor.Replace(strong.Children()...)
+				}
+				return content.WalkContinue
+			})
+
+			Expect(paragraph.Children()).To(EqualsNodes(
+				content.NewText("a "),
+				content.NewText("b"),
+				content.NewText(" c"),
+			))
+		})
+
+		It("removes nodes", func() {
+			content.Rewrite(paragraph, func(cursor *content.Cursor) content.WalkStatus {
+				if _, ok := cursor.Node().(*content.Text); ok {
+					cursor.Remove()
+				}
+				return content.WalkContinue
+			})
+
+			Expect(paragraph.Children()).To(EqualsNodes(
+				content.NewStrong(),
+			))
+		})
+
+		It("inserts nodes without visiting them", func() {
+			visited := 0
+			content.Rewrite(paragraph, func(cursor *content.Cursor) content.WalkStatus {
+				visited++
+				if text, ok := cursor.Node().(*content.Text); ok && text.Value == "a " {
+					cursor.InsertBefore(content.NewText("<"))
+					cursor.InsertAfter(content.NewText(">"), content.NewText("!"))
+				}
+				return content.WalkSkipChildren
+			})
+
+			Expect(visited).To(Equal(3))
+			Expect(paragraph.Children()).To(EqualsNodes(
+				content.NewText("<"),
+				content.NewText("a "),
+				content.NewText(">"),
+				content.NewText("!"),
+				content.NewStrong(content.NewText("b")),
+				content.NewText(" c"),
+			))
+		})
+
+		It("removes the properties of a block", func() {
+			block := content.NewBlock(
+				content.NewParagraph(content.NewText("text")),
+				content.NewProperties(
+					content.NewProperty("secret", content.NewText("value")),
+				),
+			)
+			block.Properties()
+
+			content.Rewrite(block, func(cursor *content.Cursor) content.WalkStatus {
+				if _, ok := cursor.Node().(*content.Properties); ok {
+					cursor.Remove()
+				}
+				return content.WalkContinue
+			})
+
+			Expect(block.FindProperties()).To(BeNil())
+			Expect(block.Children()).To(EqualsNodes(
+				content.NewParagraph(content.NewText("text")),
+			))
+		})
+	})
+})
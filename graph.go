@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -41,8 +42,13 @@ type Graph struct {
 	journalTitleFormat *utils.DateFormat
 
 	index         indexing.Index
+	vectors       *indexing.VectorIndex
 	changeWatcher *fsnotify.Watcher
 
+	// pages caches the content of pages that have been read, nil if caching
+	// is not enabled.
+	pages *pageCache
+
 	// changeHandlers tracks the goroutines that debounce and index file
 	// changes, so they can be waited for before the index is closed.
 	changeHandlers sync.WaitGroup
@@ -87,12 +93,31 @@ func Open(ctx context.Context, directory string, opts ...Option) (*Graph, error)
 
 	var index indexing.Index
 	if options.index {
-		index, err = indexing.NewBlugeIndex(config, options.indexDirectory)
+		indexOpts, err := options.indexOptions()
+		if err != nil {
+			return nil, err
+		}
+
+		index, err = indexing.NewBlugeIndex(config, options.indexDirectory, indexOpts...)
 		if err != nil {
 			return nil, fmt.Errorf("failed to open index: %w", err)
 		}
 	}
 
+	var vectors *indexing.VectorIndex
+	if options.embedder != nil {
+		if !options.index {
+			return nil, fmt.Errorf("an embedder requires indexing to be enabled")
+		}
+
+		vectors, err = indexing.NewVectorIndex(options.embedder, options.indexDirectory)
+		if err != nil {
+			// The index holds a lock on its directory until it is closed
+			index.Close()
+			return nil, fmt.Errorf("failed to open vector index: %w", err)
+		}
+	}
+
 	g := &Graph{
 		options:   options,
 		directory: directory,
@@ -101,11 +126,16 @@ func Open(ctx context.Context, directory string, opts ...Option) (*Graph, error)
 		journalNameFormat:  journalNameFormat,
 		journalTitleFormat: journalTitleFormat,
 
-		index: index,
+		index:   index,
+		vectors: vectors,
 
 		watchers: make([]*Watcher, 0),
 	}
 
+	if options.pageCacheSize > 0 {
+		g.pages = newPageCache(options.pageCacheSize)
+	}
+
 	// Sync the graph with the index
 	err = g.sync(ctx, options.listener)
 	if err != nil {
@@ -155,7 +185,7 @@ func (g *Graph) openJournal(date time.Time, source pageSource) (Page, error) {
 
 	title := g.journalTitleFormat.Format(date)
 
-	return openOrCreatePage(source, path, PageTypeJournal, title, date, templatePath, g.markdownParseOptions()...)
+	return openOrCreatePage(source, path, PageTypeJournal, title, date, templatePath, g)
 }
 
 func (g *Graph) journalPath(date time.Time) (string, error) {
@@ -174,7 +204,7 @@ func (g *Graph) openPage(title string, source pageSource) (Page, error) {
 		return nil, err
 	}
 
-	page, err := openOrCreatePage(source, path, PageTypeDedicated, title, time.Time{}, "", g.markdownParseOptions()...)
+	page, err := openOrCreatePage(source, path, PageTypeDedicated, title, time.Time{}, "", g)
 	if err != nil {
 		return nil, err
 	}
@@ -202,7 +232,7 @@ func (g *Graph) openPage(title string, source pageSource) (Page, error) {
 		return nil, err
 	}
 
-	return openOrCreatePage(source, path, PageTypeDedicated, target, time.Time{}, "", g.markdownParseOptions()...)
+	return openOrCreatePage(source, path, PageTypeDedicated, target, time.Time{}, "", g)
 }
 
 // pageTitleForAlias finds the title of the page that has the given title as one
@@ -281,14 +311,14 @@ func (g *Graph) openViaPath(path string, source pageSource) (Page, error) {
 
 		title := g.journalTitleFormat.Format(date)
 
-		return openOrCreatePage(source, path, PageTypeJournal, title, date, "", g.markdownParseOptions()...)
+		return openOrCreatePage(source, path, PageTypeJournal, title, date, "", g)
 	} else if dir == filepath.Join(g.directory, g.config.PagesDir) {
 		title, err := utils.FilenameToTitle(g.config.FileNameFormat, name)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get title from filename: %w", err)
 		}
 
-		return openOrCreatePage(source, path, PageTypeDedicated, title, time.Time{}, "", g.markdownParseOptions()...)
+		return openOrCreatePage(source, path, PageTypeDedicated, title, time.Time{}, "", g)
 	}
 
 	return nil, nil
@@ -303,6 +333,13 @@ func (g *Graph) Close() error {
 	// closing the index out from under it.
 	g.changeHandlers.Wait()
 
+	if g.vectors != nil {
+		err := g.vectors.Close()
+		if err != nil {
+			return err
+		}
+	}
+
 	if g.index != nil {
 		return g.index.Close()
 	}
@@ -332,6 +369,13 @@ func (g *Graph) sync(ctx context.Context, listener func(event OpenEvent)) error
 		return fmt.Errorf("failed to sync pages: %w", err)
 	}
 
+	if g.vectors != nil {
+		err = g.vectors.Sync()
+		if err != nil {
+			return err
+		}
+	}
+
 	return g.index.Sync()
 }
 
@@ -367,7 +411,7 @@ func (g *Graph) createWalker(ctx context.Context, listener func(event OpenEvent)
 		lastModified, err := g.index.GetLastModified(ctx, subPath)
 		if err != nil {
 			return fmt.Errorf("failed to get last modified: %w", err)
-		} else if lastModified.Equal(info.ModTime()) {
+		} else if lastModified.Equal(info.ModTime()) && g.vectorsUpToDate(subPath, info) {
 			// Page is assumed to be up to date if times match
 			return nil
 		}
@@ -417,9 +461,35 @@ func (g *Graph) indexDocument(ctx context.Context, docPath string) (Page, error)
 		// modify the page.
 		doc.Properties = impl.findProperties()
 		doc.Aliases = impl.Aliases()
+		if doc.Properties != nil {
+			doc.Tags = doc.Properties.Strings("tags")
+		}
+	}
+
+	err = g.index.IndexPage(ctx, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if g.vectors != nil {
+		err = g.vectors.IndexPage(ctx, doc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return page, nil
+}
+
+// vectorsUpToDate checks if the vectors of a page are as recent as the file,
+// which they are not when an embedder is used for the first time with an
+// existing index.
+func (g *Graph) vectorsUpToDate(subPath string, info os.FileInfo) bool {
+	if g.vectors == nil {
+		return true
 	}
 
-	return page, g.index.IndexPage(ctx, doc)
+	return g.vectors.GetLastModified(subPath).Equal(info.ModTime())
 }
 
 func (g *Graph) watchForChanges() {
@@ -478,6 +548,10 @@ func (g *Graph) watchForChanges() {
 
 				path := event.Name
 
+				// The file has changed, so what was cached for it is out of
+				// date even if the modification time stays the same
+				g.pages.invalidate(path)
+
 				// Logseq will save as you write, so debounce changes to files
 				// so we don't index too often
 				mu.Lock()
@@ -549,6 +623,9 @@ func (g *Graph) watchForChanges() {
 				} else {
 					subPath, _ := filepath.Rel(g.directory, path)
 					err = g.index.DeletePage(ctx, subPath)
+					if err == nil && g.vectors != nil {
+						err = g.vectors.DeletePage(ctx, subPath)
+					}
 				}
 
 				if err != nil {
@@ -557,6 +634,9 @@ func (g *Graph) watchForChanges() {
 
 				// Sync after indexing so changes are visible
 				g.index.Sync()
+				if g.vectors != nil {
+					g.vectors.Sync()
+				}
 			} else if exists {
 				// No indexing, open the page directly
 				page, err = g.openViaPath(path, g)
@@ -638,8 +718,7 @@ func (g *Graph) searchPages(ctx context.Context, opts []SearchOption, source pag
 	}
 
 	options := &searchOptions{
-		size:   10,
-		sortBy: []indexing.SortField{},
+		size: 10,
 	}
 
 	for _, opt := range opts {
@@ -654,41 +733,56 @@ func (g *Graph) searchPages(ctx context.Context, opts []SearchOption, source pag
 		options.size = 10
 	}
 
-	results, err := g.index.SearchPages(ctx, options.query, indexing.SearchOptions{
-		Size:   options.size,
-		From:   options.from,
-		SortBy: options.sortBy,
-	})
+	indexOpts, err := options.indexOptions()
 	if err != nil {
 		return nil, err
 	}
 
-	return newSearchResults(results, func(page *indexing.Page) PageResult {
-		if page.Type == indexing.PageTypeJournal {
-			date := journalDate(page.Date)
-			return &pageResultImpl{
-				docType: PageTypeJournal,
-				title:   g.journalTitleFormat.Format(date),
-				date:    date,
+	results, err := g.index.SearchPages(ctx, options.query, indexOpts)
+	if err != nil {
+		return nil, err
+	}
 
-				opener: func() (Page, error) {
-					return source.OpenJournal(date)
-				},
-			}
-		} else {
-			return &pageResultImpl{
-				docType: PageTypeDedicated,
-				title:   page.Title,
-				date:    time.Time{},
-
-				opener: func() (Page, error) {
-					return source.OpenPage(page.Title)
-				},
-			}
-		}
+	return newSearchResults(results, options, func(page *indexing.Page) PageResult {
+		return g.pageResult(page, source)
 	}), nil
 }
 
+// pageResult turns a page found in the index into a result that opens it from
+// the given source.
+func (g *Graph) pageResult(page *indexing.Page, source pageSource) PageResult {
+	if page.Type == indexing.PageTypeJournal {
+		date := journalDate(page.Date)
+		return &pageResultImpl{
+			docType:      PageTypeJournal,
+			title:        g.journalTitleFormat.Format(date),
+			date:         date,
+			lastModified: page.LastModified,
+			aliases:      page.Aliases,
+			tags:         page.Tags,
+			properties:   page.PropertyValues,
+
+			opener: func() (Page, error) {
+				return source.OpenJournal(date)
+			},
+		}
+	}
+
+	return &pageResultImpl{
+		docType:      PageTypeDedicated,
+		title:        page.Title,
+		date:         time.Time{},
+		lastModified: page.LastModified,
+		aliases:      page.Aliases,
+		tags:         page.Tags,
+		properties:   page.PropertyValues,
+
+		opener: func() (Page, error) {
+			return source.OpenPage(page.Title)
+		},
+	}
+}
+
 // OpenBlock opens the block with the given id, which is the identifier that
 // block references such as `((id))` point at. The page the block belongs to is
 // returned as well, as the block is part of it.
@@ -735,8 +829,7 @@ func (g *Graph) searchBlocks(ctx context.Context, opts []SearchOption, source pa
 	}
 
 	options := &searchOptions{
-		size:   10,
-		sortBy: []indexing.SortField{},
+		size: 10,
 	}
 
 	for _, opt := range opts {
@@ -751,58 +844,128 @@ func (g *Graph) searchBlocks(ctx context.Context, opts []SearchOption, source pa
 		options.size = 10
 	}
 
-	results, err := g.index.SearchBlocks(ctx, options.query, indexing.SearchOptions{
-		Size:   options.size,
-		From:   options.from,
-		SortBy: options.sortBy,
-	})
+	indexOpts, err := options.indexOptions()
 	if err != nil {
 		return nil, err
 	}
 
-	return newSearchResults(results, func(block *indexing.Block) BlockResult {
-		dir := filepath.Dir(block.PageSubPath)
-		name := filepath.Base(block.PageSubPath)
-		name = name[:len(name)-3]
+	results, err := g.index.SearchBlocks(ctx, options.query, indexOpts)
+	if err != nil {
+		return nil, err
+	}
 
-		var err error
-		pageType := PageTypeDedicated
-		pageDate := time.Time{}
-		var pageTitle string
-		if dir == g.config.JournalsDir {
-			pageType = PageTypeJournal
+	return newSearchResults(results, options, func(block *indexing.Block) BlockResult {
+		return g.blockResult(block, source)
+	}), nil
+}
 
-			pageDate, err = g.journalNameFormat.Parse(name)
-			if err != nil {
-				// TODO: This is an edge case where the format of journals has changed since indexing
-			}
+// blockResult turns a block found in the index into a result that opens it
+// from the given source.
+func (g *Graph) blockResult(block *indexing.Block, source pageSource) BlockResult {
+	dir := filepath.Dir(block.PageSubPath)
+	name := filepath.Base(block.PageSubPath)
+	name = name[:len(name)-3]
 
-			pageTitle = g.journalTitleFormat.Format(pageDate)
-		} else {
-			pageTitle, err = utils.FilenameToTitle(g.config.FileNameFormat, name)
-			if err != nil {
-				// TODO: This page is not in the expected format
-			}
+	var err error
+	pageType := PageTypeDedicated
+	pageDate := time.Time{}
+	var pageTitle string
+	if dir == g.config.JournalsDir {
+		pageType = PageTypeJournal
+
+		pageDate, err = g.journalNameFormat.Parse(name)
+		if err != nil {
+			// TODO: This is an edge case where the format of journals has changed since indexing
 		}
 
-		return &blockResultImpl{
-			pageType:  pageType,
-			pageTitle: pageTitle,
-			pageDate:  pageDate,
+		pageTitle = g.journalTitleFormat.Format(pageDate)
+	} else {
+		pageTitle, err = utils.FilenameToTitle(g.config.FileNameFormat, name)
+		if err != nil {
+			// TODO: This page is not in the expected format
+		}
+	}
 
-			id:       block.ID,
-			preview:  block.Preview,
-			location: block.Location,
+	return &blockResultImpl{
+		pageType:  pageType,
+		pageTitle: pageTitle,
+		pageDate:  pageDate,
 
-			opener: func() (Page, error) {
-				if pageType == PageTypeJournal {
-					return source.OpenJournal(pageDate)
-				} else {
-					return source.OpenPage(pageTitle)
-				}
-			},
+		id:         block.ID,
+		preview:    block.Preview,
+		content:    block.Content,
+		properties: block.PropertyValues,
+		taskStatus: block.TaskStatus,
+		refs:       blockRefs(block),
+		breadcrumb: block.Breadcrumb,
+
+		pageSubPath: block.PageSubPath,
+		indexID:     block.IndexID,
+
+		opener: func() (Page, error) {
+			if pageType == PageTypeJournal {
+				return source.OpenJournal(pageDate)
+			} else {
+				return source.OpenPage(pageTitle)
+			}
+		},
+	}
+}
+
+// blockRefs returns the titles of the pages a block found in the index points
+// at. Pages are only included once, the first way they are written.
+func blockRefs(block *indexing.Block) []string {
+	refs := make([]string, 0, len(block.Edges))
+	seen := make(map[string]struct{}, len(block.Edges))
+	for _, edge := range block.Edges {
+		key := strings.ToLower(edge.To)
+		if _, ok := seen[key]; ok {
+			continue
 		}
-	}), nil
+
+		seen[key] = struct{}{}
+		refs = append(refs, edge.To)
+	}
+
+	return refs
+}
+
+// EachPage calls fn for every page that matches the query, until fn returns
+// false. A nil query matches every page. Unlike SearchPages the pages are read
+// from the index as they are needed, so going through a large number of pages
+// does not hold all of them in memory. Pages come in no particular order.
+//
+// The search stops with the error of the context if the context is canceled.
+// Going through pages requires the graph to have been opened with indexing
+// enabled.
+func (g *Graph) EachPage(ctx context.Context, query Query, fn func(PageResult) bool) error {
+	if g.index == nil {
+		return fmt.Errorf("indexing is not enabled")
+	}
+
+	if query == nil {
+		query = indexing.All()
+	}
+
+	return g.index.EachPage(ctx, query, func(page *indexing.Page) bool {
+		return fn(g.pageResult(page, g))
+	})
+}
+
+// EachBlock calls fn for every block that matches the query, until fn returns
+// false. A nil query matches every block. Works like EachPage, but for blocks.
+func (g *Graph) EachBlock(ctx context.Context, query Query, fn func(BlockResult) bool) error {
+	if g.index == nil {
+		return fmt.Errorf("indexing is not enabled")
+	}
+
+	if query == nil {
+		query = indexing.All()
+	}
+
+	return g.index.EachBlock(ctx, query, func(block *indexing.Block) bool {
+		return fn(g.blockResult(block, g))
+	})
 }
 
 func (g *Graph) Watch() *Watcher {
@@ -0,0 +1,139 @@
+package indexing
+
+import (
+	"unicode"
+
+	"github.com/blugelabs/bluge/analysis"
+	"github.com/blugelabs/bluge/analysis/analyzer"
+	"github.com/blugelabs/bluge/analysis/lang/cjk"
+	"github.com/blugelabs/bluge/analysis/lang/da"
+	"github.com/blugelabs/bluge/analysis/lang/de"
+	"github.com/blugelabs/bluge/analysis/lang/en"
+	"github.com/blugelabs/bluge/analysis/lang/es"
+	"github.com/blugelabs/bluge/analysis/lang/fi"
+	"github.com/blugelabs/bluge/analysis/lang/fr"
+	"github.com/blugelabs/bluge/analysis/lang/it"
+	"github.com/blugelabs/bluge/analysis/lang/nl"
+	"github.com/blugelabs/bluge/analysis/lang/no"
+	"github.com/blugelabs/bluge/analysis/lang/pt"
+	"github.com/blugelabs/bluge/analysis/lang/ru"
+	"github.com/blugelabs/bluge/analysis/lang/sv"
+	"golang.org/x/text/unicode/norm"
+)
+
+// TextField is a field of text that is split into terms by an analyzer.
+type TextField string
+
+const (
+	// TextFieldTitle is the title of pages.
+	TextFieldTitle TextField = "title"
+	// TextFieldContent is the content of pages and blocks, along with the text
+	// of their properties.
+	TextFieldContent TextField = "content"
+)
+
+// Analyzer splits text into the terms that are indexed and searched for.
+type Analyzer struct {
+	name     string
+	analyzer *analysis.Analyzer
+}
+
+// Name is the name the analyzer is recorded as in the index.
+func (a *Analyzer) Name() string {
+	return a.name
+}
+
+// WithASCIIFolding returns a copy of the analyzer that also removes
+// diacritics, so that `café` and `cafe` are the same term.
+func (a *Analyzer) WithASCIIFolding() *Analyzer {
+	filters := make([]analysis.TokenFilter, 0, len(a.analyzer.TokenFilters)+1)
+	filters = append(filters, a.analyzer.TokenFilters...)
+	filters = append(filters, asciiFoldingFilter{})
+
+	return &Analyzer{
+		name: a.name + "+ascii",
+		analyzer: &analysis.Analyzer{
+			CharFilters:  a.analyzer.CharFilters,
+			Tokenizer:    a.analyzer.Tokenizer,
+			TokenFilters: filters,
+		},
+	}
+}
+
+// StandardAnalyzer splits text into words and lower cases them. This is the
+// analyzer used unless another one is chosen.
+func StandardAnalyzer() *Analyzer {
+	return &Analyzer{
+		name:     "standard",
+		analyzer: analyzer.NewStandardAnalyzer(),
+	}
+}
+
+// CJKAnalyzer splits Chinese, Japanese and Korean text into overlapping pairs
+// of characters, as these languages do not separate words with spaces. Text
+// in other languages is split into words without stemming.
+func CJKAnalyzer() *Analyzer {
+	return &Analyzer{
+		name:     "cjk",
+		analyzer: cjk.Analyzer(),
+	}
+}
+
+// languageAnalyzers is the analyzers with stemming for a language, by the
+// ISO 639-1 code of the language.
+var languageAnalyzers = map[string]func() *analysis.Analyzer{
+	"da": da.Analyzer,
+	"de": de.Analyzer,
+	"en": en.NewAnalyzer,
+	"es": es.Analyzer,
+	"fi": fi.Analyzer,
+	"fr": fr.Analyzer,
+	"it": it.Analyzer,
+	"nl": nl.Analyzer,
+	"no": no.Analyzer,
+	"pt": pt.Analyzer,
+	"ru": ru.Analyzer,
+	"sv": sv.Analyzer,
+}
+
+// LanguageAnalyzer returns the analyzer for a language, given as its ISO
+// 639-1 code such as `de`. Languages with stemming get an analyzer that
+// reduces words to their stem and leaves out stop words, while `ja`, `zh` and
+// `ko` get the CJK analyzer. Returns nil if the language is not supported.
+func LanguageAnalyzer(language string) *Analyzer {
+	switch language {
+	case "ja", "zh", "ko":
+		return CJKAnalyzer()
+	}
+
+	create, ok := languageAnalyzers[language]
+	if !ok {
+		return nil
+	}
+
+	return &Analyzer{
+		name:     language,
+		analyzer: create(),
+	}
+}
+
+// asciiFoldingFilter removes diacritics from terms by decomposing characters
+// and dropping the combining marks.
+type asciiFoldingFilter struct{}
+
+func (asciiFoldingFilter) Filter(input analysis.TokenStream) analysis.TokenStream {
+	for _, token := range input {
+		decomposed := norm.NFD.Bytes(token.Term)
+
+		folded := make([]byte, 0, len(decomposed))
+		for _, r := range string(decomposed) {
+			if unicode.Is(unicode.Mn, r) {
+				continue
+			}
+			folded = append(folded, string(r)...)
+		}
+
+		token.Term = norm.NFC.Bytes(folded)
+	}
+	return input
+}
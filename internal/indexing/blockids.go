@@ -0,0 +1,136 @@
+package indexing
+
+import (
+	"hash/fnv"
+	"strconv"
+	"strings"
+
+	"github.com/aholstenson/logseq-go/content"
+	"github.com/blugelabs/bluge"
+)
+
+// blockKeys gives every block on a page an id in the index that stays the
+// same when other blocks are added, removed, changed or moved around it. The id
+// is the sub path of the page followed by `#` and the key of the block.
+//
+// Blocks with an `id::` property are keyed by it, while other blocks are keyed
+// by a hash of their own text, so a block keeps its id when it moves and when
+// the blocks above it change. Blocks that share their text with other blocks
+// on the page are told apart by the text of their parent and the order they
+// have below it, so that adding a block with the same text elsewhere on the
+// page does not renumber them.
+func blockKeys(subPath string, blocks content.BlockList) map[*content.Block]string {
+	own := make(map[*content.Block]string)
+	counts := make(map[string]int)
+
+	var collect func(blocks content.BlockList)
+	collect = func(blocks content.BlockList) {
+		for _, block := range blocks {
+			if storedBlockID(block) == "" {
+				key := textHash(plainText(block.Content()))
+				own[block] = key
+				counts[key]++
+			}
+			collect(block.Blocks())
+		}
+	}
+	collect(blocks)
+
+	keys := make(map[*content.Block]string)
+	seen := make(map[string]int)
+
+	var assign func(blocks content.BlockList, parent *content.Block)
+	assign = func(blocks content.BlockList, parent *content.Block) {
+		siblings := make(map[string]int)
+		for _, block := range blocks {
+			key := storedBlockID(block)
+			switch {
+			case key != "":
+				// Ids are unique within a graph, so blocks sharing one are
+				// numbered across the page
+				key = numberKey(seen, key)
+			case counts[own[block]] > 1:
+				parentText := ""
+				if parent != nil {
+					parentText = plainText(parent.Content())
+				}
+
+				key = numberKey(siblings, textHash(parentText+"\x00"+own[block]))
+				key = numberKey(seen, key)
+			default:
+				key = numberKey(seen, own[block])
+			}
+
+			keys[block] = subPath + "#" + key
+			assign(block.Blocks(), block)
+		}
+	}
+	assign(blocks, nil)
+
+	return keys
+}
+
+// numberKey adds the number of times a key has been seen to it, for all but
+// the first time it is seen.
+func numberKey(seen map[string]int, key string) string {
+	seen[key]++
+	if n := seen[key]; n > 1 {
+		return key + "-" + strconv.Itoa(n)
+	}
+
+	return key
+}
+
+// FindBlock finds the block of a page that has an id in the index, as found
+// in Block.IndexID, by working out the ids of the blocks the same way as when
+// the page was indexed. Returns nil if the page has no block with the id,
+// such as when the page has changed since it was indexed.
+func FindBlock(subPath string, blocks content.BlockList, indexID string) *content.Block {
+	for block, id := range blockKeys(subPath, blocks) {
+		if id == indexID {
+			return block
+		}
+	}
+
+	return nil
+}
+
+// storedBlockID returns the `id::` property of a block without creating the
+// properties of blocks that have none, as indexing should not modify blocks.
+func storedBlockID(block *content.Block) string {
+	if block.FindProperties() == nil {
+		return ""
+	}
+
+	return block.ID()
+}
+
+// pageOfBlockID returns the sub path of the page a block id belongs to.
+func pageOfBlockID(id string) string {
+	end := strings.Index(id, ".md#")
+	if end < 0 {
+		return ""
+	}
+
+	return id[:end+3]
+}
+
+// textHash returns a short hash of a text.
+func textHash(text string) string {
+	h := fnv.New64a()
+	h.Write([]byte(text))
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+// documentHash returns a hash of everything in a document, which tells if a
+// document has to be written again.
+func documentHash(doc *bluge.Document) string {
+	h := fnv.New64a()
+	for _, field := range *doc {
+		h.Write([]byte(field.Name()))
+		h.Write([]byte{0})
+		h.Write(field.Value())
+		h.Write([]byte{0})
+	}
+	return strconv.FormatUint(h.Sum64(), 16)
+}
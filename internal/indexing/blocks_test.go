@@ -0,0 +1,225 @@
+package indexing_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/aholstenson/logseq-go/content"
+	"github.com/aholstenson/logseq-go/internal/indexing"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Block indexing", func() {
+	var (
+		idx *indexing.BlugeIndex
+		ctx context.Context
+	)
+
+	BeforeEach(func() {
+		idx = createIndex()
+		ctx = context.Background()
+	})
+
+	AfterEach(func() {
+		Expect(idx.Close()).To(Succeed())
+	})
+
+	textBlock := func(text string) *content.Block {
+		return content.NewBlock(content.NewParagraph(content.NewText(text)))
+	}
+
+	idBlock := func(id string, text string) *content.Block {
+		return content.NewBlock(
+			content.NewParagraph(content.NewText(text)),
+			content.NewProperties(content.NewProperty("id", content.NewText(id))),
+		)
+	}
+
+	writePage := func(lastModified time.Time, blocks ...*content.Block) {
+		Expect(idx.IndexPage(ctx, &indexing.Page{
+			SubPath:      "pages/a.md",
+			Type:         indexing.PageTypeDedicated,
+			LastModified: lastModified,
+			Title:        "a",
+			Blocks:       blocks,
+		})).To(Succeed())
+	}
+
+	findBlock := func(text string) *indexing.Block {
+		results := searchBlocks(idx, indexing.ContentMatches(text))
+		Expect(results).To(HaveLen(1))
+		return results[0]
+	}
+
+	It("keeps the id of a block when blocks are added above it", func() {
+		writePage(time.Now(), textBlock("first"), textBlock("second"))
+		Expect(idx.Sync()).To(Succeed())
+		before := findBlock("second")
+
+		writePage(time.Now(), textBlock("zeroth"), textBlock("first"), textBlock("second"))
+		Expect(idx.Sync()).To(Succeed())
+		after := findBlock("second")
+
+		Expect(after.IndexID).To(Equal(before.IndexID))
+	})
+
+	It("only writes the added block when a block is added above others", func() {
+		first := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		parent := func() *content.Block {
+			block := textBlock("parent")
+			block.AddChild(textBlock("child"))
+			return block
+		}
+
+		writePage(first, textBlock("first"), parent())
+		Expect(idx.Sync()).To(Succeed())
+
+		writePage(first.Add(time.Hour), textBlock("zeroth"), textBlock("first"), parent())
+		Expect(idx.Sync()).To(Succeed())
+
+		results, err := idx.SearchBlocks(ctx, indexing.All(), indexing.SearchOptions{
+			SortBy: []indexing.SortField{{Field: "lastModified", Asc: true}},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results.Results()).To(HaveLen(4))
+		Expect(results.Results()[3].Content).To(Equal("zeroth"))
+	})
+
+	It("keeps the ids of the blocks below a block whose text changes", func() {
+		parent := func(text string) *content.Block {
+			block := textBlock(text)
+			child := textBlock("child")
+			child.AddChild(textBlock("grandchild"))
+			block.AddChild(child)
+			return block
+		}
+
+		writePage(time.Now(), parent("parent"))
+		Expect(idx.Sync()).To(Succeed())
+		child := findBlock("child")
+		grandchild := findBlock("grandchild")
+
+		writePage(time.Now(), parent("renamed"))
+		Expect(idx.Sync()).To(Succeed())
+		Expect(findBlock("child").IndexID).To(Equal(child.IndexID))
+		Expect(findBlock("grandchild").IndexID).To(Equal(grandchild.IndexID))
+		Expect(searchBlocks(idx, indexing.All())).To(HaveLen(3))
+	})
+
+	It("keeps the id of a block that moves to another parent", func() {
+		parent := func(text string, children ...*content.Block) *content.Block {
+			block := textBlock(text)
+			for _, child := range children {
+				block.AddChild(child)
+			}
+			return block
+		}
+
+		writePage(time.Now(), parent("alpha", textBlock("moving")), parent("beta"))
+		Expect(idx.Sync()).To(Succeed())
+		before := findBlock("moving")
+		Expect(before.Breadcrumb).To(Equal([]string{"alpha"}))
+
+		writePage(time.Now(), parent("alpha"), parent("beta", textBlock("moving")))
+		Expect(idx.Sync()).To(Succeed())
+		after := findBlock("moving")
+		Expect(after.IndexID).To(Equal(before.IndexID))
+		Expect(after.Breadcrumb).To(Equal([]string{"beta"}))
+		Expect(searchBlocks(idx, indexing.All())).To(HaveLen(3))
+	})
+
+	It("uses the id property of a block in its id", func() {
+		writePage(time.Now(), idBlock("6568d5b0-0e4b-4f2b-9bb4-0a4c2e8d3f10", "with id"))
+		Expect(idx.Sync()).To(Succeed())
+
+		Expect(findBlock("with id").IndexID).To(Equal("pages/a.md#6568d5b0-0e4b-4f2b-9bb4-0a4c2e8d3f10"))
+	})
+
+	It("gives blocks with the same text their own ids", func() {
+		writePage(time.Now(), textBlock("same"), textBlock("same"))
+		Expect(idx.Sync()).To(Succeed())
+
+		results := searchBlocks(idx, indexing.ContentMatches("same"))
+		Expect(results).To(HaveLen(2))
+		Expect(results[0].IndexID).ToNot(Equal(results[1].IndexID))
+	})
+
+	It("numbers blocks with the same text below the same parent", func() {
+		parent := func(text string, children ...string) *content.Block {
+			block := textBlock(text)
+			for _, child := range children {
+				block.AddChild(textBlock(child))
+			}
+			return block
+		}
+
+		// belowBeta finds the ids of the blocks below beta
+		belowBeta := func() []string {
+			var ids []string
+			for _, block := range searchBlocks(idx, indexing.ContentMatches("same")) {
+				if len(block.Breadcrumb) == 1 && block.Breadcrumb[0] == "beta" {
+					ids = append(ids, block.IndexID)
+				}
+			}
+			return ids
+		}
+
+		writePage(time.Now(), parent("alpha", "same"), parent("beta", "same"))
+		Expect(idx.Sync()).To(Succeed())
+		before := belowBeta()
+
+		// A block with the same text added below another parent does not
+		// change the id of the one below beta
+		writePage(time.Now(), parent("alpha", "same", "same"), parent("beta", "same"))
+		Expect(idx.Sync()).To(Succeed())
+		Expect(before).To(HaveLen(1))
+		Expect(belowBeta()).To(Equal(before))
+		Expect(searchBlocks(idx, indexing.ContentMatches("same"))).To(HaveLen(3))
+	})
+
+	It("only writes the blocks that changed", func() {
+		first := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		writePage(first, idBlock("1", "one"), idBlock("2", "two"), idBlock("3", "three"))
+		Expect(idx.Sync()).To(Succeed())
+
+		writePage(first.Add(time.Hour), idBlock("1", "changed"), idBlock("2", "two"), idBlock("3", "three"))
+		Expect(idx.Sync()).To(Succeed())
+
+		// Blocks that were not written keep the earlier time, which sorts
+		// them before the block that changed
+		results, err := idx.SearchBlocks(ctx, indexing.All(), indexing.SearchOptions{
+			SortBy: []indexing.SortField{{Field: "lastModified", Asc: true}},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		ids := make([]string, 0)
+		for _, block := range results.Results() {
+			ids = append(ids, block.IndexID)
+		}
+		Expect(ids).To(Equal([]string{"pages/a.md#2", "pages/a.md#3", "pages/a.md#1"}))
+	})
+
+	It("removes blocks that are no longer on the page", func() {
+		writePage(time.Now(), textBlock("kept"), textBlock("removed"))
+		Expect(idx.Sync()).To(Succeed())
+
+		writePage(time.Now(), textBlock("kept"))
+		Expect(idx.Sync()).To(Succeed())
+
+		Expect(searchBlocks(idx, indexing.ContentMatches("removed"))).To(BeEmpty())
+		Expect(searchBlocks(idx, indexing.All())).To(HaveLen(1))
+	})
+
+	It("compares with blocks written since the last sync", func() {
+		writePage(time.Now(), idBlock("1", "original"))
+		Expect(idx.Sync()).To(Succeed())
+
+		writePage(time.Now(), idBlock("1", "edited"))
+		writePage(time.Now(), idBlock("1", "original"))
+		Expect(idx.Sync()).To(Succeed())
+
+		Expect(findBlock("original").IndexID).To(Equal("pages/a.md#1"))
+		Expect(searchBlocks(idx, indexing.ContentMatches("edited"))).To(BeEmpty())
+	})
+})
@@ -3,16 +3,21 @@ package indexing
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/aholstenson/logseq-go/content"
 	"github.com/aholstenson/logseq-go/internal/utils"
 	"github.com/blugelabs/bluge"
+	"github.com/blugelabs/bluge/analysis/analyzer"
 	"github.com/blugelabs/bluge/index"
 	"github.com/blugelabs/bluge/search"
+	"github.com/blugelabs/bluge/search/aggregations"
 )
 
 type idTerm string
@@ -33,10 +38,95 @@ type BlugeIndex struct {
 	currentReader *bluge.Reader
 
 	currentBatch     *index.Batch
+	currentBatchIDs  map[string]struct{}
 	currentBatchSize int
+
+	// journalTitleFormat is used to read dates in properties that are written
+	// the way journals are titled.
+	journalTitleFormat *utils.DateFormat
+
+	// analyzers is the analyzer of every text field, used both when indexing
+	// and when searching.
+	analyzers map[TextField]*Analyzer
+
+	// pendingBlocks is the hash of every block written since the index was
+	// last synced, by id, with an empty hash for blocks that were removed. The
+	// reader does not see these writes until the index is synced, so they are
+	// looked up here instead.
+	pendingBlocks map[string]string
+}
+
+// IndexOption is an option for creating a Bluge index.
+type IndexOption func(*BlugeIndex)
+
+// WithAnalyzer sets the analyzer to use for a text field.
+func WithAnalyzer(field TextField, analyzer *Analyzer) IndexOption {
+	return func(i *BlugeIndex) {
+		i.analyzers[field] = analyzer
+	}
 }
 
-func NewBlugeIndex(graphConfig *utils.GraphConfig, indexDirectory string) (*BlugeIndex, error) {
+// metaID is the id of the document that records how the index was built.
+const metaID = "_meta"
+
+// indexVersion is increased when the fields that are indexed change, so that
+// indexes built by earlier versions are rebuilt.
+const indexVersion = 6
+
+func NewBlugeIndex(graphConfig *utils.GraphConfig, indexDirectory string, opts ...IndexOption) (*BlugeIndex, error) {
+	var journalTitleFormat *utils.DateFormat
+	if graphConfig != nil {
+		journalTitleFormat = utils.NewDateFormat(graphConfig.JournalPageTitleFormat)
+	}
+
+	i := &BlugeIndex{
+		journalTitleFormat: journalTitleFormat,
+		analyzers: map[TextField]*Analyzer{
+			TextFieldTitle:   StandardAnalyzer(),
+			TextFieldContent: StandardAnalyzer(),
+		},
+	}
+
+	for _, opt := range opts {
+		opt(i)
+	}
+
+	writer, err := openBlugeWriter(indexDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	if indexDirectory != "" {
+		settings, err := readSettings(writer)
+		if err != nil {
+			writer.Close()
+			return nil, err
+		}
+
+		if settings != i.settingsKey() {
+			// Terms analyzed in another way can not be matched, so the index
+			// is rebuilt from scratch when the analyzers change
+			err = clearIndex(writer)
+			if err != nil {
+				writer.Close()
+				return nil, err
+			}
+		}
+	}
+
+	meta := bluge.NewDocument(metaID).
+		AddField(bluge.NewStoredOnlyField("settings", []byte(i.settingsKey())))
+	err = writer.Update(meta.ID(), meta)
+	if err != nil {
+		writer.Close()
+		return nil, fmt.Errorf("error updating index: %w", err)
+	}
+
+	i.writer = writer
+	return i, nil
+}
+
+func openBlugeWriter(indexDirectory string) (*bluge.Writer, error) {
 	var config bluge.Config
 	if indexDirectory == "" {
 		config = bluge.InMemoryOnlyConfig()
@@ -49,9 +139,106 @@ func NewBlugeIndex(graphConfig *utils.GraphConfig, indexDirectory string) (*Blug
 		return nil, fmt.Errorf("error opening index writer: %w", err)
 	}
 
-	return &BlugeIndex{
-		writer: writer,
-	}, nil
+	return writer, nil
+}
+
+// clearIndex removes every document from an index. Only the documents are
+// removed, Bluge then drops the files it no longer needs by itself, so files
+// in the directory of the index that Bluge did not create are left alone.
+func clearIndex(writer *bluge.Writer) error {
+	reader, err := writer.Reader()
+	if err != nil {
+		return fmt.Errorf("error opening index reader: %w", err)
+	}
+	defer reader.Close()
+
+	it, err := reader.Search(context.Background(), bluge.NewAllMatches(bluge.NewMatchAllQuery()))
+	if err != nil {
+		return fmt.Errorf("error searching index: %w", err)
+	}
+
+	batch := bluge.NewBatch()
+	for {
+		match, err := it.Next()
+		if err != nil {
+			return fmt.Errorf("error getting next match: %w", err)
+		}
+
+		if match == nil {
+			break
+		}
+
+		err = match.VisitStoredFields(func(field string, value []byte) bool {
+			if field == "_id" {
+				batch.Delete(idTerm(string(value)))
+				return false
+			}
+
+			return true
+		})
+		if err != nil {
+			return fmt.Errorf("error reading document: %w", err)
+		}
+	}
+
+	err = writer.Batch(batch)
+	if err != nil {
+		return fmt.Errorf("error clearing index: %w", err)
+	}
+
+	return nil
+}
+
+// readSettings reads the settings an index was built with. Indexes from before
+// the settings were recorded were built with the standard analyzer and without
+// term positions.
+func readSettings(writer *bluge.Writer) (string, error) {
+	reader, err := writer.Reader()
+	if err != nil {
+		return "", fmt.Errorf("error opening index reader: %w", err)
+	}
+	defer reader.Close()
+
+	it, err := reader.Search(context.Background(), bluge.NewTopNSearch(1, bluge.NewTermQuery(metaID).SetField("_id")))
+	if err != nil {
+		return "", fmt.Errorf("error searching index: %w", err)
+	}
+
+	match, err := it.Next()
+	if err != nil {
+		return "", fmt.Errorf("error getting next match: %w", err)
+	}
+
+	settings := "title=standard,content=standard"
+	if match != nil {
+		match.VisitStoredFields(func(field string, value []byte) bool {
+			if field == "settings" {
+				settings = string(value)
+				return false
+			}
+
+			return true
+		})
+	}
+
+	return settings, nil
+}
+
+// settingsKey describes how the index is built, which is the version of its
+// fields and the analyzers of the text fields, so that a change of them can be
+// detected.
+func (i *BlugeIndex) settingsKey() string {
+	return "version=" + strconv.Itoa(indexVersion) +
+		",title=" + i.analyzers[TextFieldTitle].Name() +
+		",content=" + i.analyzers[TextFieldContent].Name()
+}
+
+// textField creates a field that is analyzed with the analyzer of a text
+// field. The positions of terms are kept, so that phrases can be matched.
+func (i *BlugeIndex) textField(field TextField, name string, value string) *bluge.TermField {
+	return bluge.NewTextField(name, value).
+		WithAnalyzer(i.analyzers[field].analyzer).
+		SearchTermPositions()
 }
 
 func (i *BlugeIndex) Close() error {
@@ -62,22 +249,24 @@ func (i *BlugeIndex) Sync() error {
 	i.mu.Lock()
 	defer i.mu.Unlock()
 
-	if i.currentBatch == nil {
+	if i.currentBatch == nil && i.pendingBlocks == nil {
 		return nil
 	}
 
-	// Apply the current batch
-	err := i.writer.Batch(i.currentBatch)
-	if err != nil {
-		return fmt.Errorf("error updating index: %w", err)
+	// Apply the current batch, which may already have been applied if it grew
+	// large enough
+	if i.currentBatch != nil {
+		err := i.applyBatch()
+		if err != nil {
+			return err
+		}
 	}
 
-	i.currentBatch = nil
-	i.currentBatchSize = 0
+	i.pendingBlocks = nil
 
 	if i.currentReader != nil {
 		// Close the current reader and allow it to be opened again
-		err = i.currentReader.Close()
+		err := i.currentReader.Close()
 		if err != nil {
 			return fmt.Errorf("error closing index reader: %w", err)
 		}
@@ -107,47 +296,69 @@ func (i *BlugeIndex) indexUpdate(doc *bluge.Document) error {
 	i.mu.Lock()
 	defer i.mu.Unlock()
 
-	if i.currentBatch == nil {
-		i.currentBatch = &index.Batch{}
+	batch, err := i.batchFor(string(doc.ID().Term()))
+	if err != nil {
+		return err
 	}
 
-	i.currentBatch.Update(doc.ID(), doc)
-	i.currentBatchSize++
+	batch.Update(doc.ID(), doc)
+	return i.batchAdded()
+}
 
-	if i.currentBatchSize >= 1000 {
-		err := i.writer.Batch(i.currentBatch)
-		if err != nil {
-			return fmt.Errorf("error updating index: %w", err)
-		}
+func (i *BlugeIndex) indexDelete(id string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
 
-		i.currentBatch = nil
-		i.currentBatchSize = 0
+	batch, err := i.batchFor(id)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	batch.Delete(idTerm(id))
+	return i.batchAdded()
 }
 
-func (i *BlugeIndex) indexDelete(id string) error {
-	i.mu.Lock()
-	defer i.mu.Unlock()
+// batchFor returns the batch to change a document in. A document changed
+// twice in the same batch is not replaced by the second change, so a batch that
+// already changes the document is applied first.
+func (i *BlugeIndex) batchFor(id string) (*index.Batch, error) {
+	if _, ok := i.currentBatchIDs[id]; ok {
+		err := i.applyBatch()
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	if i.currentBatch == nil {
 		i.currentBatch = &index.Batch{}
+		i.currentBatchIDs = make(map[string]struct{})
 	}
 
-	i.currentBatch.Delete(idTerm(id))
+	i.currentBatchIDs[id] = struct{}{}
+	return i.currentBatch, nil
+}
+
+// batchAdded counts a change added to the current batch, and applies the
+// batch once it has grown large.
+func (i *BlugeIndex) batchAdded() error {
 	i.currentBatchSize++
 
 	if i.currentBatchSize >= 1000 {
-		err := i.writer.Batch(i.currentBatch)
-		if err != nil {
-			return fmt.Errorf("error updating index: %w", err)
-		}
+		return i.applyBatch()
+	}
 
-		i.currentBatch = nil
-		i.currentBatchSize = 0
+	return nil
+}
+
+func (i *BlugeIndex) applyBatch() error {
+	err := i.writer.Batch(i.currentBatch)
+	if err != nil {
+		return fmt.Errorf("error updating index: %w", err)
 	}
 
+	i.currentBatch = nil
+	i.currentBatchIDs = nil
+	i.currentBatchSize = 0
 	return nil
 }
 
@@ -158,15 +369,15 @@ func (i *BlugeIndex) DeletePage(ctx context.Context, subPath string) error {
 	}
 
 	// Delete all of the blocks associated with the page
-	idSet, err := i.getBlocks(ctx, subPath)
+	hashes, err := i.getBlocks(ctx, subPath)
 	if err != nil {
 		return fmt.Errorf("error getting blocks: %w", err)
 	}
 
-	for id := range idSet {
-		err = i.indexDelete(id)
+	for id := range hashes {
+		err = i.deleteBlock(id)
 		if err != nil {
-			return fmt.Errorf("error updating index: %w", err)
+			return err
 		}
 	}
 
@@ -237,7 +448,7 @@ func (i *BlugeIndex) pageToDocument(doc *Page) (*bluge.Document, error) {
 	switch doc.Type {
 	case PageTypeDedicated:
 		blugeDoc.AddField(bluge.NewKeywordField("type", "page").StoreValue())
-		blugeDoc.AddField(bluge.NewTextField("title", doc.Title).StoreValue())
+		blugeDoc.AddField(i.textField(TextFieldTitle, "title", doc.Title).StoreValue())
 
 		// The namespaces of a page are indexed both as the one it is directly
 		// in and as all of them, so that the pages of a namespace can be found
@@ -255,12 +466,21 @@ func (i *BlugeIndex) pageToDocument(doc *Page) (*bluge.Document, error) {
 		blugeDoc.AddField(bluge.NewDateTimeField("date", doc.Date).StoreValue())
 	}
 
+	transferPageFields(blugeDoc, doc)
+
 	// Aliases are matched the same way references are, so that a page can be
 	// found via a title that only differs in case from the alias.
 	for _, alias := range doc.Aliases {
 		blugeDoc.AddField(bluge.NewKeywordField("alias:ref", normalizeRef(alias)))
+		blugeDoc.AddField(bluge.NewStoredOnlyField("alias", []byte(alias)))
 	}
 
+	for _, tag := range doc.Tags {
+		blugeDoc.AddField(bluge.NewStoredOnlyField("pageTag", []byte(tag)))
+	}
+
+	i.transferSuggestions(blugeDoc, doc)
+
 	if doc.Properties != nil {
 		i.transferProperties(blugeDoc, doc.Properties)
 		i.transferRefs(blugeDoc, "pages", doc.Properties)
@@ -296,39 +516,68 @@ func (i *BlugeIndex) pageToDocument(doc *Page) (*bluge.Document, error) {
 			fullText.WriteString("\n\n")
 		}
 
-		plainText0(block.Children(), &fullText)
+		fullText.WriteString(plainText(block.Children()))
 	}
-	blugeDoc.AddField(bluge.NewTextField("content", fullText.String()))
+	blugeDoc.AddField(i.textField(TextFieldContent, "content", fullText.String()))
 
 	return blugeDoc, nil
 }
 
+// transferSuggestions indexes the names a page can be typed as, which are its
+// title and its aliases. The title of a journal is included, as journals are
+// linked to by their title the same way as other pages.
+func (i *BlugeIndex) transferSuggestions(blugeDoc *bluge.Document, doc *Page) {
+	names := make([]string, 0, len(doc.Aliases)+1)
+	if doc.Title != "" {
+		names = append(names, doc.Title)
+	}
+	names = append(names, doc.Aliases...)
+
+	for _, name := range names {
+		normalized := normalizeRef(name)
+		blugeDoc.AddField(bluge.NewKeywordField("suggest", normalized))
+		blugeDoc.AddField(bluge.NewTextField("suggest:text", name))
+
+		// Every segment of a namespace starts a name of its own, so that
+		// `Child` is suggested for `Parent/Child`.
+		for idx, r := range normalized {
+			if r == '/' && idx+1 < len(normalized) {
+				blugeDoc.AddField(bluge.NewKeywordField("suggest:segment", normalized[idx+1:]))
+			}
+		}
+	}
+}
+
+// indexBlocks writes the documents of the blocks on a page. A hash of every
+// document is stored with it, so only the blocks that are new or have changed
+// are written. Blocks that are no longer on the page are removed.
 func (i *BlugeIndex) indexBlocks(ctx context.Context, page *Page) error {
-	idSet, err := i.getBlocks(ctx, page.SubPath)
+	hashes, err := i.getBlocks(ctx, page.SubPath)
 	if err != nil {
 		return fmt.Errorf("error getting blocks: %w", err)
 	}
 
-	// Index the new blocks
+	keys := blockKeys(page.SubPath, page.Blocks)
 	for _, block := range page.Blocks {
-		err = i.indexBlock(idSet, page, block)
+		err = i.indexBlock(hashes, keys, page, block)
 		if err != nil {
 			return err
 		}
 	}
 
 	// Remove any blocks that are no longer present
-	for id := range idSet {
-		err = i.indexDelete(id)
+	for id := range hashes {
+		err = i.deleteBlock(id)
 		if err != nil {
-			return fmt.Errorf("error updating index: %w", err)
+			return err
 		}
 	}
 
 	return nil
 }
 
-func (i *BlugeIndex) getBlocks(ctx context.Context, pagePath string) (map[string]struct{}, error) {
+// getBlocks returns the hash of every block of a page in the index, by id.
+func (i *BlugeIndex) getBlocks(ctx context.Context, pagePath string) (map[string]string, error) {
 	reader, err := i.reader()
 	if err != nil {
 		return nil, err
@@ -343,7 +592,7 @@ func (i *BlugeIndex) getBlocks(ctx context.Context, pagePath string) (map[string
 		return nil, fmt.Errorf("error searching index: %w", err)
 	}
 
-	idSet := make(map[string]struct{})
+	hashes := make(map[string]string)
 	for {
 		match, err := it.Next()
 		if err != nil {
@@ -354,36 +603,67 @@ func (i *BlugeIndex) getBlocks(ctx context.Context, pagePath string) (map[string
 			break
 		}
 
+		var id, hash string
 		match.VisitStoredFields(func(field string, value []byte) bool {
-			if field == "_id" {
-				idSet[string(value)] = struct{}{}
-				return false
+			switch field {
+			case "_id":
+				id = string(value)
+			case "hash":
+				hash = string(value)
 			}
 
 			return true
 		})
+
+		hashes[id] = hash
 	}
 
-	return idSet, nil
-}
+	// Blocks written since the reader was opened are not seen by it
+	i.mu.Lock()
+	defer i.mu.Unlock()
 
-func (i *BlugeIndex) indexBlock(idSet map[string]struct{}, page *Page, block *content.Block) error {
-	id := blockID(page, block)
+	for id, hash := range i.pendingBlocks {
+		if pageOfBlockID(id) != pagePath {
+			continue
+		}
 
-	blugeDoc, err := i.blockToDocument(page, id, block)
-	if err != nil {
-		return err
+		if hash == "" {
+			delete(hashes, id)
+		} else {
+			hashes[id] = hash
+		}
 	}
 
-	err = i.indexUpdate(blugeDoc)
+	return hashes, nil
+}
+
+func (i *BlugeIndex) indexBlock(hashes map[string]string, keys map[*content.Block]string, page *Page, block *content.Block) error {
+	blugeDoc, err := i.blockToDocument(page, keys, block)
 	if err != nil {
-		return fmt.Errorf("error updating index: %w", err)
+		return err
 	}
 
-	delete(idSet, id)
+	id := keys[block]
+	hash := documentHash(blugeDoc)
+	previous, indexed := hashes[id]
+	delete(hashes, id)
+
+	if !indexed || previous != hash {
+		// The time is added after hashing, so that a block that has not changed
+		// keeps the time of when it last did
+		blugeDoc.AddField(bluge.NewStoredOnlyField("hash", []byte(hash)))
+		blugeDoc.AddField(bluge.NewDateTimeField("lastModified", page.LastModified))
+
+		err = i.indexUpdate(blugeDoc)
+		if err != nil {
+			return fmt.Errorf("error updating index: %w", err)
+		}
+
+		i.setPendingBlock(id, hash)
+	}
 
 	for _, child := range block.Blocks() {
-		err = i.indexBlock(idSet, page, child)
+		err = i.indexBlock(hashes, keys, page, child)
 		if err != nil {
 			return err
 		}
@@ -392,60 +672,236 @@ func (i *BlugeIndex) indexBlock(idSet map[string]struct{}, page *Page, block *co
 	return nil
 }
 
-func (i *BlugeIndex) blockToDocument(page *Page, id string, block *content.Block) (*bluge.Document, error) {
-	blugeDoc := bluge.NewDocument(id).
+func (i *BlugeIndex) deleteBlock(id string) error {
+	err := i.indexDelete(id)
+	if err != nil {
+		return fmt.Errorf("error updating index: %w", err)
+	}
+
+	i.setPendingBlock(id, "")
+	return nil
+}
+
+func (i *BlugeIndex) setPendingBlock(id string, hash string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.pendingBlocks == nil {
+		i.pendingBlocks = make(map[string]string)
+	}
+	i.pendingBlocks[id] = hash
+}
+
+// blockToDocument creates the document of a block. The time the block was
+// last modified is left out, so that the document can be compared with the one
+// already in the index.
+func (i *BlugeIndex) blockToDocument(page *Page, keys map[*content.Block]string, block *content.Block) (*bluge.Document, error) {
+	blugeDoc := bluge.NewDocument(keys[block]).
 		AddField(bluge.NewKeywordField("type", "block").StoreValue()).
 		AddField(bluge.NewKeywordField("page", page.SubPath).StoreValue())
 
-	if id := block.ID(); id != "" {
+	// Blocks sort and group by the page they are on, as they have no title or
+	// date of their own.
+	transferPageFields(blugeDoc, page)
+	if page.Type == PageTypeJournal {
+		blugeDoc.AddField(bluge.NewDateTimeField("date", page.Date))
+	}
+
+	if id := storedBlockID(block); id != "" {
 		blugeDoc.AddField(bluge.NewKeywordField("id", id).StoreValue())
 	}
 
+	marker := block.Content().FindDeep(content.IsOfType[*content.TaskMarker]())
+	if marker != nil && marker.(*content.TaskMarker).Status != content.TaskStatusNone {
+		blugeDoc.AddField(bluge.NewKeywordField("task", marker.(*content.TaskMarker).Status.String()).Aggregatable().StoreValue())
+	}
+
+	if block.Logbook(false) != nil {
+		blugeDoc.AddField(bluge.NewKeywordField("logbook", "true"))
+	}
+
 	// Look up the properties without creating them, as indexing should not
 	// modify the block.
 	if props := block.FindProperties(); props != nil {
 		i.transferProperties(blugeDoc, props)
 	}
+	ancestors := blockAncestors(page, block)
+	for idx, ancestor := range ancestors {
+		ancestorID := keys[ancestor]
+		if idx == 0 {
+			blugeDoc.AddField(bluge.NewKeywordField("parent", ancestorID))
+		}
+
+		blugeDoc.AddField(bluge.NewKeywordField("ancestor", ancestorID))
+	}
+
+	// The breadcrumb goes from the top of the page down to the parent
+	for idx := len(ancestors) - 1; idx >= 0; idx-- {
+		breadcrumb := generatePreview(ancestors[idx].Content())
+		blugeDoc.AddField(bluge.NewStoredOnlyField("breadcrumb", []byte(breadcrumb)))
+	}
+
+	for _, edge := range blockEdges(block) {
+		blugeDoc.AddField(bluge.NewStoredOnlyField("edge", []byte(string(edge.Type)+":"+edge.To)))
+	}
+
+	for _, node := range block.Content().FilterDeep(isBlockReference) {
+		switch ref := node.(type) {
+		case *content.BlockRef:
+			blugeDoc.AddField(bluge.NewStoredOnlyField("blockref", []byte(ref.ID)))
+		case *content.BlockEmbed:
+			blugeDoc.AddField(bluge.NewStoredOnlyField("blockref", []byte(ref.ID)))
+		}
+	}
+
 	i.transferRefs(blugeDoc, "pages", block)
+	i.transferPathRefs(blugeDoc, page, block, ancestors)
 	i.transferLinks(blugeDoc, block)
 
-	var fullText strings.Builder
-	plainText0(block.Content(), &fullText)
-	blugeDoc.AddField(bluge.NewTextField("content", fullText.String()))
+	blugeDoc.AddField(i.textField(TextFieldContent, "content", plainText(block.Content())).StoreValue())
 
 	preview := generatePreview(block.Content())
-	blugeDoc.AddField(bluge.NewTextField("preview", preview).StoreValue())
+	blugeDoc.AddField(i.textField(TextFieldContent, "preview", preview).StoreValue())
 
 	return blugeDoc, nil
 }
 
-// blockID returns a semi-stable ID based on the location of the block on the
-// page.
-func blockID(page *Page, block *content.Block) string {
-	var path strings.Builder
-	path.WriteString(page.SubPath)
+// transferPathRefs indexes the references a block inherits, which Logseq calls
+// its path refs. A block counts as referencing the page it is on, the tags in
+// the `tags::` property of that page, and every page that it or one of its
+// parents reference. This is what makes a query for a page find the tasks
+// nested below a block that references it.
+func (i *BlugeIndex) transferPathRefs(blugeDoc *bluge.Document, page *Page, block *content.Block, ancestors []*content.Block) {
+	seen := make(map[string]struct{})
+	add := func(field string, title string) {
+		normalized := normalizeRef(title)
+		if _, ok := seen[field+normalized]; ok {
+			return
+		}
+
+		seen[field+normalized] = struct{}{}
+		blugeDoc.AddField(bluge.NewKeywordField(field, normalized))
+	}
+
+	if page.Title != "" {
+		add("path:ref", page.Title)
+	}
+
+	if page.Properties != nil {
+		for _, ref := range page.Properties.Get("tags").PageReferences() {
+			add("path:ref", ref.(content.PageRef).GetTo())
+			add("path:tag", ref.(content.PageRef).GetTo())
+		}
+	}
+
+	blocks := append([]*content.Block{block}, ancestors...)
+	for _, current := range blocks {
+		// Only the content of the block itself, as the blocks below it do not
+		// pass their references up
+		for _, ref := range current.Content().PageReferences() {
+			add("path:ref", ref.(content.PageRef).GetTo())
+
+			if hashtag, ok := ref.(*content.Hashtag); ok {
+				add("path:tag", hashtag.GetTo())
+			}
+		}
+	}
+}
+
+// blockEdges finds the pages that the content of a block points at. The
+// aliases of a page are left out, as they are other names for the page rather
+// than pages it points at.
+func blockEdges(block *content.Block) []Edge {
+	edges := make([]Edge, 0)
+	for _, node := range block.Content() {
+		if properties, ok := node.(*content.Properties); ok {
+			for _, child := range properties.Children() {
+				prop, ok := child.(*content.Property)
+				if !ok || prop.PageRefsIgnored || prop.Name == "alias" {
+					continue
+				}
+
+				edgeType := EdgeTypeProperty
+				if prop.Name == "tags" {
+					edgeType = EdgeTypeTag
+				}
 
-	current := block
-	for current != nil {
-		idx := 0
-		for sibling := current.PreviousSibling(); sibling != nil; sibling = sibling.PreviousSibling() {
-			if _, ok := sibling.(*content.Block); ok {
-				idx++
+				for _, ref := range prop.Children().PageReferences() {
+					edges = append(edges, Edge{Type: edgeType, To: ref.(content.PageRef).GetTo()})
+				}
 			}
+			continue
 		}
 
-		path.WriteRune(':')
-		path.WriteString(strconv.Itoa(idx))
+		refs := content.NodeList{node}.PageReferences()
+		for _, ref := range refs {
+			edgeType := EdgeTypeLink
+			switch ref.(type) {
+			case *content.Hashtag:
+				edgeType = EdgeTypeTag
+			case *content.PageEmbed:
+				edgeType = EdgeTypeEmbed
+			}
+
+			edges = append(edges, Edge{Type: edgeType, To: ref.(content.PageRef).GetTo()})
+		}
+	}
+
+	return edges
+}
 
-		// Move up the hierarchy
-		parent := current.Parent()
-		if parent == nil {
+func isBlockReference(node content.Node) bool {
+	switch node.(type) {
+	case *content.BlockRef, *content.BlockEmbed:
+		return true
+	default:
+		return false
+	}
+}
+
+// blockAncestors returns the blocks a block is nested below, starting with its
+// parent. The root block of a page holds the blocks of the page without being
+// one itself, so the ancestors stop at the blocks of the page.
+func blockAncestors(page *Page, block *content.Block) []*content.Block {
+	ancestors := make([]*content.Block, 0)
+	for current := block; !isPageBlock(page, current); {
+		parent, ok := current.Parent().(*content.Block)
+		if !ok {
 			break
 		}
-		current = parent.(*content.Block)
+
+		ancestors = append(ancestors, parent)
+		current = parent
+	}
+
+	return ancestors
+}
+
+func isPageBlock(page *Page, block *content.Block) bool {
+	for _, pageBlock := range page.Blocks {
+		if pageBlock == block {
+			return true
+		}
 	}
 
-	return path.String()
+	return false
+}
+
+// transferPageFields adds the fields of a page that are only used for sorting
+// and grouping, which both pages and the blocks on them have. Titles are
+// sorted without regard for case, the same way they are matched.
+func transferPageFields(blugeDoc *bluge.Document, page *Page) {
+	if page.Title != "" {
+		blugeDoc.AddField(bluge.NewKeywordField("title:sort", normalizeRef(page.Title)).Sortable())
+	}
+
+	switch page.Type {
+	case PageTypeDedicated:
+		blugeDoc.AddField(bluge.NewKeywordField("pageType", "page").Aggregatable())
+	case PageTypeJournal:
+		blugeDoc.AddField(bluge.NewKeywordField("pageType", "journal").Aggregatable())
+		blugeDoc.AddField(bluge.NewKeywordField("journalMonth", page.Date.Format("2006-01")).Aggregatable())
+	}
 }
 
 func (i *BlugeIndex) transferProperties(doc *bluge.Document, properties *content.Properties) {
@@ -462,18 +918,84 @@ func (i *BlugeIndex) transferProperties(doc *bluge.Document, properties *content
 			continue
 		}
 
-		doc.AddField(bluge.NewTextField("prop:"+prop.Name+":text", s))
-		doc.AddField(bluge.NewKeywordField("prop:"+prop.Name+":value", s))
+		doc.AddField(i.textField(TextFieldContent, "prop:"+prop.Name+":text", s))
+		doc.AddField(bluge.NewKeywordField("prop:"+prop.Name+":value", s).Aggregatable().Sortable())
+		doc.AddField(bluge.NewStoredOnlyField("property", []byte(prop.Name+":"+s)))
+
+		// Values that are numbers or dates are indexed as such as well, so
+		// that they can be matched by range and sorted in their natural order
+		if number, ok := propertyNumber(s); ok {
+			doc.AddField(bluge.NewNumericField("prop:"+prop.Name+":number", number))
+		}
+
+		if date, ok := i.propertyDate(prop, s); ok {
+			doc.AddField(bluge.NewDateTimeField("prop:"+prop.Name+":date", date))
+		}
+
+		// The pages in a `tags::` property are tags the same way `#tag` is,
+		// which has already been indexed as a tag with the references
+		if prop.Name == "tags" {
+			for _, ref := range prop.Children().PageReferences() {
+				if _, ok := ref.(*content.Hashtag); ok {
+					continue
+				}
+
+				doc.AddField(bluge.NewKeywordField("tags", normalizeRef(ref.(content.PageRef).GetTo())).Aggregatable())
+			}
+		}
 	}
 }
 
+// propertyNumber reads the value of a property as a number, in the same way as
+// content.Property.Float. Booleans are numbers as well, so that they can be
+// sorted and matched by range.
+func propertyNumber(value string) (float64, bool) {
+	switch value {
+	case "true":
+		return 1, true
+	case "false":
+		return 0, true
+	}
+
+	return content.ParseNumber(value)
+}
+
+// propertyDate reads the value of a property as a date. Dates are either
+// written as ISO 8601 dates, or in the way the graph titles its journals, in
+// which case they are usually a link to the journal.
+func (i *BlugeIndex) propertyDate(prop *content.Property, value string) (time.Time, bool) {
+	candidates := []string{value}
+	for _, ref := range prop.Children().PageReferences() {
+		candidates = append(candidates, ref.(content.PageRef).GetTo())
+	}
+
+	for _, candidate := range candidates {
+		if date, err := time.ParseInLocation("2006-01-02", candidate, time.Local); err == nil {
+			return date, true
+		}
+
+		if date, err := time.Parse(time.RFC3339, candidate); err == nil {
+			return date, true
+		}
+
+		if i.journalTitleFormat != nil {
+			if date, err := i.journalTitleFormat.Parse(candidate); err == nil {
+				return date, true
+			}
+		}
+	}
+
+	return time.Time{}, false
+}
+
 func (i *BlugeIndex) transferRefs(doc *bluge.Document, field string, root content.HasChildren) {
 	refs := root.Children().PageReferences()
 	for _, ref := range refs {
-		doc.AddField(bluge.NewKeywordField(field+":ref", normalizeRef(ref.(content.PageRef).GetTo())))
+		doc.AddField(bluge.NewKeywordField(field+":ref", normalizeRef(ref.(content.PageRef).GetTo())).Aggregatable())
 
 		if hashtag, ok := ref.(*content.Hashtag); ok {
 			doc.AddField(bluge.NewKeywordField(field+":tag", normalizeRef(hashtag.GetTo())))
+			doc.AddField(bluge.NewKeywordField("tags", normalizeRef(hashtag.GetTo())).Aggregatable())
 		}
 	}
 }
@@ -499,21 +1021,17 @@ func (i *BlugeIndex) SearchPages(ctx context.Context, q Query, opts SearchOption
 		opts.Size = 10
 	}
 
-	mappedQuery := mapQuery(q)
-
 	reader, err := i.reader()
 	if err != nil {
 		return nil, err
 	}
 
-	queryWithOnlyDocs := bluge.NewBooleanQuery().
-		AddMust(bluge.NewBooleanQuery().
-			AddShould(bluge.NewTermQuery("page").SetField("type")).
-			AddShould(bluge.NewTermQuery("journal").SetField("type")),
-		).
-		AddMust(mappedQuery)
+	resolved, err := i.resolveQuery(ctx, reader, q)
+	if err != nil {
+		return nil, err
+	}
 
-	req := bluge.NewTopNSearch(opts.Size, queryWithOnlyDocs).
+	req := bluge.NewTopNSearch(opts.Size, onlyPages(i.mapQuery(resolved))).
 		WithStandardAggregations().
 		SetFrom(opts.From)
 
@@ -532,18 +1050,17 @@ func (i *BlugeIndex) SearchBlocks(ctx context.Context, q Query, opts SearchOptio
 		opts.Size = 10
 	}
 
-	mappedQuery := mapQuery(q)
-
 	reader, err := i.reader()
 	if err != nil {
 		return nil, err
 	}
 
-	queryWithOnlyBlocks := bluge.NewBooleanQuery().
-		AddMust(bluge.NewTermQuery("block").SetField("type")).
-		AddMust(mappedQuery)
+	resolved, err := i.resolveQuery(ctx, reader, q)
+	if err != nil {
+		return nil, err
+	}
 
-	req := bluge.NewTopNSearch(opts.Size, queryWithOnlyBlocks).
+	req := bluge.NewTopNSearch(opts.Size, onlyBlocks(i.mapQuery(resolved))).
 		WithStandardAggregations().
 		SetFrom(opts.From)
 
@@ -557,20 +1074,175 @@ func (i *BlugeIndex) SearchBlocks(ctx context.Context, q Query, opts SearchOptio
 	return newBlugeSearchResults(ctx, it, mapMatchToBlock)
 }
 
-func (*BlugeIndex) transferSortBy(opts SearchOptions, req *bluge.TopNSearch) {
-	if len(opts.SortBy) > 0 {
-		var sortOrder search.SortOrder
+func (i *BlugeIndex) EachPage(ctx context.Context, q Query, each func(*Page) bool) error {
+	return eachMatch(ctx, i, q, onlyPages, mapMatchToPage, each)
+}
+
+func (i *BlugeIndex) EachBlock(ctx context.Context, q Query, each func(*Block) bool) error {
+	return eachMatch(ctx, i, q, onlyBlocks, mapMatchToBlock, each)
+}
+
+func (i *BlugeIndex) AggregatePages(ctx context.Context, q Query, facets []*Facet) (*Aggregation, error) {
+	return i.aggregate(ctx, q, onlyPages, facets)
+}
 
-		for _, sortField := range opts.SortBy {
-			sortBy := search.SortBy(search.Field(sortField.Field))
-			if !sortField.Asc {
-				sortBy.Desc()
+func (i *BlugeIndex) AggregateBlocks(ctx context.Context, q Query, facets []*Facet) (*Aggregation, error) {
+	return i.aggregate(ctx, q, onlyBlocks, facets)
+}
+
+func (i *BlugeIndex) aggregate(ctx context.Context, q Query, scope func(bluge.Query) bluge.Query, facets []*Facet) (*Aggregation, error) {
+	reader, err := i.reader()
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, err := i.resolveQuery(ctx, reader, q)
+	if err != nil {
+		return nil, err
+	}
+
+	req := bluge.NewAllMatches(scope(i.mapQuery(resolved)))
+	req.AddAggregation("count", aggregations.CountMatches())
+	for _, facet := range facets {
+		req.AddAggregation(facet.name, aggregations.NewTermsAggregation(search.Field(facet.field), facet.size))
+	}
+
+	it, err := reader.Search(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("error searching index: %w", err)
+	}
+
+	// The aggregations are calculated as the matches are gone through
+	for {
+		match, err := it.Next()
+		if err != nil {
+			return nil, fmt.Errorf("error getting next match: %w", err)
+		}
+
+		if match == nil {
+			break
+		}
+	}
+
+	result := &Aggregation{
+		Count:   int(it.Aggregations().Count()),
+		buckets: make(map[string][]Bucket, len(facets)),
+	}
+
+	for _, facet := range facets {
+		buckets := make([]Bucket, 0)
+		for _, bucket := range it.Aggregations().Buckets(facet.name) {
+			buckets = append(buckets, Bucket{
+				Term:  bucket.Name(),
+				Count: int(bucket.Count()),
+			})
+		}
+
+		// Values with the same count are ordered by the value, so that the
+		// order is the same every time
+		sort.SliceStable(buckets, func(a, b int) bool {
+			if buckets[a].Count != buckets[b].Count {
+				return buckets[a].Count > buckets[b].Count
 			}
 
-			sortOrder = append(sortOrder, sortBy)
+			return buckets[a].Term < buckets[b].Term
+		})
+
+		result.buckets[facet.name] = buckets
+	}
+
+	return result, nil
+}
+
+// eachMatch streams the matches of a query without sorting or collecting them.
+// The iteration gets a reader of its own, so that changes to the index made
+// while going through the matches, such as by each itself, do not close the
+// reader from under it.
+func eachMatch[V any](ctx context.Context, i *BlugeIndex, q Query, scope func(bluge.Query) bluge.Query, mapper func(*search.DocumentMatch) V, each func(V) bool) error {
+	reader, err := i.writer.Reader()
+	if err != nil {
+		return fmt.Errorf("error opening index reader: %w", err)
+	}
+	defer reader.Close()
+
+	resolved, err := i.resolveQuery(ctx, reader, q)
+	if err != nil {
+		return err
+	}
+
+	it, err := reader.Search(ctx, bluge.NewAllMatches(scope(i.mapQuery(resolved))))
+	if err != nil {
+		return fmt.Errorf("error searching index: %w", err)
+	}
+
+	for {
+		// The iterator only checks the context every so often, so it is
+		// checked here to stop as soon as possible
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		match, err := it.Next()
+		if err != nil {
+			return fmt.Errorf("error getting next match: %w", err)
+		}
+
+		if match == nil {
+			return nil
 		}
 
-		req.SortByCustom(sortOrder)
+		if !each(mapper(match)) {
+			return nil
+		}
+	}
+}
+
+// onlyPages limits a query to matching pages and journals.
+func onlyPages(query bluge.Query) bluge.Query {
+	return bluge.NewBooleanQuery().
+		AddMust(bluge.NewBooleanQuery().
+			AddShould(bluge.NewTermQuery("page").SetField("type")).
+			AddShould(bluge.NewTermQuery("journal").SetField("type")),
+		).
+		AddMust(query)
+}
+
+// onlyBlocks limits a query to matching blocks.
+func onlyBlocks(query bluge.Query) bluge.Query {
+	return bluge.NewBooleanQuery().
+		AddMust(bluge.NewTermQuery("block").SetField("type")).
+		AddMust(query)
+}
+
+func (*BlugeIndex) transferSortBy(opts SearchOptions, req *bluge.TopNSearch) {
+	sortFields := opts.SortBy
+	if len(sortFields) == 0 {
+		sortFields = []SortField{{Field: "_score"}}
+	}
+
+	var sortOrder search.SortOrder
+	for _, sortField := range sortFields {
+		var source search.TextValueSource = search.Field(sortField.Field)
+		if sortField.Field == "_score" {
+			source = search.DocumentScore()
+		}
+
+		sortBy := search.SortBy(source)
+		if !sortField.Asc {
+			sortBy.Desc()
+		}
+
+		sortOrder = append(sortOrder, sortBy)
+	}
+
+	// Ties are broken by the id, so results keep their order between searches
+	// and a search can be continued after any of them.
+	sortOrder = append(sortOrder, search.SortBy(search.Field("_id")))
+
+	req.SortByCustom(sortOrder)
+
+	if opts.After != nil {
+		req.After(opts.After)
 	}
 }
 
@@ -598,64 +1270,93 @@ func generatePreview(nodes content.NodeList) string {
 	return ""
 }
 
+// plainText returns the text of nodes as it is indexed, which leaves out
+// properties as they are indexed separately.
 func plainText(nodes content.NodeList) string {
-	var builder strings.Builder
-	plainText0(nodes, &builder)
-	return strings.TrimSpace(builder.String())
+	return nodes.PlainText(content.WithoutProperties(), content.WithoutLogbooks())
 }
 
-func plainText0(nodes content.NodeList, builder *strings.Builder) {
-	for _, node := range nodes {
-		switch n := node.(type) {
-		case *content.Text:
-			builder.WriteString(n.Value)
-			if n.SoftLineBreak || n.HardLineBreak {
-				builder.WriteRune('\n')
-			}
-		case *content.RawText:
-			builder.WriteString(n.Value)
-		case *content.Hashtag:
-			builder.WriteString("#")
-			builder.WriteString(n.To)
-		case *content.PageLink:
-			builder.WriteString(n.To)
-		case *content.PageRefText:
-			builder.WriteString(n.To)
-		case *content.CodeSpan:
-			builder.WriteString(n.Value)
-		case *content.CodeBlock:
-			if builder.Len() > 0 {
-				builder.WriteString("\n\n")
-			}
+// resolveQuery replaces the queries that match blocks by the blocks around
+// them, such as HasAncestorMatching, with queries for the ids of those blocks.
+// The blocks are looked up first, as the index has no way to join documents.
+func (i *BlugeIndex) resolveQuery(ctx context.Context, reader *bluge.Reader, q Query) (Query, error) {
+	switch query := q.(type) {
+	case *and:
+		clauses, err := i.resolveQueries(ctx, reader, query.clauses)
+		if err != nil {
+			return nil, err
+		}
+		return &and{clauses: clauses}, nil
+	case *or:
+		clauses, err := i.resolveQueries(ctx, reader, query.clauses)
+		if err != nil {
+			return nil, err
+		}
+		return &or{clauses: clauses}, nil
+	case *not:
+		clause, err := i.resolveQuery(ctx, reader, query.clause)
+		if err != nil {
+			return nil, err
+		}
+		return &not{clause: clause}, nil
+	case *hierarchy:
+		ancestorQuery, err := i.resolveQuery(ctx, reader, query.ancestor)
+		if err != nil {
+			return nil, err
+		}
 
-			builder.WriteString(n.Code)
-		case *content.Math:
-			builder.WriteString(n.Value)
-		case *content.MathBlock:
-			if builder.Len() > 0 {
-				builder.WriteString("\n\n")
+		it, err := reader.Search(ctx, bluge.NewAllMatches(onlyBlocks(i.mapQuery(ancestorQuery))))
+		if err != nil {
+			return nil, fmt.Errorf("error searching index: %w", err)
+		}
+
+		field := "ancestor"
+		if query.direct {
+			field = "parent"
+		}
+
+		ids := &idsIn{field: field}
+		for {
+			match, err := it.Next()
+			if err != nil {
+				return nil, fmt.Errorf("error getting next match: %w", err)
 			}
 
-			builder.WriteString(n.Value)
-		case *content.TableRow:
-			plainText0(n.Children(), builder)
-			builder.WriteRune('\n')
-		case *content.TableCell:
-			plainText0(n.Children(), builder)
-			builder.WriteRune(' ')
-		case *content.Properties:
-			// Skip properties
-		case content.HasChildren:
-			if _, blockNode := n.(content.BlockNode); blockNode && builder.Len() > 0 {
-				builder.WriteString("\n\n")
+			if match == nil {
+				break
 			}
 
-			plainText0(n.Children(), builder)
+			match.VisitStoredFields(func(field string, value []byte) bool {
+				if field == "_id" {
+					ids.ids = append(ids.ids, string(value))
+					return false
+				}
+
+				return true
+			})
+		}
+
+		return ids, nil
+	default:
+		return q, nil
+	}
+}
+
+func (i *BlugeIndex) resolveQueries(ctx context.Context, reader *bluge.Reader, queries []Query) ([]Query, error) {
+	resolved := make([]Query, len(queries))
+	for idx, q := range queries {
+		r, err := i.resolveQuery(ctx, reader, q)
+		if err != nil {
+			return nil, err
 		}
+
+		resolved[idx] = r
 	}
+
+	return resolved, nil
 }
 
-func mapQuery(q Query) bluge.Query {
+func (i *BlugeIndex) mapQuery(q Query) bluge.Query {
 	switch query := q.(type) {
 	case *all:
 		return bluge.NewMatchAllQuery()
@@ -664,23 +1365,34 @@ func mapQuery(q Query) bluge.Query {
 	case *and:
 		bq := bluge.NewBooleanQuery()
 		for _, sub := range query.clauses {
-			bq = bq.AddMust(mapQuery(sub))
+			bq = bq.AddMust(i.mapQuery(sub))
 		}
 		return bq
 	case *or:
 		bq := bluge.NewBooleanQuery()
 		for _, sub := range query.clauses {
-			bq = bq.AddShould(mapQuery(sub))
+			bq = bq.AddShould(i.mapQuery(sub))
 		}
 		return bq
 	case *not:
-		return bluge.NewBooleanQuery().AddMustNot(mapQuery(query.clause))
+		return bluge.NewBooleanQuery().AddMustNot(i.mapQuery(query.clause))
 	case *fieldMatches:
+		// Text is analyzed the same way as the field was when indexed, or
+		// terms such as stems would not match
+		analyzer := i.analyzers[TextFieldContent].analyzer
+		if query.field == "title" {
+			analyzer = i.analyzers[TextFieldTitle].analyzer
+		}
+
 		if strings.HasPrefix(query.field, "prop:") {
-			return bluge.NewMatchQuery(query.text).SetField(query.field + ":text")
+			return bluge.NewMatchQuery(query.text).SetField(query.field + ":text").SetAnalyzer(analyzer)
+		}
+
+		if query.phrase {
+			return bluge.NewMatchPhraseQuery(query.text).SetField(query.field).SetAnalyzer(analyzer)
 		}
 
-		mq := bluge.NewMatchQuery(query.text).SetField(query.field)
+		mq := bluge.NewMatchQuery(query.text).SetField(query.field).SetAnalyzer(analyzer)
 		if !query.partial {
 			mq = mq.SetOperator(bluge.MatchQueryOperatorAnd)
 		}
@@ -702,20 +1414,117 @@ func mapQuery(q Query) bluge.Query {
 		}
 
 		return bluge.NewTermQuery(normalizeRef(query.target)).SetField(query.field + ":ref")
+	case *numberRange:
+		return bluge.NewNumericRangeInclusiveQuery(query.min, query.max, query.minInclusive, query.maxInclusive).
+			SetField(query.field + ":number")
+	case *dateRange:
+		return bluge.NewDateRangeInclusiveQuery(query.start, query.end, true, true).
+			SetField(query.field + ":date")
+	case *suggests:
+		if query.start {
+			return mapStartsWith(query.text)
+		}
+		return mapSuggests(query.text)
+	case *hasField:
+		return bluge.NewWildcardQuery("*").SetField(query.field)
+	case *idsIn:
+		if len(query.ids) == 0 {
+			return bluge.NewMatchNoneQuery()
+		}
+
+		bq := bluge.NewBooleanQuery()
+		for _, id := range query.ids {
+			bq.AddShould(bluge.NewTermQuery(id).SetField(query.field))
+		}
+		return bq
 	default:
 		return bluge.NewMatchNoneQuery()
 	}
 }
 
+// mapStartsWith builds the query for TitleStartsWith, which matches the names
+// of pages by their start.
+func mapStartsWith(text string) bluge.Query {
+	normalized := normalizeRef(strings.TrimSpace(text))
+	if normalized == "" {
+		return bluge.NewMatchNoneQuery()
+	}
+
+	return bluge.NewPrefixQuery(normalized).SetField("suggest")
+}
+
+// mapSuggests builds the query for TitleSuggests. The ways a name can match
+// are scored from the start of the whole name down to a word that is only
+// close to what was typed, so the most likely pages come first.
+func mapSuggests(text string) bluge.Query {
+	normalized := normalizeRef(strings.TrimSpace(text))
+	if normalized == "" {
+		return bluge.NewMatchNoneQuery()
+	}
+
+	q := bluge.NewBooleanQuery().
+		AddShould(bluge.NewPrefixQuery(normalized).SetField("suggest").SetBoost(8)).
+		AddShould(bluge.NewPrefixQuery(normalized).SetField("suggest:segment").SetBoost(4))
+
+	if strings.Contains(normalized, "/") {
+		// Each segment that was typed can be the start of a segment, so that
+		// `par/ch` finds `Parent/Child`.
+		segments := strings.Split(normalized, "/")
+		for idx, segment := range segments {
+			segments[idx] = regexp.QuoteMeta(segment)
+		}
+
+		pattern := strings.Join(segments, "[^/]*/") + ".*"
+		q.AddShould(bluge.NewRegexpQuery(pattern).SetField("suggest").SetBoost(4))
+		q.AddShould(bluge.NewRegexpQuery(pattern).SetField("suggest:segment").SetBoost(2))
+	}
+
+	tokens := analyzer.NewStandardAnalyzer().Analyze([]byte(text))
+	if len(tokens) > 0 {
+		words := bluge.NewBooleanQuery()
+		for _, token := range tokens {
+			term := string(token.Term)
+
+			word := bluge.NewBooleanQuery().
+				AddShould(bluge.NewPrefixQuery(term).SetField("suggest:text").SetBoost(2))
+			if fuzziness := suggestFuzziness(term); fuzziness > 0 {
+				word.AddShould(bluge.NewFuzzyQuery(term).SetField("suggest:text").SetFuzziness(fuzziness))
+			}
+
+			words.AddMust(word)
+		}
+
+		q.AddShould(words)
+	}
+
+	return q
+}
+
+// suggestFuzziness is the number of typos allowed in a word that is being
+// completed. Short words are left without, as almost any other short word is
+// a typo or two away from them.
+func suggestFuzziness(term string) int {
+	switch n := utf8.RuneCountInString(term); {
+	case n < 3:
+		return 0
+	case n < 6:
+		return 1
+	default:
+		return 2
+	}
+}
+
 type blugeSearchResults[D any] struct {
 	mu sync.Mutex
 
-	count   int
-	results []D
+	count      int
+	results    []D
+	sortValues [][][]byte
 }
 
 func newBlugeSearchResults[V any](ctx context.Context, it search.DocumentMatchIterator, mapper func(*search.DocumentMatch) V) (*blugeSearchResults[V], error) {
 	results := make([]V, 0)
+	sortValues := make([][][]byte, 0)
 	for {
 		match, err := it.Next()
 		if err != nil {
@@ -728,11 +1537,13 @@ func newBlugeSearchResults[V any](ctx context.Context, it search.DocumentMatchIt
 
 		doc := mapper(match)
 		results = append(results, doc)
+		sortValues = append(sortValues, match.SortValue)
 	}
 
 	return &blugeSearchResults[V]{
-		count:   int(it.Aggregations().Count()),
-		results: results,
+		count:      int(it.Aggregations().Count()),
+		results:    results,
+		sortValues: sortValues,
 	}, nil
 }
 
@@ -748,6 +1559,10 @@ func (r *blugeSearchResults[V]) Results() []V {
 	return r.results
 }
 
+func (r *blugeSearchResults[V]) SortValues() [][][]byte {
+	return r.sortValues
+}
+
 var _ SearchResults[*Page] = &blugeSearchResults[*Page]{}
 
 func mapMatchToPage(match *search.DocumentMatch) *Page {
@@ -773,6 +1588,14 @@ func mapMatchToPage(match *search.DocumentMatch) *Page {
 			}
 		case "title":
 			page.Title = string(value)
+		case "alias":
+			page.Aliases = append(page.Aliases, string(value))
+		case "pageTag":
+			page.Tags = append(page.Tags, string(value))
+		case "property":
+			page.PropertyValues = appendPropertyValue(page.PropertyValues, value)
+		case "preview":
+			page.Preview = string(value)
 		case "date":
 			t, err := bluge.DecodeDateTime(value)
 			if err != nil {
@@ -794,35 +1617,26 @@ func mapMatchToBlock(match *search.DocumentMatch) *Block {
 	match.VisitStoredFields(func(field string, value []byte) bool {
 		switch field {
 		case "_id":
-			// The ID of the block is the sub path of the page with the location in reverse
-			// order appended to it.
-			location := make([]int, 0)
-			for _, part := range strings.Split(string(value), ":") {
-				idx, err := strconv.Atoi(part)
-				if err != nil {
-					continue
-				}
-
-				location = append(location, idx)
-			}
-
-			// Reverse the location
-			for i, j := 0, len(location)-1; i < j; i, j = i+1, j-1 {
-				location[i], location[j] = location[j], location[i]
-			}
-
-			// Remove the first element which is the root block index
-			if len(location) > 0 {
-				location = location[1:]
-			}
-
-			block.Location = location
+			block.IndexID = string(value)
 		case "page":
 			block.PageSubPath = string(value)
 		case "id":
 			block.ID = string(value)
 		case "preview":
 			block.Preview = string(value)
+		case "content":
+			block.Content = string(value)
+		case "task":
+			block.TaskStatus = taskStatus(string(value))
+		case "property":
+			block.PropertyValues = appendPropertyValue(block.PropertyValues, value)
+		case "breadcrumb":
+			block.Breadcrumb = append(block.Breadcrumb, string(value))
+		case "edge":
+			edgeType, to, _ := strings.Cut(string(value), ":")
+			block.Edges = append(block.Edges, Edge{Type: EdgeType(edgeType), To: to})
+		case "blockref":
+			block.BlockRefs = append(block.BlockRefs, string(value))
 		}
 
 		return true
@@ -830,3 +1644,26 @@ func mapMatchToBlock(match *search.DocumentMatch) *Block {
 
 	return block
 }
+
+// appendPropertyValue adds a stored property, written as its name and value
+// separated by a colon, to the values of a page or block.
+func appendPropertyValue(values map[string]string, stored []byte) map[string]string {
+	if values == nil {
+		values = make(map[string]string)
+	}
+
+	name, value, _ := strings.Cut(string(stored), ":")
+	values[name] = value
+	return values
+}
+
+// taskStatus reads a task status from the marker it is written as.
+func taskStatus(marker string) content.TaskStatus {
+	for status := content.TaskStatusTodo; status <= content.TaskStatusWaiting; status++ {
+		if status.String() == marker {
+			return status
+		}
+	}
+
+	return content.TaskStatusNone
+}
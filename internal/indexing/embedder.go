@@ -0,0 +1,96 @@
+package indexing
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// hashingStopWords are words too common to say anything about what a text is
+// about.
+var hashingStopWords = map[string]struct{}{
+	"a": {}, "an": {}, "and": {}, "are": {}, "as": {}, "at": {}, "be": {},
+	"by": {}, "for": {}, "from": {}, "has": {}, "in": {}, "is": {}, "it": {},
+	"of": {}, "on": {}, "or": {}, "that": {}, "the": {}, "this": {}, "to": {},
+	"was": {}, "with": {},
+}
+
+// HashingEmbedder is an embedder that needs no model, for use offline and in
+// tests. Words and the character trigrams of words are hashed into the
+// dimensions of the vector, weighted by how often they occur. The trigrams let
+// different forms of a word, such as `meeting` and `meetings`, end up close to
+// each other.
+type HashingEmbedder struct {
+	dimensions int
+}
+
+// NewHashingEmbedder creates a hashing embedder that creates vectors with the
+// given number of dimensions. More dimensions means fewer words share a
+// dimension, 256 to 1024 works well for notes.
+func NewHashingEmbedder(dimensions int) *HashingEmbedder {
+	if dimensions <= 0 {
+		dimensions = 512
+	}
+
+	return &HashingEmbedder{
+		dimensions: dimensions,
+	}
+}
+
+// Name returns the name of the embedder, which is the same whatever the number
+// of dimensions.
+func (e *HashingEmbedder) Name() string {
+	return "hashing"
+}
+
+func (e *HashingEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for idx, text := range texts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		vectors[idx] = e.embed(text)
+	}
+	return vectors, nil
+}
+
+func (e *HashingEmbedder) embed(text string) []float32 {
+	counts := make(map[string]float64)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	for _, word := range words {
+		if _, ok := hashingStopWords[word]; ok {
+			continue
+		}
+
+		counts["w:"+word]++
+
+		runes := []rune("^" + word + "$")
+		for start := 0; start+3 <= len(runes); start++ {
+			counts["t:"+string(runes[start:start+3])] += 0.5
+		}
+	}
+
+	vector := make([]float32, e.dimensions)
+	for feature, count := range counts {
+		hash := fnv.New64a()
+		hash.Write([]byte(feature))
+		sum := hash.Sum64()
+
+		// Words repeated many times should not drown out everything else, so
+		// the weight grows with the logarithm of the count
+		weight := 1 + math.Log(1+count)
+		if sum&(1<<63) != 0 {
+			weight = -weight
+		}
+
+		vector[sum%uint64(e.dimensions)] += float32(weight)
+	}
+
+	return vector
+}
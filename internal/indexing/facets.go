@@ -0,0 +1,86 @@
+package indexing
+
+// Facet is a field that the results of a query can be grouped by, with the
+// number of results counted for every value of the field.
+type Facet struct {
+	name  string
+	field string
+	size  int
+}
+
+// Name is the name the buckets of this facet are found under.
+func (f *Facet) Name() string {
+	return f.name
+}
+
+// WithSize returns a copy of the facet that returns at most size buckets.
+func (f *Facet) WithSize(size int) *Facet {
+	c := *f
+	c.size = size
+	return &c
+}
+
+func newFacet(name string, field string) *Facet {
+	return &Facet{
+		name:  name,
+		field: field,
+		size:  10,
+	}
+}
+
+// FacetReferences groups results by the pages they reference.
+func FacetReferences() *Facet {
+	return newFacet("references", "pages:ref")
+}
+
+// FacetTags groups results by their tags, both the ones written as `#tag` and
+// the ones in a `tags::` property.
+func FacetTags() *Facet {
+	return newFacet("tags", "tags")
+}
+
+// FacetPropertyValues groups results by the values of a property.
+func FacetPropertyValues(property string) *Facet {
+	return newFacet("property:"+property, "prop:"+property+":value")
+}
+
+// FacetPageType groups results by the type of page they are or are on, which
+// is either `page` or `journal`.
+func FacetPageType() *Facet {
+	return newFacet("pageType", "pageType")
+}
+
+// FacetJournalMonth groups journals and the blocks in them by the month of the
+// journal, in the form `2006-01`.
+func FacetJournalMonth() *Facet {
+	return newFacet("journalMonth", "journalMonth")
+}
+
+// FacetTaskStatus groups blocks that are tasks by their status, such as `TODO`
+// or `DONE`.
+func FacetTaskStatus() *Facet {
+	return newFacet("taskStatus", "task")
+}
+
+// Aggregation is the result of grouping the results of a query by facets.
+type Aggregation struct {
+	// Count is the number of results that matched the query.
+	Count int
+
+	buckets map[string][]Bucket
+}
+
+// Buckets returns the values of a facet and how many results have them, with
+// the most common values first. Values that refer to pages, such as
+// references and tags, are in lower case as Logseq does not distinguish
+// between titles that only differ in case.
+func (a *Aggregation) Buckets(facet *Facet) []Bucket {
+	return a.buckets[facet.name]
+}
+
+// Bucket is a value of a facet together with the number of results that have
+// it.
+type Bucket struct {
+	Term  string
+	Count int
+}
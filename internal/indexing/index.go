@@ -30,6 +30,22 @@ type Index interface {
 
 	// SearchBlocks searches for blocks in the index.
 	SearchBlocks(ctx context.Context, query Query, opts SearchOptions) (SearchResults[*Block], error)
+
+	// EachPage calls each for every page that matches the query, in no
+	// particular order, until each returns false. Pages are read from the
+	// index as they are needed, so every page can be gone through without
+	// holding all of them in memory.
+	EachPage(ctx context.Context, query Query, each func(*Page) bool) error
+
+	// EachBlock calls each for every block that matches the query, in no
+	// particular order, until each returns false.
+	EachBlock(ctx context.Context, query Query, each func(*Block) bool) error
+
+	// AggregatePages groups the pages that match the query by the facets.
+	AggregatePages(ctx context.Context, query Query, facets []*Facet) (*Aggregation, error)
+
+	// AggregateBlocks groups the blocks that match the query by the facets.
+	AggregateBlocks(ctx context.Context, query Query, facets []*Facet) (*Aggregation, error)
 }
 
 type SearchOptions struct {
@@ -39,10 +55,18 @@ type SearchOptions struct {
 	// From is the offset to start returning results from.
 	From int
 
-	// SortBy is the sort order for the results.
+	// SortBy is the sort order for the results. Results are sorted by score
+	// if no order is given. Results that sort the same are ordered by their
+	// id, so that the order is the same every time.
 	SortBy []SortField
+
+	// After continues a search after the result with this sort value, as
+	// given by SearchResults.SortValues. From is ignored when this is set.
+	After [][]byte
 }
 
+// SortField is a field to sort results by. The special field `_score` sorts
+// by how well a result matches.
 type SortField struct {
 	Field string
 	Asc   bool
@@ -58,6 +82,10 @@ type SearchResults[V any] interface {
 
 	// Results is a slice of all the results in this result set.
 	Results() []V
+
+	// SortValues is the sort value of every result, in the same order as
+	// Results. Used to continue a search after a result.
+	SortValues() [][][]byte
 }
 
 type PageType int
@@ -77,20 +105,29 @@ type Page struct {
 	// LastModified is the last time the page was modified on disk.
 	LastModified time.Time
 
-	// Title is the title of the page. Only used for dedicated pages.
+	// Title is the title of the page. Journals only have a title while
+	// indexing, where it lets them be suggested by it.
 	Title string
 	// Date is the date of the journal. Only used for journals.
 	Date time.Time
 
-	// Preview string of the page, only used when searching.
+	// Preview string of the page, only used when searching. Pages that render
+	// as nothing have an empty preview.
 	Preview string
 
 	// Blocks is the blocks of the page, only used while indexing.
 	Blocks content.BlockList
 
-	// Aliases is the alternative titles of the page, only used while indexing.
+	// Aliases is the alternative titles of the page.
 	Aliases []string
 
+	// Tags is the pages in the `tags::` property of the page.
+	Tags []string
+
+	// PropertyValues is the text of every property of the page, by name. Only
+	// used when searching, nil if the page has no properties.
+	PropertyValues map[string]string
+
 	// Properties is the properties of the page, nil if it has none. Only used
 	// while indexing.
 	Properties *content.Properties
@@ -103,9 +140,61 @@ type Block struct {
 	// ID is the stable id of the block if it has one.
 	ID string
 
-	// Location is the location of the block in the page.
-	Location []int
+	// IndexID is the id of the block in the index. It is the sub path of the
+	// page followed by the `id::` of the block, or a hash of its text if it has
+	// none, so it stays the same when blocks are added or moved around it. Use
+	// FindBlock to find the block on its page.
+	IndexID string
 
 	// Preview is a preview of the block.
 	Preview string
+
+	// Content is the text of the block without any formatting, only used when
+	// searching.
+	Content string
+
+	// TaskStatus is the status of the block if it is a task.
+	TaskStatus content.TaskStatus
+
+	// PropertyValues is the text of every property of the block, by name. Only
+	// used when searching, nil if the block has no properties.
+	PropertyValues map[string]string
+
+	// Breadcrumb is the previews of the blocks this block is nested below,
+	// starting at the top of the page.
+	Breadcrumb []string
+
+	// Edges is the pages this block points at and how it points at them. Only
+	// the content of the block itself is included, not the blocks below it.
+	Edges []Edge
+
+	// BlockRefs is the ids of the blocks this block references or embeds.
+	BlockRefs []string
+}
+
+// EdgeType is the way a block points at a page.
+type EdgeType string
+
+const (
+	// EdgeTypeLink is a link such as `[[Page]]`.
+	EdgeTypeLink EdgeType = "link"
+	// EdgeTypeTag is a tag, either as `#Page` or in a `tags::` property.
+	EdgeTypeTag EdgeType = "tag"
+	// EdgeTypeProperty is a reference to a page in the value of a property.
+	EdgeTypeProperty EdgeType = "property"
+	// EdgeTypeEmbed is an embed such as `{{embed [[Page]]}}`.
+	EdgeTypeEmbed EdgeType = "embed"
+	// EdgeTypeAlias points from a page to one of the aliases in its `alias::`
+	// property. Blocks do not have these edges, they are only in link graphs.
+	EdgeTypeAlias EdgeType = "alias"
+	// EdgeTypeNamespace points from a page to the namespace it is directly in,
+	// so `Parent/Child` points at `Parent`. Blocks do not have these edges,
+	// they are only in link graphs.
+	EdgeTypeNamespace EdgeType = "namespace"
+)
+
+// Edge is a page that a block points at.
+type Edge struct {
+	Type EdgeType
+	To   string
 }
@@ -1,7 +1,15 @@
 package indexing
 
+import (
+	"math"
+	"time"
+)
+
 type Query interface {
 	isQuery()
+
+	// String writes the query in the syntax read by ParseQuery.
+	String() string
 }
 
 type all struct{}
@@ -34,6 +42,8 @@ type fieldMatches struct {
 	field   string
 	text    string
 	partial bool
+	// phrase marks queries that match the words of the text in order.
+	phrase bool
 }
 
 func (f *fieldMatches) isQuery() {}
@@ -56,6 +66,55 @@ type fieldRefs struct {
 
 func (f *fieldRefs) isQuery() {}
 
+type numberRange struct {
+	field        string
+	min          float64
+	max          float64
+	minInclusive bool
+	maxInclusive bool
+}
+
+func (n *numberRange) isQuery() {}
+
+type dateRange struct {
+	field string
+	start time.Time
+	end   time.Time
+}
+
+func (d *dateRange) isQuery() {}
+
+type hierarchy struct {
+	ancestor Query
+	direct   bool
+}
+
+func (h *hierarchy) isQuery() {}
+
+// idsIn is what a hierarchy query is resolved into before searching, as it
+// depends on which blocks its ancestor query matches.
+type idsIn struct {
+	field string
+	ids   []string
+}
+
+func (i *idsIn) isQuery() {}
+
+type suggests struct {
+	text string
+	// start marks queries that only match names that start with the text.
+	start bool
+}
+
+func (s *suggests) isQuery() {}
+
+// hasField matches the documents that have any value for a field.
+type hasField struct {
+	field string
+}
+
+func (h *hasField) isQuery() {}
+
 func All() *all {
 	return &all{}
 }
@@ -97,6 +156,25 @@ func TitlePartiallyMatches(text string) Query {
 	}
 }
 
+// TitleSuggests matches the pages that could be what is being typed when the
+// given text is the start of a page title. The title and the aliases of a page
+// are matched by their start, by the start of a segment of their namespace and
+// by their words, allowing for a few typos in longer words.
+func TitleSuggests(text string) Query {
+	return &suggests{
+		text: text,
+	}
+}
+
+// TitleStartsWith matches the pages whose title or one of whose aliases starts
+// with the given text, which are the best of the pages TitleSuggests matches.
+func TitleStartsWith(text string) Query {
+	return &suggests{
+		text:  text,
+		start: true,
+	}
+}
+
 func BlockIDEquals(id string) Query {
 	return &fieldEquals{
 		field: "id",
@@ -104,6 +182,53 @@ func BlockIDEquals(id string) Query {
 	}
 }
 
+// IndexIDIn matches the blocks with one of the given ids in the index, as
+// found in Block.IndexID.
+func IndexIDIn(ids ...string) Query {
+	return &idsIn{
+		field: "_id",
+		ids:   ids,
+	}
+}
+
+// IsTask matches the blocks that are tasks, whatever their status.
+func IsTask() Query {
+	return &hasField{
+		field: "task",
+	}
+}
+
+// HasLogbook matches the blocks that have a logbook, such as the tasks that
+// time has been clocked on.
+func HasLogbook() Query {
+	return &hasField{
+		field: "logbook",
+	}
+}
+
+// ChildOf matches the blocks directly below the block with the given id.
+func ChildOf(id string) Query {
+	return &hierarchy{
+		ancestor: BlockIDEquals(id),
+		direct:   true,
+	}
+}
+
+// DescendantOf matches the blocks anywhere below the block with the given id.
+func DescendantOf(id string) Query {
+	return &hierarchy{
+		ancestor: BlockIDEquals(id),
+	}
+}
+
+// HasAncestorMatching matches the blocks that are anywhere below a block that
+// matches the query.
+func HasAncestorMatching(query Query) Query {
+	return &hierarchy{
+		ancestor: query,
+	}
+}
+
 func ContentMatches(text string) Query {
 	return &fieldMatches{
 		field: "content",
@@ -111,6 +236,16 @@ func ContentMatches(text string) Query {
 	}
 }
 
+// ContentMatchesPhrase matches content that has the words of the text next
+// to each other, in the same order.
+func ContentMatchesPhrase(text string) Query {
+	return &fieldMatches{
+		field:  "content",
+		text:   text,
+		phrase: true,
+	}
+}
+
 func PropertyMatches(property string, text string) Query {
 	return &fieldMatches{
 		field: "prop:" + property,
@@ -125,6 +260,49 @@ func PropertyEquals(property string, value string) Query {
 	}
 }
 
+// PropertyBetween matches properties with a number as their value that is
+// between min and max, including min and max themselves. Properties that are
+// `true` or `false` have the value 1 and 0.
+func PropertyBetween(property string, min float64, max float64) Query {
+	return &numberRange{
+		field:        "prop:" + property,
+		min:          min,
+		max:          max,
+		minInclusive: true,
+		maxInclusive: true,
+	}
+}
+
+// PropertyGreaterThan matches properties with a number as their value that is
+// greater than the given value.
+func PropertyGreaterThan(property string, value float64) Query {
+	return &numberRange{
+		field: "prop:" + property,
+		min:   value,
+		max:   math.Inf(1),
+	}
+}
+
+// PropertyLessThan matches properties with a number as their value that is
+// less than the given value.
+func PropertyLessThan(property string, value float64) Query {
+	return &numberRange{
+		field: "prop:" + property,
+		min:   math.Inf(-1),
+		max:   value,
+	}
+}
+
+// PropertyDateBetween matches properties with a date as their value that is
+// between start and end, including start and end themselves.
+func PropertyDateBetween(property string, start time.Time, end time.Time) Query {
+	return &dateRange{
+		field: "prop:" + property,
+		start: start,
+		end:   end,
+	}
+}
+
 func PropertyReferences(property string, target string) Query {
 	return &fieldRefs{
 		field:  "prop:" + property,
@@ -178,6 +356,25 @@ func ReferencesTag(tag string) Query {
 	}
 }
 
+// ReferencesInPath matches blocks that reference a page either themselves or
+// via one of their parents, the page they are on or the tags of that page.
+func ReferencesInPath(page string) Query {
+	return &fieldRefs{
+		field:  "path",
+		target: page,
+	}
+}
+
+// ReferencesTagInPath matches blocks that have a tag either themselves or via
+// one of their parents or the `tags::` property of the page they are on.
+func ReferencesTagInPath(tag string) Query {
+	return &fieldRefs{
+		field:  "path",
+		target: tag,
+		tag:    true,
+	}
+}
+
 func LinksToURL(url string) Query {
 	return &fieldEquals{
 		field: "link",
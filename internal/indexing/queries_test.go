@@ -286,6 +286,84 @@ var _ = Describe("Queries", func() {
 		})
 	})
 
+	Describe("PropertyBetween", func() {
+		indexRating := func(subPath string, title string, rating string) {
+			indexPage(idx, subPath, title,
+				content.NewBlock(
+					content.NewProperties(
+						content.NewProperty("rating", content.NewText(rating)),
+					),
+					content.NewParagraph(content.NewText("content")),
+				),
+			)
+		}
+
+		It("matches pages with a number in the range", func() {
+			indexRating("pages/a.md", "Page A", "2")
+			indexRating("pages/b.md", "Page B", "4")
+			indexRating("pages/c.md", "Page C", "4.5")
+			indexRating("pages/d.md", "Page D", "great")
+
+			results := searchPages(idx, indexing.PropertyBetween("rating", 3, 4.5))
+			Expect(results).To(HaveLen(2))
+
+			results = searchPages(idx, indexing.PropertyGreaterThan("rating", 4))
+			Expect(results).To(HaveLen(1))
+			Expect(results[0].Title).To(Equal("Page C"))
+
+			results = searchPages(idx, indexing.PropertyLessThan("rating", 4))
+			Expect(results).To(HaveLen(1))
+			Expect(results[0].Title).To(Equal("Page A"))
+		})
+
+		It("does not read values that are not plain decimal numbers", func() {
+			indexRating("pages/a.md", "Page A", "3")
+			indexRating("pages/b.md", "Page B", "NaN")
+			indexRating("pages/c.md", "Page C", "Inf")
+			indexRating("pages/d.md", "Page D", "0x1p-2")
+			indexRating("pages/e.md", "Page E", "infinity")
+
+			results := searchPages(idx, indexing.PropertyGreaterThan("rating", -1000))
+			Expect(results).To(HaveLen(1))
+			Expect(results[0].Title).To(Equal("Page A"))
+		})
+
+		It("matches booleans as 1 and 0", func() {
+			indexRating("pages/a.md", "Page A", "true")
+			indexRating("pages/b.md", "Page B", "false")
+
+			results := searchPages(idx, indexing.PropertyBetween("rating", 1, 1))
+			Expect(results).To(HaveLen(1))
+			Expect(results[0].Title).To(Equal("Page A"))
+		})
+	})
+
+	Describe("PropertyDateBetween", func() {
+		It("matches pages with a date in the range", func() {
+			indexPage(idx, "pages/a.md", "Page A",
+				content.NewBlock(
+					content.NewProperties(
+						content.NewProperty("due", content.NewText("2024-05-01")),
+					),
+				),
+			)
+			indexPage(idx, "pages/b.md", "Page B",
+				content.NewBlock(
+					content.NewProperties(
+						content.NewProperty("due", content.NewText("2024-06-15")),
+					),
+				),
+			)
+
+			results := searchPages(idx, indexing.PropertyDateBetween("due",
+				time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local),
+				time.Date(2024, 5, 31, 0, 0, 0, 0, time.Local),
+			))
+			Expect(results).To(HaveLen(1))
+			Expect(results[0].Title).To(Equal("Page A"))
+		})
+	})
+
 	Describe("References", func() {
 		It("matches pages that reference another page", func() {
 			indexPage(idx, "pages/a.md", "Page A",
@@ -355,6 +433,129 @@ var _ = Describe("Queries", func() {
 		})
 	})
 
+	Describe("ReferencesInPath", func() {
+		It("matches blocks below a block that references the page", func() {
+			indexPage(idx, "pages/a.md", "Page A",
+				content.NewBlock(
+					content.NewParagraph(content.NewPageLink("Project")),
+					content.NewBlock(
+						content.NewParagraph(content.NewText("child")),
+						content.NewBlock(content.NewParagraph(content.NewText("grandchild"))),
+					),
+				),
+				content.NewBlock(content.NewParagraph(content.NewText("sibling"))),
+			)
+
+			results := searchBlocks(idx, indexing.ReferencesInPath("project"))
+			Expect(results).To(HaveLen(3))
+
+			results = searchBlocks(idx, indexing.And(
+				indexing.ReferencesInPath("Project"),
+				indexing.ContentMatches("grandchild"),
+			))
+			Expect(results).To(HaveLen(1))
+		})
+
+		It("matches blocks on a page with the page as a tag", func() {
+			indexPage(idx, "pages/a.md", "Page A",
+				content.NewBlock(
+					content.NewProperties(
+						content.NewProperty("tags", content.NewPageLink("Project")),
+					),
+				),
+				content.NewBlock(content.NewParagraph(content.NewText("content"))),
+			)
+
+			results := searchBlocks(idx, indexing.And(
+				indexing.ReferencesInPath("Project"),
+				indexing.ContentMatches("content"),
+			))
+			Expect(results).To(HaveLen(1))
+
+			results = searchBlocks(idx, indexing.And(
+				indexing.ReferencesTagInPath("Project"),
+				indexing.ContentMatches("content"),
+			))
+			Expect(results).To(HaveLen(1))
+		})
+
+		It("matches blocks on the page itself", func() {
+			indexPage(idx, "pages/project.md", "Project",
+				content.NewBlock(content.NewParagraph(content.NewText("content"))),
+			)
+
+			results := searchBlocks(idx, indexing.ReferencesInPath("Project"))
+			Expect(results).To(HaveLen(1))
+		})
+
+		It("does not match the parents of a block that references the page", func() {
+			indexPage(idx, "pages/a.md", "Page A",
+				content.NewBlock(
+					content.NewParagraph(content.NewText("parent")),
+					content.NewBlock(content.NewParagraph(content.NewPageLink("Project"))),
+				),
+			)
+
+			results := searchBlocks(idx, indexing.And(
+				indexing.ReferencesInPath("Project"),
+				indexing.ContentMatches("parent"),
+			))
+			Expect(results).To(BeEmpty())
+		})
+	})
+
+	Describe("HasAncestorMatching", func() {
+		It("matches blocks nested below a matching block", func() {
+			indexPage(idx, "pages/a.md", "Page A",
+				content.NewBlock(
+					content.NewParagraph(content.NewText("parent")),
+					content.NewBlock(
+						content.NewParagraph(content.NewText("child")),
+						content.NewBlock(content.NewParagraph(content.NewText("grandchild"))),
+					),
+					content.NewBlock(content.NewParagraph(content.NewText("sibling"))),
+				),
+			)
+
+			results := searchBlocks(idx, indexing.HasAncestorMatching(indexing.ContentMatches("parent")))
+			Expect(results).To(HaveLen(3))
+
+			results = searchBlocks(idx, indexing.HasAncestorMatching(indexing.ContentMatches("child")))
+			Expect(results).To(HaveLen(1))
+			Expect(results[0].Preview).To(Equal("grandchild"))
+
+			results = searchBlocks(idx, indexing.HasAncestorMatching(indexing.ContentMatches("sibling")))
+			Expect(results).To(BeEmpty())
+		})
+	})
+
+	Describe("IsTask and HasLogbook", func() {
+		It("match the blocks that are tasks or have a logbook", func() {
+			indexPage(idx, "pages/a.md", "Page A",
+				content.NewBlock(content.NewParagraph(
+					content.NewTaskMarker(content.TaskStatusTodo),
+					content.NewText("task"),
+				)),
+				content.NewBlock(
+					content.NewParagraph(content.NewText("clocked")),
+					content.NewLogbook(content.NewLogbookEntryClock(time.Now().Add(-time.Hour), time.Now())),
+				),
+				content.NewBlock(content.NewParagraph(content.NewText("plain"))),
+			)
+
+			results := searchBlocks(idx, indexing.IsTask())
+			Expect(results).To(HaveLen(1))
+			Expect(results[0].Preview).To(Equal("task"))
+
+			results = searchBlocks(idx, indexing.HasLogbook())
+			Expect(results).To(HaveLen(1))
+			Expect(results[0].Preview).To(Equal("clocked"))
+
+			results = searchBlocks(idx, indexing.Or(indexing.IsTask(), indexing.HasLogbook()))
+			Expect(results).To(HaveLen(2))
+		})
+	})
+
 	Describe("LinksToURL", func() {
 		It("matches pages that link to a URL", func() {
 			indexPage(idx, "pages/a.md", "Page A",
@@ -0,0 +1,756 @@
+package indexing
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// SyntaxError is an error in the syntax of a query, at a position given in
+// characters from the start of the query.
+type SyntaxError struct {
+	Position int
+	Message  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at position %d: %s", e.Position, e.Message)
+}
+
+// Field prefixes that map to queries of their own. Any other prefix is the
+// name of a property.
+const (
+	prefixTitle     = "title"
+	prefixContent   = "content"
+	prefixRef       = "ref"
+	prefixTag       = "tag"
+	prefixPathRef   = "pathref"
+	prefixPathTag   = "pathtag"
+	prefixNamespace = "ns"
+	prefixAlias     = "alias"
+	prefixID        = "id"
+	prefixParent    = "parent"
+	prefixAncestor  = "ancestor"
+	prefixLink      = "link"
+
+	// prefixProperty is written before the name of a property that has the
+	// same name as one of the other prefixes, as in `prop.title:value`.
+	prefixProperty = "prop."
+)
+
+var reservedPrefixes = map[string]struct{}{
+	prefixTitle: {}, prefixContent: {}, prefixRef: {}, prefixTag: {},
+	prefixPathRef: {}, prefixPathTag: {}, prefixNamespace: {}, prefixAlias: {},
+	prefixID: {}, prefixParent: {}, prefixAncestor: {}, prefixLink: {},
+}
+
+// ParseQuery parses the syntax of a search box into a query. See the
+// ParseQuery of the logseq package for the syntax.
+func ParseQuery(text string) (Query, error) {
+	p := &queryParser{
+		input: []rune(text),
+	}
+
+	p.skipSpace()
+	if p.eof() {
+		return All(), nil
+	}
+
+	q, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+	if !p.eof() {
+		return nil, p.errorf("unexpected %q", string(p.peek()))
+	}
+
+	return q, nil
+}
+
+type queryParser struct {
+	input []rune
+	pos   int
+}
+
+func (p *queryParser) eof() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *queryParser) peek() rune {
+	if p.eof() {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *queryParser) errorf(format string, args ...any) error {
+	return p.errorAt(p.pos, format, args...)
+}
+
+func (p *queryParser) errorAt(pos int, format string, args ...any) error {
+	return &SyntaxError{
+		Position: pos,
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
+
+func (p *queryParser) skipSpace() {
+	for !p.eof() && unicode.IsSpace(p.peek()) {
+		p.pos++
+	}
+}
+
+// keyword checks if one of the keywords `OR`, `AND` and `NOT` is next and
+// skips past it if it is.
+func (p *queryParser) keyword(word string) bool {
+	end := p.pos + len(word)
+	if end > len(p.input) || string(p.input[p.pos:end]) != word {
+		return false
+	}
+
+	if end < len(p.input) && !unicode.IsSpace(p.input[end]) && p.input[end] != '(' {
+		return false
+	}
+
+	p.pos = end
+	return true
+}
+
+func (p *queryParser) parseOr() (Query, error) {
+	var clauses []Query
+	for {
+		clause, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, clause)
+
+		p.skipSpace()
+		if !p.keyword("OR") {
+			break
+		}
+	}
+
+	if len(clauses) == 1 {
+		return clauses[0], nil
+	}
+	return Or(clauses...), nil
+}
+
+func (p *queryParser) parseAnd() (Query, error) {
+	var clauses []Query
+	for {
+		p.skipSpace()
+		if p.eof() || p.peek() == ')' {
+			break
+		}
+
+		start := p.pos
+		if p.keyword("OR") {
+			p.pos = start
+			break
+		}
+
+		if p.keyword("AND") {
+			if len(clauses) == 0 {
+				return nil, p.errorAt(start, "AND needs a term before it")
+			}
+			continue
+		}
+
+		clause, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, clause)
+	}
+
+	switch len(clauses) {
+	case 0:
+		return nil, p.errorf("expected a term")
+	case 1:
+		return clauses[0], nil
+	default:
+		return And(clauses...), nil
+	}
+}
+
+func (p *queryParser) parseUnary() (Query, error) {
+	if p.peek() == '-' && p.pos+1 < len(p.input) && !unicode.IsSpace(p.input[p.pos+1]) {
+		p.pos++
+		clause, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return negate(clause), nil
+	}
+
+	if p.keyword("NOT") {
+		p.skipSpace()
+		if p.eof() {
+			return nil, p.errorf("NOT needs a term after it")
+		}
+
+		clause, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return negate(clause), nil
+	}
+
+	return p.parsePrimary()
+}
+
+// negate returns the query that matches what the clause does not. Nothing is
+// matched by negating everything, which is how None is written.
+func negate(clause Query) Query {
+	if _, ok := clause.(*all); ok {
+		return None()
+	}
+	return Not(clause)
+}
+
+func (p *queryParser) parsePrimary() (Query, error) {
+	switch p.peek() {
+	case '(':
+		return p.parseGroup()
+	case '"':
+		text, err := p.parseQuoted()
+		if err != nil {
+			return nil, err
+		}
+		return ContentMatchesPhrase(text), nil
+	case '*':
+		if p.pos+1 == len(p.input) || isValueEnd(p.input[p.pos+1]) {
+			p.pos++
+			return All(), nil
+		}
+	}
+
+	start := p.pos
+	name := p.parseName()
+	if name != "" && !p.eof() && (p.peek() == ':' || p.peek() == '~') {
+		return p.parseField(start, name)
+	}
+
+	// Not a field, so this is a word to look for in the content
+	p.pos = start
+	word := p.parseBare()
+	if word == "" {
+		return nil, p.errorf("unexpected %q", string(p.peek()))
+	}
+	return ContentMatches(word), nil
+}
+
+func (p *queryParser) parseGroup() (Query, error) {
+	open := p.pos
+	p.pos++
+
+	q, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+	if p.peek() != ')' {
+		return nil, p.errorAt(open, "missing closing parenthesis")
+	}
+	p.pos++
+
+	return q, nil
+}
+
+func (p *queryParser) parseQuoted() (string, error) {
+	open := p.pos
+	p.pos++
+
+	var b strings.Builder
+	for !p.eof() {
+		r := p.peek()
+		p.pos++
+
+		switch r {
+		case '"':
+			return b.String(), nil
+		case '\\':
+			if p.eof() {
+				return "", p.errorAt(open, "missing closing quote")
+			}
+			b.WriteRune(p.peek())
+			p.pos++
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return "", p.errorAt(open, "missing closing quote")
+}
+
+func (p *queryParser) parseName() string {
+	start := p.pos
+	for !p.eof() && isNameRune(p.peek()) {
+		p.pos++
+	}
+	return string(p.input[start:p.pos])
+}
+
+// parseBare reads a value that is not quoted, which ends at a space or a
+// closing parenthesis. Links such as `[[Le Guin]]` are read as a whole, so
+// they may contain spaces.
+func (p *queryParser) parseBare() string {
+	start := p.pos
+	for !p.eof() && !isValueEnd(p.peek()) {
+		if p.peek() == '[' && p.pos+1 < len(p.input) && p.input[p.pos+1] == '[' {
+			if end := p.linkEnd(); end > 0 {
+				p.pos = end
+				continue
+			}
+		}
+		p.pos++
+	}
+	return string(p.input[start:p.pos])
+}
+
+// linkEnd finds the end of a link that starts at the current position, or
+// returns zero if the link is never closed.
+func (p *queryParser) linkEnd() int {
+	for idx := p.pos + 2; idx+1 < len(p.input); idx++ {
+		if p.input[idx] == ']' && p.input[idx+1] == ']' {
+			return idx + 2
+		}
+	}
+	return 0
+}
+
+func (p *queryParser) parseField(start int, name string) (Query, error) {
+	matches := p.peek() == '~'
+	p.pos++
+
+	if p.peek() == '(' {
+		if matches || (name != prefixParent && name != prefixAncestor) {
+			return nil, p.errorf("%s does not take a group", name)
+		}
+
+		ancestor, err := p.parseGroup()
+		if err != nil {
+			return nil, err
+		}
+		return &hierarchy{ancestor: ancestor, direct: name == prefixParent}, nil
+	}
+
+	quoted := p.peek() == '"'
+	var value string
+	if quoted {
+		var err error
+		value, err = p.parseQuoted()
+		if err != nil {
+			return nil, err
+		}
+
+		// A namespace that needs quotes is written as `ns:"Reading List"/`
+		// to match the pages anywhere in it
+		if name == prefixNamespace && p.peek() == '/' {
+			p.pos++
+			return UnderNamespace(value), nil
+		}
+	} else {
+		value = p.parseBare()
+		if value == "" {
+			return nil, p.errorf("expected a value for %s", name)
+		}
+	}
+
+	property, isProperty := strings.CutPrefix(name, prefixProperty)
+	if !isProperty {
+		if _, reserved := reservedPrefixes[name]; !reserved {
+			property, isProperty = name, true
+		}
+	}
+
+	if matches {
+		switch {
+		case isProperty:
+			return PropertyMatches(property, value), nil
+		case name == prefixTitle:
+			return TitlePartiallyMatches(value), nil
+		default:
+			return nil, p.errorAt(start, "%s can not be matched with ~", name)
+		}
+	}
+
+	if isProperty {
+		if quoted {
+			return PropertyEquals(property, value), nil
+		}
+		return p.parsePropertyValue(start, property, value)
+	}
+
+	if !quoted {
+		value = unwrapLink(value)
+	}
+
+	switch name {
+	case prefixTitle:
+		return TitleMatches(value), nil
+	case prefixContent:
+		return ContentMatches(value), nil
+	case prefixRef:
+		return References(value), nil
+	case prefixTag:
+		return ReferencesTag(strings.TrimPrefix(value, "#")), nil
+	case prefixPathRef:
+		return ReferencesInPath(value), nil
+	case prefixPathTag:
+		return ReferencesTagInPath(strings.TrimPrefix(value, "#")), nil
+	case prefixNamespace:
+		if !quoted && len(value) > 1 && strings.HasSuffix(value, "/") {
+			return UnderNamespace(strings.TrimSuffix(value, "/")), nil
+		}
+		return InNamespace(value), nil
+	case prefixAlias:
+		return HasAlias(value), nil
+	case prefixID:
+		return BlockIDEquals(value), nil
+	case prefixParent:
+		return ChildOf(value), nil
+	case prefixAncestor:
+		return DescendantOf(value), nil
+	default:
+		return LinksToURL(value), nil
+	}
+}
+
+// parsePropertyValue turns a value of a property that is not quoted into a
+// query, where the value can be a range, a link or a tag.
+func (p *queryParser) parsePropertyValue(start int, property string, value string) (Query, error) {
+	switch {
+	case strings.HasPrefix(value, "[[") && strings.HasSuffix(value, "]]") && len(value) > 4:
+		return PropertyReferences(property, value[2:len(value)-2]), nil
+	case strings.HasPrefix(value, "#[[") && strings.HasSuffix(value, "]]") && len(value) > 5:
+		return PropertyReferencesTag(property, value[3:len(value)-2]), nil
+	case strings.HasPrefix(value, "#") && len(value) > 1:
+		return PropertyReferencesTag(property, value[1:]), nil
+	case strings.HasPrefix(value, ">"), strings.HasPrefix(value, "<"):
+		number, err := strconv.ParseFloat(value[1:], 64)
+		if err != nil {
+			return nil, p.errorAt(start, "%s is not a number", value[1:])
+		}
+
+		if value[0] == '>' {
+			return PropertyGreaterThan(property, number), nil
+		}
+		return PropertyLessThan(property, number), nil
+	}
+
+	if low, high, ok := strings.Cut(value, ".."); ok {
+		lowNumber, lowErr := strconv.ParseFloat(low, 64)
+		highNumber, highErr := strconv.ParseFloat(high, 64)
+		if lowErr == nil && highErr == nil {
+			return PropertyBetween(property, lowNumber, highNumber), nil
+		}
+
+		lowDate, lowOK := parseQueryDate(low)
+		highDate, highOK := parseQueryDate(high)
+		if lowOK && highOK {
+			return PropertyDateBetween(property, lowDate, highDate), nil
+		}
+
+		return nil, p.errorAt(start, "%s is not a range of numbers or dates", value)
+	}
+
+	return PropertyEquals(property, value), nil
+}
+
+// unwrapLink removes the brackets around a value written as a link, so that
+// `ref:[[Le Guin]]` references `Le Guin`.
+func unwrapLink(value string) string {
+	if strings.HasPrefix(value, "[[") && strings.HasSuffix(value, "]]") && len(value) > 4 {
+		return value[2 : len(value)-2]
+	}
+	return value
+}
+
+func parseQueryDate(value string) (time.Time, bool) {
+	if date, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return date, true
+	}
+
+	if date, err := time.Parse(time.RFC3339, value); err == nil {
+		return date, true
+	}
+
+	return time.Time{}, false
+}
+
+func formatQueryDate(date time.Time) string {
+	if date.Location() == time.Local && date.Equal(time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.Local)) {
+		return date.Format("2006-01-02")
+	}
+	return date.Format(time.RFC3339)
+}
+
+func formatQueryNumber(number float64) string {
+	return strconv.FormatFloat(number, 'g', -1, 64)
+}
+
+func isNameRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.'
+}
+
+func isValueEnd(r rune) bool {
+	return unicode.IsSpace(r) || r == '(' || r == ')'
+}
+
+// quoteQueryValue quotes a value, escaping quotes and backslashes in it.
+func quoteQueryValue(value string) string {
+	var b strings.Builder
+	b.WriteRune('"')
+	for _, r := range value {
+		if r == '"' || r == '\\' {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteRune('"')
+	return b.String()
+}
+
+// isPlainQueryValue checks if a value can be written without quotes and still
+// be read back as the same value.
+func isPlainQueryValue(value string) bool {
+	if value == "" || value == "*" || value == "OR" || value == "AND" || value == "NOT" {
+		return false
+	}
+
+	for idx, r := range value {
+		if isValueEnd(r) || r == '"' || r == '\\' {
+			return false
+		}
+
+		if idx == 0 && r == '-' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// formatQueryValue writes a value quoted if it has to be. Values that start
+// like a link are quoted too, as the brackets of a link are removed when the
+// value is read.
+func formatQueryValue(value string) string {
+	if isPlainQueryValue(value) && !strings.HasPrefix(value, "[[") {
+		return value
+	}
+	return quoteQueryValue(value)
+}
+
+// formatPropertyName writes the name of a property, which needs a prefix if
+// it is the same as one of the other prefixes.
+func formatPropertyName(name string) string {
+	if _, reserved := reservedPrefixes[name]; reserved || strings.HasPrefix(name, prefixProperty) {
+		return prefixProperty + name
+	}
+	return name
+}
+
+// formatPropertyValue writes the value of a property, quoting it if it could
+// be read as a range, link or tag.
+func formatPropertyValue(value string) string {
+	if !isPlainQueryValue(value) ||
+		strings.HasPrefix(value, "[[") ||
+		strings.HasPrefix(value, "#") ||
+		strings.HasPrefix(value, ">") ||
+		strings.HasPrefix(value, "<") ||
+		strings.Contains(value, "..") {
+		return quoteQueryValue(value)
+	}
+	return value
+}
+
+// formatRef writes the target of a reference, so that it is read back the
+// same way.
+func formatRef(target string) string {
+	if isPlainQueryValue(target) && !strings.HasPrefix(target, "#") && !strings.HasPrefix(target, "[[") {
+		return target
+	}
+	return quoteQueryValue(target)
+}
+
+func formatClause(q Query, parent Query) string {
+	s := q.String()
+
+	switch query := q.(type) {
+	case *or:
+		if len(query.clauses) > 1 {
+			if _, ok := parent.(*or); !ok {
+				return "(" + s + ")"
+			}
+		}
+	case *and:
+		if len(query.clauses) > 1 {
+			if _, ok := parent.(*not); ok {
+				return "(" + s + ")"
+			}
+			if _, ok := parent.(*and); ok {
+				return "(" + s + ")"
+			}
+		}
+	}
+
+	return s
+}
+
+func (a *all) String() string {
+	return "*"
+}
+
+func (n *none) String() string {
+	return "-*"
+}
+
+func (a *and) String() string {
+	if len(a.clauses) == 0 {
+		return "*"
+	}
+
+	parts := make([]string, len(a.clauses))
+	for idx, clause := range a.clauses {
+		parts[idx] = formatClause(clause, a)
+	}
+	return strings.Join(parts, " ")
+}
+
+func (o *or) String() string {
+	if len(o.clauses) == 0 {
+		return "-*"
+	}
+
+	parts := make([]string, len(o.clauses))
+	for idx, clause := range o.clauses {
+		parts[idx] = formatClause(clause, o)
+	}
+	return strings.Join(parts, " OR ")
+}
+
+func (n *not) String() string {
+	return "-" + formatClause(n.clause, n)
+}
+
+func (f *fieldMatches) String() string {
+	switch {
+	case f.field == "title" && f.partial:
+		return prefixTitle + "~" + formatQueryValue(f.text)
+	case f.field == "title":
+		return prefixTitle + ":" + formatQueryValue(f.text)
+	case f.field == "content" && f.phrase:
+		return quoteQueryValue(f.text)
+	case f.field == "content":
+		if isPlainQueryValue(f.text) && !strings.ContainsAny(f.text, ":~") && !strings.HasPrefix(f.text, "*") {
+			return f.text
+		}
+		return prefixContent + ":" + quoteQueryValue(f.text)
+	case strings.HasPrefix(f.field, "prop:"):
+		return formatPropertyName(f.field[len("prop:"):]) + "~" + formatQueryValue(f.text)
+	default:
+		return f.field + "~" + formatQueryValue(f.text)
+	}
+}
+
+func (f *fieldEquals) String() string {
+	switch {
+	case f.field == "id":
+		return prefixID + ":" + formatQueryValue(f.value)
+	case f.field == "namespace":
+		if strings.HasSuffix(f.value, "/") {
+			return prefixNamespace + ":" + quoteQueryValue(f.value)
+		}
+		return prefixNamespace + ":" + formatQueryValue(f.value)
+	case f.field == "namespaces":
+		if isPlainQueryValue(f.value) {
+			return prefixNamespace + ":" + f.value + "/"
+		}
+		return prefixNamespace + ":" + quoteQueryValue(f.value) + "/"
+	case f.field == "link":
+		return prefixLink + ":" + formatQueryValue(f.value)
+	case strings.HasPrefix(f.field, "prop:"):
+		return formatPropertyName(f.field[len("prop:"):]) + ":" + formatPropertyValue(f.value)
+	default:
+		return f.field + ":" + formatQueryValue(f.value)
+	}
+}
+
+func (f *fieldRefs) String() string {
+	switch {
+	case f.field == "pages" && f.tag:
+		return prefixTag + ":" + formatRef(f.target)
+	case f.field == "pages":
+		return prefixRef + ":" + formatRef(f.target)
+	case f.field == "path" && f.tag:
+		return prefixPathTag + ":" + formatRef(f.target)
+	case f.field == "path":
+		return prefixPathRef + ":" + formatRef(f.target)
+	case f.field == "alias":
+		return prefixAlias + ":" + formatQueryValue(f.target)
+	case strings.HasPrefix(f.field, "prop:"):
+		name := formatPropertyName(f.field[len("prop:"):])
+		if f.tag {
+			return name + ":#[[" + f.target + "]]"
+		}
+		return name + ":[[" + f.target + "]]"
+	default:
+		return f.field + ":" + formatQueryValue(f.target)
+	}
+}
+
+func (n *numberRange) String() string {
+	name := strings.TrimPrefix(n.field, "prop:")
+	name = formatPropertyName(name)
+
+	switch {
+	case math.IsInf(n.max, 1) && !n.minInclusive:
+		return name + ":>" + formatQueryNumber(n.min)
+	case math.IsInf(n.min, -1) && !n.maxInclusive:
+		return name + ":<" + formatQueryNumber(n.max)
+	default:
+		return name + ":" + formatQueryNumber(n.min) + ".." + formatQueryNumber(n.max)
+	}
+}
+
+func (d *dateRange) String() string {
+	name := formatPropertyName(strings.TrimPrefix(d.field, "prop:"))
+	return name + ":" + formatQueryDate(d.start) + ".." + formatQueryDate(d.end)
+}
+
+func (h *hierarchy) String() string {
+	prefix := prefixAncestor
+	if h.direct {
+		prefix = prefixParent
+	}
+
+	if id, ok := h.ancestor.(*fieldEquals); ok && id.field == "id" {
+		return prefix + ":" + formatQueryValue(id.value)
+	}
+
+	return prefix + ":(" + h.ancestor.String() + ")"
+}
+
+func (i *idsIn) String() string {
+	return "ids(" + i.field + ":" + strings.Join(i.ids, ",") + ")"
+}
+
+func (s *suggests) String() string {
+	if s.start {
+		return "startswith(" + quoteQueryValue(s.text) + ")"
+	}
+	return "suggests(" + quoteQueryValue(s.text) + ")"
+}
+
+func (h *hasField) String() string {
+	return "has(" + h.field + ")"
+}
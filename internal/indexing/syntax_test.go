@@ -0,0 +1,172 @@
+package indexing_test
+
+import (
+	"time"
+
+	"github.com/aholstenson/logseq-go/internal/indexing"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Query syntax", func() {
+	parse := func(text string) indexing.Query {
+		q, err := indexing.ParseQuery(text)
+		Expect(err).ToNot(HaveOccurred())
+		return q
+	}
+
+	parseError := func(text string) *indexing.SyntaxError {
+		_, err := indexing.ParseQuery(text)
+		Expect(err).To(HaveOccurred())
+
+		syntaxErr, ok := err.(*indexing.SyntaxError)
+		Expect(ok).To(BeTrue())
+		return syntaxErr
+	}
+
+	It("parses the example from the documentation", func() {
+		Expect(parse(`tag:book author:"Le Guin" -status:done "exact phrase" ns:Reading/`)).To(Equal(indexing.And(
+			indexing.ReferencesTag("book"),
+			indexing.PropertyEquals("author", "Le Guin"),
+			indexing.Not(indexing.PropertyEquals("status", "done")),
+			indexing.ContentMatchesPhrase("exact phrase"),
+			indexing.UnderNamespace("Reading"),
+		)))
+	})
+
+	It("parses words as content", func() {
+		Expect(parse("hello")).To(Equal(indexing.ContentMatches("hello")))
+		Expect(parse("")).To(Equal(indexing.All()))
+	})
+
+	It("parses OR with lower precedence than AND", func() {
+		Expect(parse("a b OR c")).To(Equal(indexing.Or(
+			indexing.And(indexing.ContentMatches("a"), indexing.ContentMatches("b")),
+			indexing.ContentMatches("c"),
+		)))
+	})
+
+	It("parses groups and NOT", func() {
+		Expect(parse("NOT (tag:a OR tag:b) AND c")).To(Equal(indexing.And(
+			indexing.Not(indexing.Or(indexing.ReferencesTag("a"), indexing.ReferencesTag("b"))),
+			indexing.ContentMatches("c"),
+		)))
+	})
+
+	It("parses the prefixes of queries", func() {
+		Expect(parse("title:Logseq")).To(Equal(indexing.TitleMatches("Logseq")))
+		Expect(parse(`content:"two words"`)).To(Equal(indexing.ContentMatches("two words")))
+		Expect(parse("ref:[[Le Guin]]")).To(Equal(indexing.References("Le Guin")))
+		Expect(parse("tag:#book")).To(Equal(indexing.ReferencesTag("book")))
+		Expect(parse("pathref:Project")).To(Equal(indexing.ReferencesInPath("Project")))
+		Expect(parse("pathtag:idea")).To(Equal(indexing.ReferencesTagInPath("idea")))
+		Expect(parse("ns:Reading")).To(Equal(indexing.InNamespace("Reading")))
+		Expect(parse(`ns:"Reading List"/`)).To(Equal(indexing.UnderNamespace("Reading List")))
+		Expect(parse("alias:LS")).To(Equal(indexing.HasAlias("LS")))
+		Expect(parse("id:abc")).To(Equal(indexing.BlockIDEquals("abc")))
+		Expect(parse("parent:abc")).To(Equal(indexing.ChildOf("abc")))
+		Expect(parse("ancestor:abc")).To(Equal(indexing.DescendantOf("abc")))
+		Expect(parse("ancestor:(tag:decision)")).To(Equal(indexing.HasAncestorMatching(indexing.ReferencesTag("decision"))))
+		Expect(parse("link:https://example.com/a")).To(Equal(indexing.LinksToURL("https://example.com/a")))
+	})
+
+	It("parses property values", func() {
+		Expect(parse("author~guin")).To(Equal(indexing.PropertyMatches("author", "guin")))
+		Expect(parse("author:[[Le Guin]]")).To(Equal(indexing.PropertyReferences("author", "Le Guin")))
+		Expect(parse("type:#book")).To(Equal(indexing.PropertyReferencesTag("type", "book")))
+		Expect(parse("rating:>3")).To(Equal(indexing.PropertyGreaterThan("rating", 3)))
+		Expect(parse("rating:<3.5")).To(Equal(indexing.PropertyLessThan("rating", 3.5)))
+		Expect(parse("rating:1..5")).To(Equal(indexing.PropertyBetween("rating", 1, 5)))
+		Expect(parse("due:2024-01-01..2024-01-31")).To(Equal(indexing.PropertyDateBetween(
+			"due",
+			time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local),
+			time.Date(2024, 1, 31, 0, 0, 0, 0, time.Local),
+		)))
+		Expect(parse(`rating:"1..5"`)).To(Equal(indexing.PropertyEquals("rating", "1..5")))
+		Expect(parse("prop.title:Dune")).To(Equal(indexing.PropertyEquals("title", "Dune")))
+	})
+
+	It("returns errors with their position", func() {
+		err := parseError(`tag:book "open`)
+		Expect(err.Position).To(Equal(9))
+		Expect(err.Message).To(Equal("missing closing quote"))
+
+		err = parseError("(a OR b")
+		Expect(err.Position).To(Equal(0))
+		Expect(err.Message).To(Equal("missing closing parenthesis"))
+
+		err = parseError("a )")
+		Expect(err.Position).To(Equal(2))
+
+		err = parseError("rating:>many")
+		Expect(err.Position).To(Equal(0))
+
+		err = parseError("a OR")
+		Expect(err.Position).To(Equal(4))
+		Expect(err.Error()).To(Equal("syntax error at position 4: expected a term"))
+	})
+
+	It("writes queries that read back the same", func() {
+		queries := []indexing.Query{
+			indexing.All(),
+			indexing.None(),
+			indexing.Not(indexing.None()),
+			indexing.ContentMatches("hello"),
+			indexing.ContentMatches("two words"),
+			indexing.ContentMatches("key:value"),
+			indexing.ContentMatches("-dash"),
+			indexing.ContentMatches("OR"),
+			indexing.ContentMatchesPhrase(`say "hi"`),
+			indexing.TitleMatches("Logseq"),
+			indexing.TitleMatches("[[x]]"),
+			indexing.TitlePartiallyMatches("[[x]]"),
+			indexing.References("Le Guin"),
+			indexing.References("#hash"),
+			indexing.ReferencesTag("science fiction"),
+			indexing.ReferencesInPath("Project"),
+			indexing.ReferencesTagInPath("idea"),
+			indexing.InNamespace("Reading"),
+			indexing.UnderNamespace("Reading List"),
+			indexing.HasAlias("LS"),
+			indexing.HasAlias("[[LS]]"),
+			indexing.BlockIDEquals("6568d5b0-0e4b-4f2b-9bb4-0a4c2e8d3f10"),
+			indexing.ChildOf("abc"),
+			indexing.DescendantOf("abc"),
+			indexing.HasAncestorMatching(indexing.Or(indexing.ReferencesTag("a"), indexing.ReferencesTag("b"))),
+			indexing.LinksToURL("https://example.com/a?b=c"),
+			indexing.LinksToURL("[[x]]"),
+			indexing.BlockIDEquals("[[abc]]"),
+			indexing.InNamespace("[[Reading]]"),
+			indexing.PropertyEquals("status", "done"),
+			indexing.PropertyEquals("author", "Le Guin"),
+			indexing.PropertyEquals("range", "1..5"),
+			indexing.PropertyEquals("title", "Dune"),
+			indexing.PropertyMatches("author", "le guin"),
+			indexing.PropertyReferences("author", "Le Guin"),
+			indexing.PropertyReferencesTag("type", "book"),
+			indexing.PropertyGreaterThan("rating", 3),
+			indexing.PropertyLessThan("rating", -1.5),
+			indexing.PropertyBetween("rating", 1, 5),
+			indexing.PropertyDateBetween(
+				"due",
+				time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local),
+				time.Date(2024, 1, 31, 0, 0, 0, 0, time.Local),
+			),
+			indexing.Not(indexing.PropertyEquals("status", "done")),
+			indexing.Not(indexing.And(indexing.ContentMatches("a"), indexing.ContentMatches("b"))),
+			indexing.And(
+				indexing.Or(indexing.ReferencesTag("a"), indexing.ReferencesTag("b")),
+				indexing.Not(indexing.Or(indexing.ContentMatches("c"), indexing.ContentMatches("d"))),
+				indexing.And(indexing.ContentMatches("e"), indexing.ContentMatches("f")),
+			),
+			indexing.Or(
+				indexing.And(indexing.ContentMatches("a"), indexing.ContentMatches("b")),
+				indexing.ContentMatches("c"),
+			),
+		}
+
+		for _, q := range queries {
+			Expect(parse(q.String())).To(Equal(q), "query written as %s", q.String())
+		}
+	})
+})
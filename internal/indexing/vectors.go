@@ -0,0 +1,386 @@
+package indexing
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aholstenson/logseq-go/content"
+)
+
+// vectorsFile is the file vectors are kept in, in the directory of the index.
+const vectorsFile = "vectors.gob"
+
+// Embedder turns text into vectors, where texts that mean similar things get
+// vectors that point in similar directions.
+type Embedder interface {
+	// Embed returns a vector for every text, in the same order as the texts.
+	// Every vector returned by an embedder should have the same length.
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// NamedEmbedder is an embedder that can tell which model it uses. Vectors kept
+// between runs are only reused with an embedder of the same name, so the name
+// should change whenever the model does. Embedders without a name are told
+// apart by their type.
+type NamedEmbedder interface {
+	Embedder
+
+	// Name returns the name of the model the embedder uses.
+	Name() string
+}
+
+// embedderName returns the name vectors made by an embedder are kept under.
+func embedderName(embedder Embedder) string {
+	if named, ok := embedder.(NamedEmbedder); ok {
+		return named.Name()
+	}
+
+	return fmt.Sprintf("%T", embedder)
+}
+
+// VectorIndex keeps a vector for every block, so that blocks can be found by
+// how similar they are to a text or to another block. Blocks have the same
+// ids as in the Bluge index, so the blocks found can be looked up there.
+type VectorIndex struct {
+	mu sync.RWMutex
+
+	embedder Embedder
+
+	// dimensions is the length of the vectors made by the embedder, or zero
+	// if nothing has been embedded yet.
+	dimensions int
+
+	// file is where the vectors are kept between runs, empty if the vectors
+	// are only kept in memory.
+	file string
+
+	pages   map[string]*vectorPage
+	changed bool
+}
+
+// vectorsVersion is increased when the ids of blocks change, so that pages
+// embedded by earlier versions are embedded again.
+const vectorsVersion = 3
+
+// vectorsData is what is kept in the vectors file. The embedder the vectors
+// were made by is kept with them, as vectors from different embedders cannot
+// be compared with each other.
+type vectorsData struct {
+	Embedder   string
+	Dimensions int
+	Pages      map[string]*vectorPage
+}
+
+// vectorPage is the vectors of the blocks on a page.
+type vectorPage struct {
+	Version      int
+	LastModified time.Time
+	Blocks       map[string][]float32
+
+	// Hashes is the hash of the text of every block, so that blocks with the
+	// same text as before keep their vector instead of being embedded again.
+	Hashes map[string]string
+}
+
+// VectorMatch is a block found in the vector index, with the cosine
+// similarity of its vector as the score.
+type VectorMatch struct {
+	ID    string
+	Score float64
+}
+
+// vector returns the vector of a block if it was embedded from a text with
+// the given hash.
+func (p *vectorPage) vector(id string, hash string) []float32 {
+	if p == nil || p.Version != vectorsVersion || p.Hashes[id] != hash {
+		return nil
+	}
+
+	return p.Blocks[id]
+}
+
+// NewVectorIndex creates a vector index that uses the embedder for the blocks
+// it indexes. If a directory is given the vectors are kept in it between runs,
+// otherwise they are only kept in memory.
+func NewVectorIndex(embedder Embedder, indexDirectory string) (*VectorIndex, error) {
+	v := &VectorIndex{
+		embedder: embedder,
+		pages:    make(map[string]*vectorPage),
+	}
+
+	if indexDirectory == "" {
+		return v, nil
+	}
+
+	v.file = filepath.Join(indexDirectory, vectorsFile)
+	f, err := os.Open(v.file)
+	if errors.Is(err, os.ErrNotExist) {
+		return v, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error opening vectors: %w", err)
+	}
+	defer f.Close()
+
+	// Vectors can always be recreated, so start over instead of failing if
+	// they cannot be read or were made by another embedder
+	var data vectorsData
+	err = gob.NewDecoder(f).Decode(&data)
+	if err != nil || data.Embedder != embedderName(embedder) || !v.hasDimensions(data.Dimensions) {
+		return v, nil
+	}
+
+	v.pages = data.Pages
+	return v, nil
+}
+
+// hasDimensions checks if the embedder makes vectors with the given number of
+// dimensions, as the same model can be set up to make vectors of different
+// lengths.
+func (v *VectorIndex) hasDimensions(dimensions int) bool {
+	vector, err := v.Embed(context.Background(), "dimensions")
+	if err != nil {
+		return false
+	}
+
+	v.dimensions = len(vector)
+	return v.dimensions == dimensions
+}
+
+// Close syncs the vectors to disk.
+func (v *VectorIndex) Close() error {
+	return v.Sync()
+}
+
+// Sync writes the vectors to disk if they have changed and the index is kept
+// in a directory.
+func (v *VectorIndex) Sync() error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if !v.changed || v.file == "" {
+		return nil
+	}
+
+	// Write to a temporary file first, so a crash never leaves half of the
+	// vectors behind
+	tmp := v.file + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("error writing vectors: %w", err)
+	}
+
+	err = gob.NewEncoder(f).Encode(&vectorsData{
+		Embedder:   embedderName(v.embedder),
+		Dimensions: v.dimensions,
+		Pages:      v.pages,
+	})
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("error writing vectors: %w", err)
+	}
+
+	err = f.Close()
+	if err != nil {
+		return fmt.Errorf("error writing vectors: %w", err)
+	}
+
+	err = os.Rename(tmp, v.file)
+	if err != nil {
+		return fmt.Errorf("error writing vectors: %w", err)
+	}
+
+	v.changed = false
+	return nil
+}
+
+// GetLastModified returns the last modified time of a page when its blocks
+// were embedded, or a zero time if the page is not in the index.
+func (v *VectorIndex) GetLastModified(subPath string) time.Time {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	if page, ok := v.pages[subPath]; ok && page.Version == vectorsVersion {
+		return page.LastModified
+	}
+	return time.Time{}
+}
+
+// IndexPage embeds the blocks of a page, replacing the vectors the page had
+// before. Blocks without any text are left out, and blocks with the same text
+// as when the page was last embedded keep their vector.
+func (v *VectorIndex) IndexPage(ctx context.Context, page *Page) error {
+	v.mu.RLock()
+	previous := v.pages[page.SubPath]
+	v.mu.RUnlock()
+
+	keys := blockKeys(page.SubPath, page.Blocks)
+	blocks := make(map[string][]float32)
+	hashes := make(map[string]string)
+
+	var ids []string
+	var texts []string
+
+	var collect func(blocks content.BlockList)
+	collect = func(list content.BlockList) {
+		for _, block := range list {
+			text := plainText(block.Content())
+			if text != "" {
+				id := keys[block]
+				hash := textHash(text)
+				hashes[id] = hash
+
+				if vector := previous.vector(id, hash); vector != nil {
+					blocks[id] = vector
+				} else {
+					ids = append(ids, id)
+					texts = append(texts, text)
+				}
+			}
+
+			collect(block.Blocks())
+		}
+	}
+	collect(page.Blocks)
+
+	if len(texts) > 0 {
+		vectors, err := v.embedder.Embed(ctx, texts)
+		if err != nil {
+			return fmt.Errorf("error embedding blocks: %w", err)
+		}
+
+		if len(vectors) != len(texts) {
+			return fmt.Errorf("embedder returned %d vectors for %d texts", len(vectors), len(texts))
+		}
+
+		for idx, id := range ids {
+			blocks[id] = normalizeVector(vectors[idx])
+		}
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if len(ids) > 0 {
+		v.dimensions = len(blocks[ids[0]])
+	}
+
+	v.pages[page.SubPath] = &vectorPage{
+		Version:      vectorsVersion,
+		LastModified: page.LastModified,
+		Blocks:       blocks,
+		Hashes:       hashes,
+	}
+	v.changed = true
+	return nil
+}
+
+// DeletePage removes the vectors of the blocks on a page.
+func (v *VectorIndex) DeletePage(ctx context.Context, subPath string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if _, ok := v.pages[subPath]; ok {
+		delete(v.pages, subPath)
+		v.changed = true
+	}
+	return nil
+}
+
+// Embed turns a text into a vector that can be compared with the vectors of
+// blocks.
+func (v *VectorIndex) Embed(ctx context.Context, text string) ([]float32, error) {
+	vectors, err := v.embedder.Embed(ctx, []string{text})
+	if err != nil {
+		return nil, fmt.Errorf("error embedding text: %w", err)
+	}
+
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("embedder returned %d vectors for 1 text", len(vectors))
+	}
+
+	return normalizeVector(vectors[0]), nil
+}
+
+// Vector returns the vector of a block, or nil if the block has none.
+func (v *VectorIndex) Vector(id string) []float32 {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	// Ids of blocks start with the sub path of their page
+	if page, ok := v.pages[pageOfBlockID(id)]; ok {
+		return page.Blocks[id]
+	}
+	return nil
+}
+
+// Similar returns the blocks with vectors that are the most similar to the
+// given vector, most similar first. Only blocks that the filter accepts are
+// included, a nil filter accepts every block. Vectors of another length than
+// the one given, such as ones made by another embedder, are skipped.
+func (v *VectorIndex) Similar(vector []float32, filter func(id string) bool) []VectorMatch {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	var matches []VectorMatch
+	for _, page := range v.pages {
+		for id, other := range page.Blocks {
+			if len(other) != len(vector) {
+				continue
+			}
+
+			if filter != nil && !filter(id) {
+				continue
+			}
+
+			matches = append(matches, VectorMatch{
+				ID:    id,
+				Score: dotProduct(vector, other),
+			})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].ID < matches[j].ID
+	})
+
+	return matches
+}
+
+// normalizeVector scales a vector to a length of one, so that the dot product
+// of two vectors is their cosine similarity.
+func normalizeVector(vector []float32) []float32 {
+	var sum float64
+	for _, value := range vector {
+		sum += float64(value) * float64(value)
+	}
+
+	result := make([]float32, len(vector))
+	if sum == 0 {
+		return result
+	}
+
+	length := math.Sqrt(sum)
+	for idx, value := range vector {
+		result[idx] = float32(float64(value) / length)
+	}
+	return result
+}
+
+func dotProduct(a []float32, b []float32) float64 {
+	var sum float64
+	for idx := range a {
+		sum += float64(a[idx]) * float64(b[idx])
+	}
+	return sum
+}
@@ -0,0 +1,149 @@
+package indexing_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aholstenson/logseq-go/content"
+	"github.com/aholstenson/logseq-go/internal/indexing"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// countingEmbedder counts the texts it has been asked to embed.
+type countingEmbedder struct {
+	indexing.Embedder
+
+	texts int
+}
+
+func (e *countingEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	e.texts += len(texts)
+	return e.Embedder.Embed(ctx, texts)
+}
+
+// namedEmbedder is a hashing embedder with a name of its own.
+type namedEmbedder struct {
+	*indexing.HashingEmbedder
+
+	name string
+}
+
+func (e *namedEmbedder) Name() string {
+	return e.name
+}
+
+var _ = Describe("Vectors", func() {
+	var (
+		vectors *indexing.VectorIndex
+		ctx     context.Context
+	)
+
+	BeforeEach(func() {
+		var err error
+		vectors, err = indexing.NewVectorIndex(indexing.NewHashingEmbedder(256), "")
+		Expect(err).ToNot(HaveOccurred())
+		ctx = context.Background()
+	})
+
+	// indexVectors indexes a page with a block for every text, below a root
+	// block. The blocks get the ids b0, b1 and so on.
+	indexVectors := func(subPath string, texts ...string) {
+		root := content.NewBlock(content.NewParagraph(content.NewText("root")))
+		for idx, text := range texts {
+			root.AddChild(content.NewBlock(
+				content.NewParagraph(content.NewText(text)),
+				content.NewProperties(content.NewProperty("id", content.NewText(fmt.Sprintf("b%d", idx)))),
+			))
+		}
+
+		Expect(vectors.IndexPage(ctx, &indexing.Page{
+			SubPath:      subPath,
+			LastModified: time.Now(),
+			Blocks:       content.BlockList{root},
+		})).To(Succeed())
+	}
+
+	similar := func(text string) []indexing.VectorMatch {
+		vector, err := vectors.Embed(ctx, text)
+		Expect(err).ToNot(HaveOccurred())
+		return vectors.Similar(vector, nil)
+	}
+
+	It("ranks the most similar block first", func() {
+		indexVectors("pages/a.md", "growing tomatoes in the garden", "writing unit tests")
+
+		matches := similar("tests")
+		Expect(matches).To(HaveLen(3))
+		Expect(matches[0].ID).To(Equal("pages/a.md#b1"))
+		Expect(matches[0].Score).To(BeNumerically(">", matches[1].Score))
+	})
+
+	It("keeps the vector of a block by its id", func() {
+		indexVectors("pages/a.md", "growing tomatoes")
+
+		Expect(vectors.Vector("pages/a.md#b0")).ToNot(BeNil())
+		Expect(vectors.Vector("pages/b.md#b0")).To(BeNil())
+	})
+
+	It("only embeds blocks with new text", func() {
+		embedder := &countingEmbedder{Embedder: indexing.NewHashingEmbedder(256)}
+		var err error
+		vectors, err = indexing.NewVectorIndex(embedder, "")
+		Expect(err).ToNot(HaveOccurred())
+
+		indexVectors("pages/a.md", "growing tomatoes", "writing unit tests")
+		Expect(embedder.texts).To(Equal(3))
+
+		indexVectors("pages/a.md", "growing tomatoes", "writing more unit tests")
+		Expect(embedder.texts).To(Equal(4))
+	})
+
+	Describe("Keeping vectors between runs", func() {
+		var dir string
+
+		BeforeEach(func() {
+			dir = GinkgoT().TempDir()
+		})
+
+		reopen := func(embedder indexing.Embedder) {
+			Expect(vectors.Close()).To(Succeed())
+
+			var err error
+			vectors, err = indexing.NewVectorIndex(embedder, dir)
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		BeforeEach(func() {
+			reopen(indexing.NewHashingEmbedder(256))
+			indexVectors("pages/a.md", "growing tomatoes")
+		})
+
+		It("keeps the vectors for the same embedder", func() {
+			reopen(indexing.NewHashingEmbedder(256))
+			Expect(vectors.Vector("pages/a.md#b0")).ToNot(BeNil())
+			Expect(vectors.GetLastModified("pages/a.md").IsZero()).To(BeFalse())
+		})
+
+		It("drops the vectors of another embedder", func() {
+			reopen(&namedEmbedder{HashingEmbedder: indexing.NewHashingEmbedder(256), name: "other"})
+			Expect(vectors.Vector("pages/a.md#b0")).To(BeNil())
+			Expect(vectors.GetLastModified("pages/a.md").IsZero()).To(BeTrue())
+		})
+
+		It("drops the vectors of the same embedder with other dimensions", func() {
+			reopen(indexing.NewHashingEmbedder(512))
+			Expect(vectors.Vector("pages/a.md#b0")).To(BeNil())
+			Expect(vectors.GetLastModified("pages/a.md").IsZero()).To(BeTrue())
+		})
+	})
+
+	It("removes the vectors of deleted pages", func() {
+		indexVectors("pages/a.md", "growing tomatoes")
+		Expect(vectors.DeletePage(ctx, "pages/a.md")).To(Succeed())
+
+		Expect(similar("tomatoes")).To(BeEmpty())
+		Expect(vectors.GetLastModified("pages/a.md").IsZero()).To(BeTrue())
+	})
+})
@@ -1605,7 +1605,9 @@ func (w *Output) logbookStateChange(node *content.LogbookEntryStateChange) (stri
 }
 
 func (w *Output) writeLogbook(node *content.Logbook) error {
-	err := w.startBlock(node, "")
+	// Like task dates the logbook belongs to the task above it, so it does not
+	// get a blank line of its own when the previous line type is automatic.
+	err := w.startBlockWithAutomaticBehavior(node, "", false)
 	if err != nil {
 		return err
 	}
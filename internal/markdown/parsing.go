@@ -77,11 +77,6 @@ type ParseOption func(*parseOptions)
 // the parts of parsing that Goldmark drives can reach them.
 var parseOptionsKey = parser.NewContextKey()
 
-// builtInPropertiesSeparatedByCommas are the properties whose value Logseq
-// always reads as a list of pages separated by commas, no matter what the
-// graph configures.
-var builtInPropertiesSeparatedByCommas = []string{"alias", "aliases", "tags"}
-
 // parseOptions are the settings that parsing takes from the graph. The zero
 // value is not usable, use defaultParseOptions to get the defaults of Logseq.
 type parseOptions struct {
@@ -97,16 +92,10 @@ type parseOptions struct {
 // defaultParseOptions are what Logseq does for a graph that does not configure
 // anything else.
 func defaultParseOptions() parseOptions {
-	options := parseOptions{
+	return parseOptions{
 		propertiesSeparatedByCommas: make(map[string]struct{}),
 		ignoredPageReferences:       make(map[string]struct{}),
 	}
-
-	for _, name := range builtInPropertiesSeparatedByCommas {
-		options.propertiesSeparatedByCommas[name] = struct{}{}
-	}
-
-	return options
 }
 
 // WithPropertiesSeparatedByCommas adds properties whose value is a list of
@@ -134,7 +123,7 @@ func WithIgnoredPageReferences(names ...string) ParseOption {
 // is a list of pages separated by commas.
 func (o *parseOptions) isSeparatedByCommas(name string) bool {
 	_, ok := o.propertiesSeparatedByCommas[normalizePropertyName(name)]
-	return ok
+	return ok || content.IsAlwaysSeparatedByCommas(name)
 }
 
 // ignoresPageReferences checks if the value of the property with the given
@@ -776,7 +765,9 @@ func convertProperties(src []byte, node *properties) (*content.Properties, error
 			return nil, errors.New("Invalid child in properties")
 		}
 
-		prop := content.NewProperty(p.Name).WithPageRefsIgnored(p.PageRefsIgnored)
+		prop := content.NewProperty(p.Name).
+			WithPageRefsIgnored(p.PageRefsIgnored).
+			WithSeparatedByCommas(p.SeparatedByCommas)
 		err := convertChildren(src, p, prop)
 		if err != nil {
 			return nil, err
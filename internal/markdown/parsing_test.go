@@ -2248,7 +2248,7 @@ var _ = Describe("Parsing", func() {
 							content.NewPageRefText("First"),
 							content.NewText(", "),
 							content.NewPageRefText("Second Name"),
-						),
+						).WithSeparatedByCommas(true),
 					),
 				)))
 			})
@@ -2259,7 +2259,8 @@ var _ = Describe("Parsing", func() {
 
 				Expect(block).To(tests.EqualNode(content.NewBlock(
 					content.NewProperties(
-						content.NewProperty("tags", content.NewPageRefText("Example")),
+						content.NewProperty("tags", content.NewPageRefText("Example")).
+							WithSeparatedByCommas(true),
 					),
 				)))
 			})
@@ -2275,7 +2276,7 @@ var _ = Describe("Parsing", func() {
 							content.NewPageRefText("First"),
 							content.NewText("，"),
 							content.NewPageRefText("Second"),
-						),
+						).WithSeparatedByCommas(true),
 					),
 				)))
 			})
@@ -2293,7 +2294,7 @@ var _ = Describe("Parsing", func() {
 							content.NewPageRefText("Second"),
 							content.NewText(", "),
 							content.NewHashtag("Third"),
-						),
+						).WithSeparatedByCommas(true),
 					),
 				)))
 			})
@@ -2304,7 +2305,8 @@ var _ = Describe("Parsing", func() {
 
 				Expect(block).To(tests.EqualNode(content.NewBlock(
 					content.NewProperties(
-						content.NewProperty("tags", content.NewText(",")),
+						content.NewProperty("tags", content.NewText(",")).
+							WithSeparatedByCommas(true),
 					),
 				)))
 			})
@@ -2323,7 +2325,7 @@ var _ = Describe("Parsing", func() {
 							content.NewPageRefText("First"),
 							content.NewText(", "),
 							content.NewPageRefText("Second Name"),
-						),
+						).WithSeparatedByCommas(true),
 					),
 				)))
 			})
@@ -2337,7 +2339,8 @@ var _ = Describe("Parsing", func() {
 
 				Expect(block).To(tests.EqualNode(content.NewBlock(
 					content.NewProperties(
-						content.NewProperty("Key", content.NewPageRefText("First")),
+						content.NewProperty("Key", content.NewPageRefText("First")).
+							WithSeparatedByCommas(true),
 					),
 				)))
 			})
@@ -2382,7 +2385,8 @@ var _ = Describe("Parsing", func() {
 				Expect(block).To(tests.EqualNode(content.NewBlock(
 					content.NewProperties(
 						content.NewProperty("tags", content.NewText("First, Second")).
-							WithPageRefsIgnored(true),
+							WithPageRefsIgnored(true).
+							WithSeparatedByCommas(true),
 					),
 				)))
 			})
@@ -36,6 +36,10 @@ type property struct {
 	// PageRefsIgnored is whether the pages named in the value are read as text
 	// instead of as references, as set by `:ignored-page-references-keywords`.
 	PageRefsIgnored bool
+
+	// SeparatedByCommas is whether the value is a list of pages separated by
+	// commas, as set by `:property/separated-by-commas`.
+	SeparatedByCommas bool
 }
 
 func (*property) Kind() ast.NodeKind {
@@ -115,6 +119,9 @@ func applyOptionsToProperties(node *properties, reader text.Reader, options *par
 			continue
 		}
 
+		// A list keeps its values apart whether or not they refer to pages
+		property.SeparatedByCommas = options.isSeparatedByCommas(property.Name)
+
 		if options.ignoresPageReferences(property.Name) {
 			// The value of this property is only text, so it is left as it was
 			// parsed and marked as not pointing at any page.
@@ -122,7 +129,7 @@ func applyOptionsToProperties(node *properties, reader text.Reader, options *par
 			continue
 		}
 
-		if options.isSeparatedByCommas(property.Name) {
+		if property.SeparatedByCommas {
 			splitValueIntoPageRefs(property, reader)
 		}
 	}
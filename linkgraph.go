@@ -0,0 +1,407 @@
+package logseq
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aholstenson/logseq-go/internal/indexing"
+	"github.com/aholstenson/logseq-go/internal/utils"
+)
+
+// NodeType is the type of a node in a link graph.
+type NodeType string
+
+const (
+	// NodeTypePage is a page, including pages that are linked to but have no
+	// file of their own.
+	NodeTypePage NodeType = "page"
+	// NodeTypeJournal is a journal.
+	NodeTypeJournal NodeType = "journal"
+	// NodeTypeTag is a page that is only used as a tag.
+	NodeTypeTag NodeType = "tag"
+	// NodeTypeNamespace is a namespace that has no page of its own.
+	NodeTypeNamespace NodeType = "namespace"
+)
+
+// EdgeType is the way one page points at another in a link graph.
+type EdgeType = indexing.EdgeType
+
+const (
+	// EdgeTypeLink is a link such as `[[Page]]`.
+	EdgeTypeLink = indexing.EdgeTypeLink
+	// EdgeTypeTag is a tag, either as `#Page` or in a `tags::` property.
+	EdgeTypeTag = indexing.EdgeTypeTag
+	// EdgeTypeProperty is a reference to a page in the value of a property.
+	EdgeTypeProperty = indexing.EdgeTypeProperty
+	// EdgeTypeEmbed is an embed such as `{{embed [[Page]]}}`.
+	EdgeTypeEmbed = indexing.EdgeTypeEmbed
+	// EdgeTypeAlias points from a page to one of the aliases in its `alias::`
+	// property.
+	EdgeTypeAlias = indexing.EdgeTypeAlias
+	// EdgeTypeNamespace points from a page to the namespace it is directly in,
+	// so `Parent/Child` points at `Parent`.
+	EdgeTypeNamespace = indexing.EdgeTypeNamespace
+)
+
+// LinkGraph is the pages of a graph and how they point at each other, the
+// same thing the graph view of Logseq shows.
+type LinkGraph struct {
+	Nodes []*LinkNode `json:"nodes"`
+	Edges []*LinkEdge `json:"edges"`
+}
+
+// LinkNode is a page in a link graph. The ID is the title in lower case, as
+// Logseq does not distinguish between titles that only differ in case.
+type LinkNode struct {
+	ID    string   `json:"id"`
+	Title string   `json:"title"`
+	Type  NodeType `json:"type"`
+}
+
+// LinkEdge points from one node to another. Count is the number of times the
+// page points at the other page in the same way.
+type LinkEdge struct {
+	From  string   `json:"from"`
+	To    string   `json:"to"`
+	Type  EdgeType `json:"type"`
+	Count int      `json:"count"`
+}
+
+// LinkGraphOption is an option for building a link graph.
+type LinkGraphOption func(*linkGraphOptions)
+
+type linkGraphOptions struct {
+	query      Query
+	tags       bool
+	namespaces bool
+}
+
+// WithPagesMatching limits the link graph to the edges from the pages that
+// match the query, such as the pages in a namespace via UnderNamespace. The
+// pages they point at are included no matter if they match.
+func WithPagesMatching(q Query) LinkGraphOption {
+	return func(o *linkGraphOptions) {
+		o.query = q
+	}
+}
+
+// WithTagNodes includes tags in the link graph. Tags are left out by default,
+// as they tend to connect most pages to a few and hide the links between them.
+func WithTagNodes() LinkGraphOption {
+	return func(o *linkGraphOptions) {
+		o.tags = true
+	}
+}
+
+// WithNamespaceNodes includes edges from pages to the namespace they are in,
+// along with nodes for namespaces that have no page of their own.
+func WithNamespaceNodes() LinkGraphOption {
+	return func(o *linkGraphOptions) {
+		o.namespaces = true
+	}
+}
+
+// LinkGraph builds the graph of how the pages in the graph point at each
+// other, via links, tags, properties, embeds, aliases and namespaces. Pages
+// that are pointed at but have no file of their own are included, as Logseq
+// shows them as pages as well.
+//
+// The link graph is built from the index, so this requires the graph to have
+// been opened with indexing enabled.
+func (g *Graph) LinkGraph(ctx context.Context, opts ...LinkGraphOption) (*LinkGraph, error) {
+	if g.index == nil {
+		return nil, fmt.Errorf("indexing is not enabled")
+	}
+
+	options := &linkGraphOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	query := options.query
+	if query == nil {
+		query = indexing.All()
+	}
+
+	builder := &linkGraphBuilder{
+		nodes: make(map[string]*LinkNode),
+		edges: make(map[string]*LinkEdge),
+	}
+
+	// Pages are gone through first, so that the nodes of the pages that exist
+	// get their proper title and type before being pointed at
+	sources := make(map[string]string)
+	err := g.index.EachPage(ctx, query, func(page *indexing.Page) bool {
+		result := g.pageResult(page, g)
+		nodeType := NodeTypePage
+		if result.Type() == PageTypeJournal {
+			nodeType = NodeTypeJournal
+		}
+
+		id := builder.node(result.Title(), nodeType)
+		sources[page.SubPath] = id
+
+		for _, alias := range page.Aliases {
+			builder.edge(id, builder.node(alias, NodeTypePage), EdgeTypeAlias)
+		}
+
+		if options.namespaces && result.Type() == PageTypeDedicated {
+			namespaces := utils.NamespacesOf(result.Title())
+			if len(namespaces) > 0 {
+				builder.edge(id, builder.node(namespaces[0], NodeTypeNamespace), EdgeTypeNamespace)
+			}
+		}
+
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = g.index.EachBlock(ctx, indexing.All(), func(block *indexing.Block) bool {
+		from, ok := sources[block.PageSubPath]
+		if !ok {
+			return true
+		}
+
+		for _, edge := range block.Edges {
+			if edge.Type == indexing.EdgeTypeTag && !options.tags {
+				continue
+			}
+
+			nodeType := NodeTypePage
+			if edge.Type == indexing.EdgeTypeTag {
+				nodeType = NodeTypeTag
+			}
+
+			builder.edge(from, builder.node(edge.To, nodeType), edge.Type)
+		}
+
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return builder.build(), nil
+}
+
+type linkGraphBuilder struct {
+	nodes map[string]*LinkNode
+	edges map[string]*LinkEdge
+}
+
+// node adds a node unless it already exists, and returns its id. A node that
+// is pointed at in more than one way takes the type of the strongest one, so a
+// page that exists stays a page even if it is also used as a tag.
+func (b *linkGraphBuilder) node(title string, nodeType NodeType) string {
+	id := strings.ToLower(title)
+	if node, ok := b.nodes[id]; ok {
+		if nodeTypeRank(nodeType) < nodeTypeRank(node.Type) {
+			node.Type = nodeType
+		}
+		return id
+	}
+
+	b.nodes[id] = &LinkNode{
+		ID:    id,
+		Title: title,
+		Type:  nodeType,
+	}
+	return id
+}
+
+func nodeTypeRank(nodeType NodeType) int {
+	switch nodeType {
+	case NodeTypeJournal:
+		return 0
+	case NodeTypePage:
+		return 1
+	case NodeTypeNamespace:
+		return 2
+	default:
+		return 3
+	}
+}
+
+func (b *linkGraphBuilder) edge(from string, to string, edgeType EdgeType) {
+	if from == to {
+		return
+	}
+
+	key := from + "\x00" + to + "\x00" + string(edgeType)
+	if edge, ok := b.edges[key]; ok {
+		edge.Count++
+		return
+	}
+
+	b.edges[key] = &LinkEdge{
+		From:  from,
+		To:    to,
+		Type:  edgeType,
+		Count: 1,
+	}
+}
+
+// build returns the link graph, with the nodes and edges sorted so that the
+// same graph is always written the same way.
+func (b *linkGraphBuilder) build() *LinkGraph {
+	graph := &LinkGraph{
+		Nodes: make([]*LinkNode, 0, len(b.nodes)),
+		Edges: make([]*LinkEdge, 0, len(b.edges)),
+	}
+
+	for _, node := range b.nodes {
+		graph.Nodes = append(graph.Nodes, node)
+	}
+	sort.Slice(graph.Nodes, func(i, j int) bool {
+		return graph.Nodes[i].ID < graph.Nodes[j].ID
+	})
+
+	for _, edge := range b.edges {
+		graph.Edges = append(graph.Edges, edge)
+	}
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		a, b := graph.Edges[i], graph.Edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		return a.Type < b.Type
+	})
+
+	return graph
+}
+
+// WriteJSON writes the link graph as JSON, with a list of nodes and a list of
+// edges that refer to the nodes by their id.
+func (l *LinkGraph) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(l)
+}
+
+// WriteDOT writes the link graph in the DOT language of Graphviz.
+func (l *LinkGraph) WriteDOT(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph logseq {\n")
+
+	for _, node := range l.Nodes {
+		fmt.Fprintf(&b, "  %s [label=%s, type=%s];\n",
+			strconv.Quote(node.ID),
+			strconv.Quote(node.Title),
+			strconv.Quote(string(node.Type)),
+		)
+	}
+
+	for _, edge := range l.Edges {
+		fmt.Fprintf(&b, "  %s -> %s [type=%s, weight=%d];\n",
+			strconv.Quote(edge.From),
+			strconv.Quote(edge.To),
+			strconv.Quote(string(edge.Type)),
+			edge.Count,
+		)
+	}
+
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteGraphML writes the link graph as GraphML, with the title and type of
+// nodes and the type and count of edges as data.
+func (l *LinkGraph) WriteGraphML(w io.Writer) error {
+	type data struct {
+		Key   string `xml:"key,attr"`
+		Value string `xml:",chardata"`
+	}
+
+	type node struct {
+		ID   string `xml:"id,attr"`
+		Data []data `xml:"data"`
+	}
+
+	type edge struct {
+		Source string `xml:"source,attr"`
+		Target string `xml:"target,attr"`
+		Data   []data `xml:"data"`
+	}
+
+	type key struct {
+		ID       string `xml:"id,attr"`
+		For      string `xml:"for,attr"`
+		AttrName string `xml:"attr.name,attr"`
+		AttrType string `xml:"attr.type,attr"`
+	}
+
+	type graph struct {
+		EdgeDefault string `xml:"edgedefault,attr"`
+		Nodes       []node `xml:"node"`
+		Edges       []edge `xml:"edge"`
+	}
+
+	type graphML struct {
+		XMLName xml.Name `xml:"graphml"`
+		XMLNS   string   `xml:"xmlns,attr"`
+		Keys    []key    `xml:"key"`
+		Graph   graph    `xml:"graph"`
+	}
+
+	doc := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []key{
+			{ID: "title", For: "node", AttrName: "title", AttrType: "string"},
+			{ID: "nodeType", For: "node", AttrName: "type", AttrType: "string"},
+			{ID: "edgeType", For: "edge", AttrName: "type", AttrType: "string"},
+			{ID: "count", For: "edge", AttrName: "count", AttrType: "int"},
+		},
+		Graph: graph{
+			EdgeDefault: "directed",
+		},
+	}
+
+	for _, n := range l.Nodes {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node{
+			ID: n.ID,
+			Data: []data{
+				{Key: "title", Value: n.Title},
+				{Key: "nodeType", Value: string(n.Type)},
+			},
+		})
+	}
+
+	for _, e := range l.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, edge{
+			Source: e.From,
+			Target: e.To,
+			Data: []data{
+				{Key: "edgeType", Value: string(e.Type)},
+				{Key: "count", Value: strconv.Itoa(e.Count)},
+			},
+		})
+	}
+
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	err = encoder.Encode(doc)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,138 @@
+package logseq_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+
+	logseq "github.com/aholstenson/logseq-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Link graph", func() {
+	var (
+		graph *logseq.Graph
+		dir   string
+		ctx   context.Context
+	)
+
+	BeforeEach(func() {
+		dir = setupGraph()
+		ctx = context.Background()
+	})
+
+	AfterEach(func() {
+		if graph != nil {
+			graph.Close()
+			graph = nil
+		}
+	})
+
+	edge := func(from string, to string, edgeType logseq.EdgeType, count int) *logseq.LinkEdge {
+		return &logseq.LinkEdge{From: from, To: to, Type: edgeType, Count: count}
+	}
+
+	It("has typed edges between pages", func() {
+		graph = openGraphWithPages(dir, map[string]string{
+			"a.md": "alias:: First\nauthor:: [[B]]\n\n- links to [[B]] and [[b]]\n- {{embed [[C]]}}\n",
+			"b.md": "- links to [[Missing]]\n",
+			"c.md": "- content\n",
+		})
+
+		linkGraph, err := graph.LinkGraph(ctx)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(linkGraph.Edges).To(Equal([]*logseq.LinkEdge{
+			edge("a", "b", logseq.EdgeTypeLink, 2),
+			edge("a", "b", logseq.EdgeTypeProperty, 1),
+			edge("a", "c", logseq.EdgeTypeEmbed, 1),
+			edge("a", "first", logseq.EdgeTypeAlias, 1),
+			edge("b", "missing", logseq.EdgeTypeLink, 1),
+		}))
+
+		Expect(linkGraph.Nodes).To(ContainElement(&logseq.LinkNode{
+			ID:    "missing",
+			Title: "Missing",
+			Type:  logseq.NodeTypePage,
+		}))
+	})
+
+	It("includes tags and namespaces when asked to", func() {
+		graph = openGraphWithPages(dir, map[string]string{
+			"Parent___Child.md": "tags:: Topic\n\n- note #idea\n",
+		})
+
+		linkGraph, err := graph.LinkGraph(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(linkGraph.Edges).To(BeEmpty())
+
+		linkGraph, err = graph.LinkGraph(ctx, logseq.WithTagNodes(), logseq.WithNamespaceNodes())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(linkGraph.Edges).To(Equal([]*logseq.LinkEdge{
+			edge("parent/child", "idea", logseq.EdgeTypeTag, 1),
+			edge("parent/child", "parent", logseq.EdgeTypeNamespace, 1),
+			edge("parent/child", "topic", logseq.EdgeTypeTag, 1),
+		}))
+		Expect(linkGraph.Nodes).To(ContainElements(
+			&logseq.LinkNode{ID: "idea", Title: "idea", Type: logseq.NodeTypeTag},
+			&logseq.LinkNode{ID: "parent", Title: "Parent", Type: logseq.NodeTypeNamespace},
+		))
+	})
+
+	It("only includes edges from the pages that match", func() {
+		graph = openGraphWithPages(dir, map[string]string{
+			"Team___A.md": "- [[Other]]\n",
+			"Other.md":    "- [[Team/A]]\n",
+		})
+
+		linkGraph, err := graph.LinkGraph(ctx, logseq.WithPagesMatching(logseq.UnderNamespace("Team")))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(linkGraph.Edges).To(Equal([]*logseq.LinkEdge{
+			edge("team/a", "other", logseq.EdgeTypeLink, 1),
+		}))
+	})
+
+	It("can be written as DOT, GraphML and JSON", func() {
+		graph = openGraphWithPages(dir, map[string]string{
+			"a.md": "- [[B]]\n",
+		})
+
+		linkGraph, err := graph.LinkGraph(ctx)
+		Expect(err).ToNot(HaveOccurred())
+
+		var dot bytes.Buffer
+		Expect(linkGraph.WriteDOT(&dot)).To(Succeed())
+		Expect(dot.String()).To(Equal("digraph logseq {\n" +
+			"  \"a\" [label=\"a\", type=\"page\"];\n" +
+			"  \"b\" [label=\"B\", type=\"page\"];\n" +
+			"  \"a\" -> \"b\" [type=\"link\", weight=1];\n" +
+			"}\n"))
+
+		var graphML bytes.Buffer
+		Expect(linkGraph.WriteGraphML(&graphML)).To(Succeed())
+
+		var parsedGraphML struct {
+			Nodes []struct {
+				ID string `xml:"id,attr"`
+			} `xml:"graph>node"`
+			Edges []struct {
+				Source string `xml:"source,attr"`
+				Target string `xml:"target,attr"`
+			} `xml:"graph>edge"`
+		}
+		Expect(xml.Unmarshal(graphML.Bytes(), &parsedGraphML)).To(Succeed())
+		Expect(parsedGraphML.Nodes).To(HaveLen(2))
+		Expect(parsedGraphML.Edges).To(HaveLen(1))
+		Expect(parsedGraphML.Edges[0].Source).To(Equal("a"))
+		Expect(parsedGraphML.Edges[0].Target).To(Equal("b"))
+
+		var jsonOutput bytes.Buffer
+		Expect(linkGraph.WriteJSON(&jsonOutput)).To(Succeed())
+
+		var parsed logseq.LinkGraph
+		Expect(json.Unmarshal(jsonOutput.Bytes(), &parsed)).To(Succeed())
+		Expect(parsed).To(Equal(*linkGraph))
+	})
+})
@@ -0,0 +1,356 @@
+package logseq
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aholstenson/logseq-go/content"
+	"github.com/aholstenson/logseq-go/internal/indexing"
+	"github.com/aholstenson/logseq-go/internal/utils"
+)
+
+// LintKind is the kind of problem a lint finding is about.
+type LintKind string
+
+const (
+	// LintOrphanPage is a page that has no content and that nothing
+	// references.
+	LintOrphanPage LintKind = "orphan-page"
+	// LintMissingPage is a link or embed of a page that has no file.
+	LintMissingPage LintKind = "missing-page"
+	// LintMissingBlock is a block reference such as `((id))` or a block embed
+	// of an id that no block has.
+	LintMissingBlock LintKind = "missing-block"
+	// LintDuplicateAlias is an alias that more than one page claims. Logseq
+	// resolves the alias to one of them, and the others can not be reached
+	// via it.
+	LintDuplicateAlias LintKind = "duplicate-alias"
+	// LintDuplicateBlockID is an `id::` that more than one block has.
+	LintDuplicateBlockID LintKind = "duplicate-block-id"
+)
+
+// LintFinding is a problem found in the graph.
+type LintFinding struct {
+	// Kind is the kind of problem.
+	Kind LintKind
+
+	// Page is the page the problem is on.
+	Page PageResult
+	// Block is the block the problem is in, nil if the problem is with the
+	// page as a whole.
+	Block BlockResult
+
+	// File is the path of the file of the page, relative to the graph.
+	File string
+	// Location is the location of the block in the page, as the index of the
+	// block in every level from the top of the page. Empty if the problem is
+	// with the page as a whole.
+	Location []int
+
+	// Target is what the problem is about, the title of the missing page, the
+	// missing or duplicated block id, or the duplicated alias.
+	Target string
+}
+
+// String describes the finding along with where it is, such as
+// `pages/a.md:2.1: missing-page: Other`. Block locations start at one.
+func (f *LintFinding) String() string {
+	var b strings.Builder
+	b.WriteString(f.File)
+	for idx, position := range f.Location {
+		if idx == 0 {
+			b.WriteString(":")
+		} else {
+			b.WriteString(".")
+		}
+		b.WriteString(strconv.Itoa(position + 1))
+	}
+
+	b.WriteString(": ")
+	b.WriteString(string(f.Kind))
+	if f.Target != "" {
+		b.WriteString(": ")
+		b.WriteString(f.Target)
+	}
+	return b.String()
+}
+
+// LintReport is the problems found in a graph.
+type LintReport struct {
+	// Findings is the problems, ordered by file and then by location.
+	Findings []*LintFinding
+}
+
+// OfKind returns the findings of a certain kind.
+func (r *LintReport) OfKind(kind LintKind) []*LintFinding {
+	var result []*LintFinding
+	for _, finding := range r.Findings {
+		if finding.Kind == kind {
+			result = append(result, finding)
+		}
+	}
+	return result
+}
+
+// Lint checks the graph for problems that Logseq does not point out on its
+// own. These are pages without content that nothing references, links and
+// embeds of pages that have no file, block references to ids that no block
+// has, aliases claimed by more than one page and block ids used more than
+// once. Tags and pages referenced by property values are not reported when
+// they have no file, as they are commonly used as labels without a page.
+//
+// This can be used to check a graph before it is published:
+//
+//	report, err := graph.Lint(ctx)
+//
+//	for _, finding := range report.Findings {
+//		fmt.Println(finding)
+//	}
+//
+// The checks are done against the index, so this requires the graph to have
+// been opened with indexing enabled. Only the pages with problems in their
+// blocks are read, to find where on the page the blocks are.
+func (g *Graph) Lint(ctx context.Context) (*LintReport, error) {
+	if g.index == nil {
+		return nil, fmt.Errorf("indexing is not enabled")
+	}
+
+	type pageInfo struct {
+		page   *indexing.Page
+		result PageResult
+	}
+
+	pages := make(map[string]*pageInfo)
+	titles := make(map[string]string)
+	aliases := make(map[string][]*pageInfo)
+	err := g.index.EachPage(ctx, indexing.All(), func(page *indexing.Page) bool {
+		info := &pageInfo{
+			page:   page,
+			result: g.pageResult(page, g),
+		}
+		pages[page.SubPath] = info
+		titles[strings.ToLower(info.result.Title())] = page.SubPath
+
+		for _, alias := range page.Aliases {
+			normalized := strings.ToLower(alias)
+			aliases[normalized] = append(aliases[normalized], info)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// resolve finds the file of the page a title refers to, either by the
+	// title of the page or one of its aliases
+	resolve := func(title string) (string, bool) {
+		normalized := strings.ToLower(title)
+		if subPath, ok := titles[normalized]; ok {
+			return subPath, true
+		}
+		if claims, ok := aliases[normalized]; ok {
+			return claims[0].page.SubPath, true
+		}
+		return "", false
+	}
+
+	report := &LintReport{}
+	referenced := make(map[string]bool)
+	blockIDs := make(map[string][]*indexing.Block)
+	var blockRefs []*indexing.Block
+
+	// Blocks are located on their pages once all findings are known, so that
+	// every page is only read once
+	blockFindings := make(map[string]map[*LintFinding]string)
+	addBlockFinding := func(kind LintKind, block *indexing.Block, target string) {
+		finding := &LintFinding{
+			Kind:   kind,
+			Page:   g.pageResultForBlock(block),
+			Block:  g.blockResult(block, g),
+			File:   block.PageSubPath,
+			Target: target,
+		}
+		report.Findings = append(report.Findings, finding)
+
+		if blockFindings[block.PageSubPath] == nil {
+			blockFindings[block.PageSubPath] = make(map[*LintFinding]string)
+		}
+		blockFindings[block.PageSubPath][finding] = block.IndexID
+	}
+
+	err = g.index.EachBlock(ctx, indexing.All(), func(block *indexing.Block) bool {
+		for _, edge := range block.Edges {
+			subPath, ok := resolve(edge.To)
+			if ok {
+				if subPath != block.PageSubPath {
+					referenced[subPath] = true
+				}
+				continue
+			}
+
+			if edge.Type == indexing.EdgeTypeLink || edge.Type == indexing.EdgeTypeEmbed {
+				addBlockFinding(LintMissingPage, block, edge.To)
+			}
+		}
+
+		if block.ID != "" {
+			blockIDs[block.ID] = append(blockIDs[block.ID], block)
+		}
+
+		if len(block.BlockRefs) > 0 {
+			blockRefs = append(blockRefs, block)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Block references can only be checked once every id has been seen
+	for _, block := range blockRefs {
+		for _, id := range block.BlockRefs {
+			if _, ok := blockIDs[id]; !ok {
+				addBlockFinding(LintMissingBlock, block, id)
+			}
+		}
+	}
+
+	for id, blocks := range blockIDs {
+		if len(blocks) < 2 {
+			continue
+		}
+
+		for _, block := range blocks {
+			addBlockFinding(LintDuplicateBlockID, block, id)
+		}
+	}
+
+	for alias, claims := range aliases {
+		if len(claims) < 2 {
+			continue
+		}
+
+		for _, claim := range claims {
+			report.Findings = append(report.Findings, &LintFinding{
+				Kind:   LintDuplicateAlias,
+				Page:   claim.result,
+				File:   claim.page.SubPath,
+				Target: aliasAsWritten(claim.page, alias),
+			})
+		}
+	}
+
+	// A namespace is referenced by the pages in it, as Logseq lists them on
+	// the page of the namespace
+	for _, info := range pages {
+		for _, namespace := range utils.NamespacesOf(info.result.Title()) {
+			if subPath, ok := resolve(namespace); ok {
+				referenced[subPath] = true
+			}
+		}
+	}
+
+	for subPath, info := range pages {
+		// Journals are created by Logseq for every day, so an empty journal
+		// is not a problem
+		if info.result.Type() == PageTypeJournal {
+			continue
+		}
+
+		if info.page.Preview == "" && !referenced[subPath] {
+			report.Findings = append(report.Findings, &LintFinding{
+				Kind: LintOrphanPage,
+				Page: info.result,
+				File: subPath,
+			})
+		}
+	}
+
+	for subPath, findings := range blockFindings {
+		var page Page
+		for finding, indexID := range findings {
+			if page == nil {
+				page, err = finding.Page.Open()
+				if err != nil {
+					return nil, fmt.Errorf("failed to open page %s: %w", finding.Page.Title(), err)
+				}
+			}
+
+			if block := indexing.FindBlock(subPath, page.Blocks(), indexID); block != nil {
+				finding.Location = blockLocation(block)
+			}
+		}
+	}
+
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		a, b := report.Findings[i], report.Findings[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if c := compareLocations(a.Location, b.Location); c != 0 {
+			return c < 0
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Target < b.Target
+	})
+
+	return report, nil
+}
+
+// pageResultForBlock returns the page a block found in the index is on.
+func (g *Graph) pageResultForBlock(block *indexing.Block) PageResult {
+	result := g.blockResult(block, g)
+	return &pageResultImpl{
+		docType: result.PageType(),
+		title:   result.PageTitle(),
+		date:    result.PageDate(),
+		opener:  result.OpenPage,
+	}
+}
+
+// aliasAsWritten returns the alias of the page in the case it is written in.
+func aliasAsWritten(page *indexing.Page, alias string) string {
+	for _, candidate := range page.Aliases {
+		if strings.EqualFold(candidate, alias) {
+			return candidate
+		}
+	}
+	return alias
+}
+
+// blockLocation returns the index of a block among its siblings, for every
+// level from the top of its page down to the block.
+func blockLocation(block *content.Block) []int {
+	var location []int
+	for {
+		parent, ok := block.Parent().(*content.Block)
+		if !ok {
+			break
+		}
+
+		for idx, sibling := range parent.Blocks() {
+			if sibling == block {
+				location = append([]int{idx}, location...)
+				break
+			}
+		}
+
+		block = parent
+	}
+
+	return location
+}
+
+func compareLocations(a []int, b []int) int {
+	for idx := 0; idx < len(a) && idx < len(b); idx++ {
+		if a[idx] != b[idx] {
+			return a[idx] - b[idx]
+		}
+	}
+	return len(a) - len(b)
+}
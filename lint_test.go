@@ -0,0 +1,125 @@
+package logseq_test
+
+import (
+	"context"
+
+	logseq "github.com/aholstenson/logseq-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Lint", func() {
+	var (
+		graph *logseq.Graph
+		dir   string
+		ctx   context.Context
+	)
+
+	BeforeEach(func() {
+		dir = setupGraph()
+		ctx = context.Background()
+	})
+
+	AfterEach(func() {
+		if graph != nil {
+			graph.Close()
+			graph = nil
+		}
+	})
+
+	findings := func(report *logseq.LintReport) []string {
+		var result []string
+		for _, finding := range report.Findings {
+			result = append(result, finding.String())
+		}
+		return result
+	}
+
+	It("reports nothing for a healthy graph", func() {
+		graph = openGraphWithPages(dir, map[string]string{
+			"a.md": "alias:: First\n\n- links to [[B]] and [[first]]\n",
+			"b.md": "- content\n  id:: 6568d5b0-0e4b-4f2b-9bb4-0a4c2e8d3f10\n- ((6568d5b0-0e4b-4f2b-9bb4-0a4c2e8d3f10))\n",
+		})
+
+		report, err := graph.Lint(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Findings).To(BeEmpty())
+	})
+
+	It("reports links and embeds of pages that have no file", func() {
+		graph = openGraphWithPages(dir, map[string]string{
+			"a.md": "- content\n\t- [[Missing]] #label\n\t- {{embed [[Gone]]}}\n",
+		})
+
+		report, err := graph.Lint(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(findings(report)).To(Equal([]string{
+			"pages/a.md:1.1: missing-page: Missing",
+			"pages/a.md:1.2: missing-page: Gone",
+		}))
+
+		finding := report.Findings[0]
+		Expect(finding.Page.Title()).To(Equal("a"))
+		Expect(finding.Block.Preview()).To(Equal("Missing #label"))
+		Expect(finding.Location).To(Equal([]int{0, 0}))
+	})
+
+	It("reports block references to ids that no block has", func() {
+		graph = openGraphWithPages(dir, map[string]string{
+			"a.md": "- see ((6568d5b0-0e4b-4f2b-9bb4-0a4c2e8d3f10))\n- {{embed ((6568d5b0-0e4b-4f2b-9bb4-0a4c2e8d3f11))}}\n",
+		})
+
+		report, err := graph.Lint(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(findings(report)).To(ConsistOf(
+			"pages/a.md:1: missing-block: 6568d5b0-0e4b-4f2b-9bb4-0a4c2e8d3f10",
+			"pages/a.md:2: missing-block: 6568d5b0-0e4b-4f2b-9bb4-0a4c2e8d3f11",
+		))
+	})
+
+	It("reports duplicate block ids", func() {
+		graph = openGraphWithPages(dir, map[string]string{
+			"a.md": "- first\n  id:: 6568d5b0-0e4b-4f2b-9bb4-0a4c2e8d3f10\n",
+			"b.md": "- second\n  id:: 6568d5b0-0e4b-4f2b-9bb4-0a4c2e8d3f10\n",
+		})
+
+		report, err := graph.Lint(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(findings(report)).To(Equal([]string{
+			"pages/a.md:1: duplicate-block-id: 6568d5b0-0e4b-4f2b-9bb4-0a4c2e8d3f10",
+			"pages/b.md:1: duplicate-block-id: 6568d5b0-0e4b-4f2b-9bb4-0a4c2e8d3f10",
+		}))
+	})
+
+	It("reports aliases claimed by more than one page", func() {
+		graph = openGraphWithPages(dir, map[string]string{
+			"a.md": "alias:: Shared, Other\n\n- content\n",
+			"b.md": "alias:: shared\n\n- content\n",
+		})
+
+		report, err := graph.Lint(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(findings(report)).To(Equal([]string{
+			"pages/a.md: duplicate-alias: Shared",
+			"pages/b.md: duplicate-alias: shared",
+		}))
+		Expect(report.Findings[0].Block).To(BeNil())
+	})
+
+	It("reports empty pages that nothing references", func() {
+		graph = openGraphWithPages(dir, map[string]string{
+			"empty.md":      "- \n",
+			"linked.md":     "- \n",
+			"self.md":       "- [[self]]\n",
+			"Parent.md":     "- \n",
+			"Parent___A.md": "- [[Linked]]\n",
+		})
+
+		report, err := graph.Lint(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.OfKind(logseq.LintOrphanPage)).To(HaveLen(1))
+		Expect(findings(report)).To(Equal([]string{
+			"pages/empty.md: orphan-page",
+		}))
+	})
+})
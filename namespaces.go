@@ -16,12 +16,11 @@ func (g *Graph) pageTitlesUnderNamespace(ctx context.Context, namespace string)
 
 	titles := make([]string, 0)
 
-	err := eachResult(func(opts indexing.SearchOptions) (indexing.SearchResults[*indexing.Page], error) {
-		return g.index.SearchPages(ctx, indexing.UnderNamespace(namespace), opts)
-	}, func(page *indexing.Page) {
+	err := g.index.EachPage(ctx, indexing.UnderNamespace(namespace), func(page *indexing.Page) bool {
 		if page.Type == indexing.PageTypeDedicated && page.Title != "" {
 			titles = append(titles, page.Title)
 		}
+		return true
 	})
 	if err != nil {
 		return nil, err
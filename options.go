@@ -1,15 +1,25 @@
 package logseq
 
-import "github.com/aholstenson/logseq-go/content"
+import (
+	"fmt"
+
+	"github.com/aholstenson/logseq-go/content"
+	"github.com/aholstenson/logseq-go/internal/indexing"
+)
 
 type Option func(*options)
 
 type options struct {
 	index          bool
 	indexDirectory string
+	embedder       Embedder
+	language       string
+	analyzers      map[TextField]*Analyzer
 
 	recycleDeletedPages bool
 
+	pageCacheSize int
+
 	listener func(event OpenEvent)
 
 	blockTimeFormat       string
@@ -34,6 +44,47 @@ func WithInMemoryIndex() Option {
 	}
 }
 
+// WithLanguage analyzes the titles and content of the graph for a language,
+// given as its ISO 639-1 code such as `de` or `ja`. See LanguageAnalyzer for
+// what this means for a language. Diacritics are removed as well, so searches
+// work without typing them.
+//
+// The analyzers are recorded in the index, and an index built with other
+// analyzers is rebuilt when the graph is opened.
+func WithLanguage(language string) Option {
+	return func(o *options) {
+		o.language = language
+	}
+}
+
+// WithAnalyzer sets the analyzer for a text field, such as CJKAnalyzer for the
+// content of graphs written in Japanese. Takes precedence over WithLanguage.
+// As with WithLanguage the index is rebuilt if it was built with another
+// analyzer.
+func WithAnalyzer(field TextField, analyzer *Analyzer) Option {
+	return func(o *options) {
+		if o.analyzers == nil {
+			o.analyzers = make(map[TextField]*Analyzer)
+		}
+		o.analyzers[field] = analyzer
+	}
+}
+
+// WithEmbedder keeps a vector for every block next to the index, so that
+// blocks can be found by meaning via SimilarBlocks and SimilarTo. Requires
+// indexing to be enabled via WithIndex or WithInMemoryIndex. NewHashingEmbedder
+// creates an embedder that works offline, other embedders such as ones backed
+// by a local model can be used by implementing Embedder.
+//
+// The vectors are kept in the index directory. Changing the embedder used for
+// a directory requires the index to be removed, so every block is embedded
+// again.
+func WithEmbedder(embedder Embedder) Option {
+	return func(o *options) {
+		o.embedder = embedder
+	}
+}
+
 // WithRecycleDeletedPages makes deleted pages move into the `logseq/.recycle`
 // directory of the graph instead of being removed. That is where Logseq keeps
 // pages deleted in the app, so they can be recovered by moving them back.
@@ -46,6 +97,19 @@ func WithRecycleDeletedPages() Option {
 	}
 }
 
+// WithPageCache keeps the content of up to size recently read pages in
+// memory, so that reading a page again, such as when opening several search
+// results on the same page, does not parse it again. Pages are read again when
+// their file has been modified since they were cached.
+//
+// Every open gets its own copy of the content, so changing an opened page does
+// not change what other opens of it see.
+func WithPageCache(size int) Option {
+	return func(o *options) {
+		o.pageCacheSize = size
+	}
+}
+
 // WithListener sets a listener that will be invoked for events that occur
 // while the graph is being opened.
 func WithListener(listener func(event OpenEvent)) Option {
@@ -82,3 +146,27 @@ func WithBlockTimeFormatter(f func(string) content.InlineNode) Option {
 		o.blockTimeFormatToNode = f
 	}
 }
+
+// indexOptions turns the options that affect indexing into options for the
+// index.
+func (o *options) indexOptions() ([]indexing.IndexOption, error) {
+	var indexOpts []indexing.IndexOption
+	if o.language != "" {
+		analyzer := LanguageAnalyzer(o.language)
+		if analyzer == nil {
+			return nil, fmt.Errorf("unsupported language: %s", o.language)
+		}
+
+		analyzer = analyzer.WithASCIIFolding()
+		indexOpts = append(indexOpts,
+			indexing.WithAnalyzer(TextFieldTitle, analyzer),
+			indexing.WithAnalyzer(TextFieldContent, analyzer),
+		)
+	}
+
+	for field, analyzer := range o.analyzers {
+		indexOpts = append(indexOpts, indexing.WithAnalyzer(field, analyzer))
+	}
+
+	return indexOpts, nil
+}
@@ -0,0 +1,108 @@
+package logseq
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/aholstenson/logseq-go/content"
+)
+
+// pageCache keeps the content of recently read pages, so that a page that is
+// read many times, such as when opening several search results on it, is only
+// parsed once. The least recently used page is dropped when the cache is full.
+//
+// Content is copied both when it is added and when it is handed out, so that
+// changes made to an opened page never reach the cache.
+type pageCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type pageCacheEntry struct {
+	path         string
+	lastModified time.Time
+	root         *content.Block
+}
+
+func newPageCache(size int) *pageCache {
+	return &pageCache{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// get returns a copy of the content of the page at the path, if it has been
+// cached and the file has not been modified since.
+func (c *pageCache) get(path string, lastModified time.Time) (*content.Block, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[path]
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*pageCacheEntry)
+	if !entry.lastModified.Equal(lastModified) {
+		c.order.Remove(element)
+		delete(c.entries, path)
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	return content.Clone(entry.root), true
+}
+
+// put adds a copy of the content of the page at the path.
+func (c *pageCache) put(path string, lastModified time.Time, root *content.Block) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &pageCacheEntry{
+		path:         path,
+		lastModified: lastModified,
+		root:         content.Clone(root),
+	}
+
+	if element, ok := c.entries[path]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[path] = c.order.PushFront(entry)
+
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*pageCacheEntry).path)
+	}
+}
+
+// invalidate drops the page at the path, used when the file is known to have
+// changed.
+func (c *pageCache) invalidate(path string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[path]; ok {
+		c.order.Remove(element)
+		delete(c.entries, path)
+	}
+}
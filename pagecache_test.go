@@ -0,0 +1,86 @@
+package logseq_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	logseq "github.com/aholstenson/logseq-go"
+	"github.com/aholstenson/logseq-go/content"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Page cache", func() {
+	var (
+		graph *logseq.Graph
+		dir   string
+		ctx   context.Context
+	)
+
+	BeforeEach(func() {
+		dir = setupGraph()
+		ctx = context.Background()
+
+		Expect(os.WriteFile(
+			filepath.Join(dir, "pages", "cached.md"),
+			[]byte("- first\n"),
+			0o644,
+		)).To(Succeed())
+
+		var err error
+		graph, err = logseq.Open(ctx, dir, logseq.WithPageCache(10))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		graph.Close()
+	})
+
+	firstText := func(page logseq.Page) string {
+		text := page.Blocks()[0].Children().FindDeep(content.IsOfType[*content.Text]())
+		return text.(*content.Text).Value
+	}
+
+	It("gives every open its own copy of the page", func() {
+		page, err := graph.OpenPage("cached")
+		Expect(err).ToNot(HaveOccurred())
+		page.Blocks()[0].Children().FindDeep(content.IsOfType[*content.Text]()).(*content.Text).Value = "changed"
+		page.AddBlock(textBlock("added"))
+
+		page, err = graph.OpenPage("cached")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(firstText(page)).To(Equal("first"))
+		Expect(page.Blocks()).To(HaveLen(1))
+	})
+
+	It("reads the page again after it is saved", func() {
+		_, err := graph.OpenPage("cached")
+		Expect(err).ToNot(HaveOccurred())
+
+		tx := graph.NewTransaction()
+		page, err := tx.OpenPage("cached")
+		Expect(err).ToNot(HaveOccurred())
+		page.AddBlock(textBlock("second"))
+		Expect(tx.Save()).To(Succeed())
+
+		page, err = graph.OpenPage("cached")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(page.Blocks()).To(HaveLen(2))
+	})
+
+	It("reads the page again when the file is modified", func() {
+		_, err := graph.OpenPage("cached")
+		Expect(err).ToNot(HaveOccurred())
+
+		path := filepath.Join(dir, "pages", "cached.md")
+		Expect(os.WriteFile(path, []byte("- rewritten\n"), 0o644)).To(Succeed())
+		later := time.Now().Add(time.Minute)
+		Expect(os.Chtimes(path, later, later)).To(Succeed())
+
+		page, err := graph.OpenPage("cached")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(firstText(page)).To(Equal("rewritten"))
+	})
+})
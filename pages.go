@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"strings"
 	"time"
 
 	"github.com/aholstenson/logseq-go/content"
@@ -124,7 +123,7 @@ type pageImpl struct {
 	root *content.Block
 }
 
-func openOrCreatePage(source pageSource, path string, pageType PageType, title string, date time.Time, templatePath string, parseOptions ...markdown.ParseOption) (*pageImpl, error) {
+func openOrCreatePage(source pageSource, path string, pageType PageType, title string, date time.Time, templatePath string, graph *Graph) (*pageImpl, error) {
 	// Get the last modified time for the file
 	info, err := os.Stat(path)
 	var root *content.Block
@@ -134,7 +133,7 @@ func openOrCreatePage(source pageSource, path string, pageType PageType, title s
 			// No template, start with an empty page
 			root = content.NewBlock()
 		} else {
-			root, err = loadRootBlock(templatePath, parseOptions...)
+			root, err = loadRootBlock(templatePath, graph.markdownParseOptions()...)
 			if err != nil {
 				return nil, fmt.Errorf("failed to load template: %w", err)
 			}
@@ -144,7 +143,7 @@ func openOrCreatePage(source pageSource, path string, pageType PageType, title s
 		return nil, err
 	} else {
 		// This page exists, load it
-		root, err = loadRootBlock(path, parseOptions...)
+		root, err = graph.loadPage(path, info.ModTime())
 		if err != nil {
 			return nil, fmt.Errorf("failed to load page: %w", err)
 		}
@@ -227,49 +226,7 @@ func (p *pageImpl) Aliases() []string {
 		return nil
 	}
 
-	return propertyTitles(properties.GetAsNode("alias"))
-}
-
-// propertyTitles reads the page titles that are the value of a property, such
-// as `alias:: [[Example]], Another`. Page references are taken as they are,
-// while the rest of the value is split on commas, which is how Logseq writes
-// properties that hold several values.
-func propertyTitles(property *content.Property) []string {
-	if property == nil {
-		return nil
-	}
-
-	titles := make([]string, 0)
-
-	var text strings.Builder
-	takeText := func() {
-		for _, part := range strings.Split(text.String(), ",") {
-			part = strings.TrimSpace(part)
-			if part != "" {
-				titles = append(titles, part)
-			}
-		}
-
-		text.Reset()
-	}
-
-	for _, node := range property.Children() {
-		switch n := node.(type) {
-		case content.PageRef:
-			takeText()
-			titles = append(titles, n.GetTo())
-		case *content.Text:
-			text.WriteString(n.Value)
-		}
-	}
-
-	takeText()
-
-	if len(titles) == 0 {
-		return nil
-	}
-
-	return titles
+	return properties.Strings("alias")
 }
 
 // findProperties locates the properties of the page, returning nil if the page
@@ -336,6 +293,23 @@ func (p *pageImpl) InsertBlockBefore(block *content.Block, before *content.Block
 	p.root.InsertChildBefore(block, before)
 }
 
+// loadPage reads the content of the page at the path, which was last modified
+// at the given time. The content is taken from the page cache if it is
+// enabled and has the page.
+func (g *Graph) loadPage(path string, lastModified time.Time) (*content.Block, error) {
+	if root, ok := g.pages.get(path, lastModified); ok {
+		return root, nil
+	}
+
+	root, err := loadRootBlock(path, g.markdownParseOptions()...)
+	if err != nil {
+		return nil, err
+	}
+
+	g.pages.put(path, lastModified, root)
+	return root, nil
+}
+
 func loadRootBlock(path string, parseOptions ...markdown.ParseOption) (*content.Block, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -0,0 +1,404 @@
+package logseq
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aholstenson/logseq-go/content"
+)
+
+// PropertySource is something that has properties, such as a Page or a
+// *content.Block.
+type PropertySource interface {
+	Properties() *content.Properties
+}
+
+// PropertyUnmarshaler is implemented by types that read themselves from the
+// value of a property.
+type PropertyUnmarshaler interface {
+	UnmarshalProperty(property *content.Property) error
+}
+
+// PropertyMarshaler is implemented by types that write themselves as the
+// value of a property.
+type PropertyMarshaler interface {
+	MarshalProperty(property *content.Property) error
+}
+
+// PropertiesOption is an option for UnmarshalProperties and MarshalProperties.
+type PropertiesOption func(*propertiesOptions)
+
+type propertiesOptions struct {
+	dateFormat string
+}
+
+// WithPropertyDateFormat sets the format dates in properties are written in,
+// which is the Logseq format the journals of the graph are titled with. The
+// default is content.DefaultDateFormat.
+func WithPropertyDateFormat(format string) PropertiesOption {
+	return func(o *propertiesOptions) {
+		o.dateFormat = format
+	}
+}
+
+var (
+	timeType                = reflect.TypeOf(time.Time{})
+	durationType            = reflect.TypeOf(time.Duration(0))
+	propertyUnmarshalerType = reflect.TypeOf((*PropertyUnmarshaler)(nil)).Elem()
+	propertyMarshalerType   = reflect.TypeOf((*PropertyMarshaler)(nil)).Elem()
+	textUnmarshalerType     = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	textMarshalerType       = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// UnmarshalProperties reads the properties of a page or block into the
+// fields of the struct v points to. Fields are mapped to properties via a
+// `logseq` tag, such as `logseq:"author"`, and fields without a tag use their
+// name in lower case. A tag of `-` leaves the field out. Fields whose
+// property is not set are left as they are.
+//
+// Values are read the way the index reads them: text without markup, numbers,
+// `true` and `false`, and dates written as ISO 8601 dates or as links to
+// journals. Durations are read as described by content.Property.Duration.
+// Slices hold the values of a property separated by commas, with page
+// references taken as they are, and the `pages` flag, such as
+// `logseq:"related,pages"`, reads only the pages a property refers to.
+// Pointers are allocated when their property is set, and types implementing
+// PropertyUnmarshaler or encoding.TextUnmarshaler read their own values.
+func UnmarshalProperties(source PropertySource, v any, opts ...PropertiesOption) error {
+	options := propertiesOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("properties can only be unmarshalled into a pointer to a struct, got %T", v)
+	}
+
+	properties := findPropertiesOf(source)
+	if properties == nil {
+		return nil
+	}
+
+	value = value.Elem()
+	for _, field := range propertyFields(value.Type()) {
+		property := properties.GetAsNode(field.name)
+		if property == nil {
+			continue
+		}
+
+		err := unmarshalProperty(property, value.Field(field.index), field.pages, &options)
+		if err != nil {
+			return fmt.Errorf("failed to read property %s: %w", field.name, err)
+		}
+	}
+
+	return nil
+}
+
+// MarshalProperties writes the fields of the struct v, or the struct it points
+// to, as properties, replacing the values of properties that are already set.
+// Fields are mapped the same way as for UnmarshalProperties, and values are
+// written the way Logseq writes them, with dates as links to journals. The
+// `pages` flag writes text as links to pages, such as `tags:: [[a]], [[b]]`.
+//
+// Nil pointers, and empty values of fields with the `omitempty` flag, such as
+// `logseq:"rating,omitempty"`, remove their property instead.
+func MarshalProperties(v any, properties *content.Properties, opts ...PropertiesOption) error {
+	options := propertiesOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	value := reflect.ValueOf(v)
+	if value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		return fmt.Errorf("properties can only be marshalled from a struct, got %T", v)
+	}
+
+	for _, field := range propertyFields(value.Type()) {
+		fieldValue := value.Field(field.index)
+		if (field.omitEmpty && fieldValue.IsZero()) || (fieldValue.Kind() == reflect.Pointer && fieldValue.IsNil()) {
+			properties.Remove(field.name)
+			continue
+		}
+
+		property := properties.GetAsNode(field.name)
+		isNew := property == nil
+		if isNew {
+			property = content.NewProperty(field.name)
+		}
+
+		if err := marshalProperty(property, fieldValue, field.pages, &options); err != nil {
+			return fmt.Errorf("failed to write property %s: %w", field.name, err)
+		}
+
+		if isNew {
+			properties.AddChild(property)
+		}
+	}
+
+	return nil
+}
+
+// findPropertiesOf locates the properties of a page or block without adding
+// them if there are none.
+func findPropertiesOf(source PropertySource) *content.Properties {
+	switch s := source.(type) {
+	case *pageImpl:
+		return s.findProperties()
+	case *content.Block:
+		return s.FindProperties()
+	}
+
+	return source.Properties()
+}
+
+// propertyField is a field of a struct that is mapped to a property.
+type propertyField struct {
+	index     int
+	name      string
+	pages     bool
+	omitEmpty bool
+}
+
+// propertyFields reads how the exported fields of a struct map to properties.
+func propertyFields(t reflect.Type) []propertyField {
+	fields := make([]propertyField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+
+		tag := structField.Tag.Get("logseq")
+		if tag == "-" {
+			continue
+		}
+
+		name, flags, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = strings.ToLower(structField.Name)
+		}
+
+		field := propertyField{index: i, name: name}
+		for _, flag := range strings.Split(flags, ",") {
+			switch flag {
+			case "pages":
+				field.pages = true
+			case "omitempty":
+				field.omitEmpty = true
+			}
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+func unmarshalProperty(property *content.Property, value reflect.Value, pages bool, options *propertiesOptions) error {
+	t := value.Type()
+
+	if reflect.PointerTo(t).Implements(propertyUnmarshalerType) {
+		return value.Addr().Interface().(PropertyUnmarshaler).UnmarshalProperty(property)
+	}
+
+	switch t {
+	case timeType:
+		date, ok := property.Date(options.dateFormat)
+		if !ok {
+			return cannotRead(property, t)
+		}
+
+		value.Set(reflect.ValueOf(date))
+		return nil
+	case durationType:
+		duration, ok := property.Duration()
+		if !ok {
+			return cannotRead(property, t)
+		}
+
+		value.SetInt(int64(duration))
+		return nil
+	}
+
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return value.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(property.String()))
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		if value.IsNil() {
+			value.Set(reflect.New(t.Elem()))
+		}
+
+		return unmarshalProperty(property, value.Elem(), pages, options)
+	case reflect.String:
+		value.SetString(property.String())
+	case reflect.Bool:
+		b, ok := property.Bool()
+		if !ok {
+			return cannotRead(property, t)
+		}
+
+		value.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		number, ok := property.Int()
+		if !ok || value.OverflowInt(number) {
+			return cannotRead(property, t)
+		}
+
+		value.SetInt(number)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		number, err := strconv.ParseUint(strings.TrimSpace(property.String()), 10, t.Bits())
+		if err != nil {
+			return cannotRead(property, t)
+		}
+
+		value.SetUint(number)
+	case reflect.Float32, reflect.Float64:
+		number, ok := property.Float()
+		if !ok || value.OverflowFloat(number) {
+			return cannotRead(property, t)
+		}
+
+		value.SetFloat(number)
+	case reflect.Slice:
+		var values []string
+		if pages {
+			values = property.PageRefs()
+		} else {
+			values = property.Split()
+		}
+
+		slice := reflect.MakeSlice(t, len(values), len(values))
+		for idx, v := range values {
+			part := content.NewProperty(property.Name, content.NewText(v))
+			if err := unmarshalProperty(part, slice.Index(idx), false, options); err != nil {
+				return err
+			}
+		}
+
+		value.Set(slice)
+	default:
+		return fmt.Errorf("unsupported type %s", t)
+	}
+
+	return nil
+}
+
+func cannotRead(property *content.Property, t reflect.Type) error {
+	return fmt.Errorf("can not read %q as %s", property.String(), t)
+}
+
+func marshalProperty(property *content.Property, value reflect.Value, pages bool, options *propertiesOptions) error {
+	t := value.Type()
+
+	if t.Implements(propertyMarshalerType) {
+		return value.Interface().(PropertyMarshaler).MarshalProperty(property)
+	}
+
+	if value.CanAddr() && reflect.PointerTo(t).Implements(propertyMarshalerType) {
+		return value.Addr().Interface().(PropertyMarshaler).MarshalProperty(property)
+	}
+
+	switch t {
+	case timeType:
+		property.SetDate(value.Interface().(time.Time), options.dateFormat)
+		return nil
+	case durationType:
+		property.SetDuration(time.Duration(value.Int()))
+		return nil
+	}
+
+	if t.Implements(textMarshalerType) {
+		text, err := value.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return err
+		}
+
+		property.SetString(string(text))
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		if value.IsNil() {
+			property.SetChildren()
+			return nil
+		}
+
+		return marshalProperty(property, value.Elem(), pages, options)
+	case reflect.String:
+		if pages {
+			property.SetPageRefs(value.String())
+		} else {
+			property.SetString(value.String())
+		}
+	case reflect.Bool:
+		property.SetBool(value.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		property.SetInt(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		property.SetString(strconv.FormatUint(value.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		property.SetFloat(value.Float())
+	case reflect.Slice:
+		return marshalSlice(property, value, pages, options)
+	default:
+		return fmt.Errorf("unsupported type %s", t)
+	}
+
+	return nil
+}
+
+// marshalSlice writes the elements of a slice as the values of a property,
+// separated by commas.
+func marshalSlice(property *content.Property, value reflect.Value, pages bool, options *propertiesOptions) error {
+	elemType := value.Type().Elem()
+	if elemType.Kind() == reflect.String && !hasCustomMarshaler(elemType) {
+		values := make([]string, value.Len())
+		for idx := range values {
+			values[idx] = value.Index(idx).String()
+		}
+
+		if pages {
+			property.SetPageRefs(values...)
+		} else {
+			property.SetStrings(values...)
+		}
+
+		return nil
+	}
+
+	nodes := make([]content.Node, 0, value.Len()*2)
+	for idx := 0; idx < value.Len(); idx++ {
+		part := content.NewProperty(property.Name)
+		if err := marshalProperty(part, value.Index(idx), pages, options); err != nil {
+			return err
+		}
+
+		if idx > 0 {
+			nodes = append(nodes, content.NewText(", "))
+		}
+
+		nodes = append(nodes, part.Children()...)
+	}
+
+	property.SetChildren(nodes...)
+	return nil
+}
+
+func hasCustomMarshaler(t reflect.Type) bool {
+	return t.Implements(propertyMarshalerType) ||
+		reflect.PointerTo(t).Implements(propertyMarshalerType) ||
+		t.Implements(textMarshalerType)
+}
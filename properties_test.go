@@ -0,0 +1,181 @@
+package logseq_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	logseq "github.com/aholstenson/logseq-go"
+	"github.com/aholstenson/logseq-go/content"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type book struct {
+	Title    string        `logseq:"-"`
+	Author   string        `logseq:"author"`
+	Tags     []string      `logseq:"tags,pages"`
+	Pages    int           `logseq:"pages"`
+	Rating   *float64      `logseq:"rating"`
+	Read     bool          `logseq:"read,omitempty"`
+	Finished time.Time     `logseq:"finished,omitempty"`
+	Reading  time.Duration `logseq:"reading-time,omitempty"`
+	Shelf    shelf         `logseq:"shelf,omitempty"`
+}
+
+// shelf is written as the upper case name of a shelf.
+type shelf string
+
+func (s *shelf) UnmarshalProperty(property *content.Property) error {
+	*s = shelf(strings.ToLower(property.String()))
+	return nil
+}
+
+func (s shelf) MarshalProperty(property *content.Property) error {
+	property.SetString(strings.ToUpper(string(s)))
+	return nil
+}
+
+var _ = Describe("Properties", func() {
+	var (
+		graph *logseq.Graph
+		dir   string
+	)
+
+	BeforeEach(func() {
+		dir = setupGraph()
+	})
+
+	AfterEach(func() {
+		if graph != nil {
+			graph.Close()
+			graph = nil
+		}
+	})
+
+	readPage := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(dir, "pages", name))
+		Expect(err).ToNot(HaveOccurred())
+		return string(data)
+	}
+
+	It("reads the properties of a page into a struct", func() {
+		graph = openGraphWithPages(dir, map[string]string{
+			"dune.md": "author:: [[Frank Herbert]]\n" +
+				"tags:: [[sci-fi]], classic\n" +
+				"pages:: 412\n" +
+				"rating:: 4.5\n" +
+				"read:: true\n" +
+				"finished:: [[Jun 26th, 2023]]\n" +
+				"reading-time:: 12h30m\n" +
+				"shelf:: FAVOURITES\n\n" +
+				"- Notes\n",
+		})
+
+		page, err := graph.OpenPage("dune")
+		Expect(err).ToNot(HaveOccurred())
+
+		var b book
+		Expect(logseq.UnmarshalProperties(page, &b)).To(Succeed())
+
+		Expect(b.Author).To(Equal("Frank Herbert"))
+		Expect(b.Tags).To(Equal([]string{"sci-fi", "classic"}))
+		Expect(b.Pages).To(Equal(412))
+		Expect(*b.Rating).To(Equal(4.5))
+		Expect(b.Read).To(BeTrue())
+		Expect(b.Finished).To(Equal(time.Date(2023, 6, 26, 0, 0, 0, 0, time.Local)))
+		Expect(b.Reading).To(Equal(12*time.Hour + 30*time.Minute))
+		Expect(b.Shelf).To(Equal(shelf("favourites")))
+	})
+
+	It("leaves fields without a property as they are", func() {
+		block := content.NewBlock(content.NewParagraph(content.NewText("No properties")))
+
+		b := book{Author: "Someone"}
+		Expect(logseq.UnmarshalProperties(block, &b)).To(Succeed())
+		Expect(b.Author).To(Equal("Someone"))
+		Expect(b.Rating).To(BeNil())
+		Expect(block.FindProperties()).To(BeNil())
+	})
+
+	It("reads all values of a slice", func() {
+		var v struct {
+			Scores []int
+			Tags   []string
+		}
+
+		block := content.NewBlock(content.NewProperties(
+			content.NewProperty("scores", content.NewText("1, 2, 3")),
+			content.NewProperty("tags", content.NewPageLink("a"), content.NewText(", b")),
+		))
+
+		Expect(logseq.UnmarshalProperties(block, &v)).To(Succeed())
+		Expect(v.Scores).To(Equal([]int{1, 2, 3}))
+		Expect(v.Tags).To(Equal([]string{"a", "b"}))
+	})
+
+	It("fails on values of the wrong type", func() {
+		block := content.NewBlock(content.NewProperties(
+			content.NewProperty("pages", content.NewText("many")),
+		))
+
+		var b book
+		err := logseq.UnmarshalProperties(block, &b)
+		Expect(err).To(MatchError(ContainSubstring("pages")))
+	})
+
+	It("writes a struct as the properties Logseq writes", func() {
+		rating := 4.5
+		b := book{
+			Title:    "Dune",
+			Author:   "Frank Herbert",
+			Tags:     []string{"sci-fi", "classic"},
+			Pages:    412,
+			Rating:   &rating,
+			Finished: time.Date(2023, 6, 26, 0, 0, 0, 0, time.Local),
+			Shelf:    "favourites",
+		}
+
+		block := content.NewBlock(content.NewProperties(
+			content.NewProperty("read", content.NewText("true")),
+		))
+		Expect(logseq.MarshalProperties(b, block.Properties())).To(Succeed())
+
+		graph = openGraphWithPages(dir, map[string]string{})
+		tx := graph.NewTransaction()
+		page, err := tx.OpenPage("dune")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(logseq.MarshalProperties(&b, page.Properties())).To(Succeed())
+		Expect(tx.Save()).To(Succeed())
+
+		Expect(readPage("dune.md")).To(Equal(
+			"author:: Frank Herbert\n" +
+				"tags:: [[sci-fi]], [[classic]]\n" +
+				"pages:: 412\n" +
+				"rating:: 4.5\n" +
+				"finished:: [[Jun 26th, 2023]]\n" +
+				"shelf:: FAVOURITES\n",
+		))
+
+		Expect(block.Properties().GetAsNode("read")).To(BeNil())
+		Expect(block.Properties().String("author")).To(Equal("Frank Herbert"))
+	})
+
+	It("writes dates in the format of the graph", func() {
+		var v struct {
+			Due time.Time
+		}
+		v.Due = time.Date(2023, 6, 26, 0, 0, 0, 0, time.Local)
+
+		properties := content.NewProperties()
+		Expect(logseq.MarshalProperties(v, properties, logseq.WithPropertyDateFormat("yyyy-MM-dd"))).To(Succeed())
+		Expect(properties.PageRefs("due")).To(Equal([]string{"2023-06-26"}))
+
+		var read struct {
+			Due time.Time
+		}
+		Expect(logseq.UnmarshalProperties(content.NewBlock(properties), &read, logseq.WithPropertyDateFormat("yyyy-MM-dd"))).To(Succeed())
+		Expect(read.Due).To(Equal(v.Due))
+	})
+})
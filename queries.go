@@ -1,9 +1,55 @@
 package logseq
 
-import "github.com/aholstenson/logseq-go/internal/indexing"
+import (
+	"time"
 
+	"github.com/aholstenson/logseq-go/internal/indexing"
+)
+
+// Query is a query for pages and blocks. Queries can be written as text via
+// String and read back via ParseQuery.
 type Query = indexing.Query
 
+// SyntaxError is returned by ParseQuery when a query can not be read. The
+// position is in characters from the start of the query.
+type SyntaxError = indexing.SyntaxError
+
+// ParseQuery reads a query written the way it would be typed into a search
+// box, such as:
+//
+//	tag:book author:"Le Guin" -status:done "exact phrase" ns:Reading/
+//
+// Terms are separated by spaces and all of them have to match. Terms can be
+// combined with `OR`, negated with `-` or `NOT`, and grouped with parentheses.
+// Words and quoted phrases are matched against the content, while terms with
+// a prefix match in other ways:
+//
+//   - `title:word` matches titles, as TitleMatches
+//   - `content:"some words"` matches content with all of the words, in any order
+//   - `ref:Page` and `tag:Page` reference a page, as References and ReferencesTag
+//   - `pathref:Page` and `pathtag:Page` do the same via parents, as
+//     ReferencesInPath and ReferencesTagInPath
+//   - `ns:Parent` is directly in a namespace, `ns:Parent/` anywhere in it
+//   - `alias:Name` is a page with the alias, as HasAlias
+//   - `id:uuid` is the block with the id, as BlockIDEquals
+//   - `parent:uuid` and `ancestor:uuid` are below a block, as ChildOf and
+//     DescendantOf, while `ancestor:(tag:decision)` is below blocks that match
+//   - `link:https://example.com` links to the URL, as LinksToURL
+//   - `*` matches everything
+//
+// Any other prefix is a property. `status:done` is PropertyEquals, while
+// `author~guin` is PropertyMatches. A property value can also be a reference as
+// in `author:[[Le Guin]]` or `type:#book`, a number range as in `rating:>3`,
+// `rating:<3` or `rating:1..5`, or a date range as in
+// `due:2024-01-01..2024-01-31`. Quoting a value makes it a plain value. A
+// property with the same name as one of the prefixes above is written as
+// `prop.title:value`.
+//
+// An empty query matches everything. Errors are returned as a *SyntaxError.
+func ParseQuery(text string) (Query, error) {
+	return indexing.ParseQuery(text)
+}
+
 func All() Query {
 	return indexing.All()
 }
@@ -32,12 +78,37 @@ func ContentMatches(text string) Query {
 	return indexing.ContentMatches(text)
 }
 
+// ContentMatchesPhrase matches content that has the words of the text next to
+// each other, in the same order.
+func ContentMatchesPhrase(text string) Query {
+	return indexing.ContentMatchesPhrase(text)
+}
+
 // BlockIDEquals matches the block with the given id, which is the identifier
 // that block references such as `((id))` point at.
 func BlockIDEquals(id string) Query {
 	return indexing.BlockIDEquals(id)
 }
 
+// ChildOf matches the blocks directly below the block with the given id.
+func ChildOf(id string) Query {
+	return indexing.ChildOf(id)
+}
+
+// DescendantOf matches the blocks anywhere below the block with the given id,
+// including the ones below its children.
+func DescendantOf(id string) Query {
+	return indexing.DescendantOf(id)
+}
+
+// HasAncestorMatching matches the blocks that are anywhere below a block that
+// matches the query, such as the blocks below a block tagged `#decision`:
+//
+//	HasAncestorMatching(ReferencesTag("decision"))
+func HasAncestorMatching(query Query) Query {
+	return indexing.HasAncestorMatching(query)
+}
+
 func PropertyMatches(property, text string) Query {
 	return indexing.PropertyMatches(property, text)
 }
@@ -46,6 +117,33 @@ func PropertyEquals(property, value string) Query {
 	return indexing.PropertyEquals(property, value)
 }
 
+// PropertyBetween matches properties with a number as their value that is
+// between min and max, including min and max themselves. Properties that are
+// `true` or `false` count as 1 and 0.
+func PropertyBetween(property string, min, max float64) Query {
+	return indexing.PropertyBetween(property, min, max)
+}
+
+// PropertyGreaterThan matches properties with a number as their value that is
+// greater than the given value.
+func PropertyGreaterThan(property string, value float64) Query {
+	return indexing.PropertyGreaterThan(property, value)
+}
+
+// PropertyLessThan matches properties with a number as their value that is
+// less than the given value.
+func PropertyLessThan(property string, value float64) Query {
+	return indexing.PropertyLessThan(property, value)
+}
+
+// PropertyDateBetween matches properties with a date as their value that is
+// between start and end, including start and end themselves. Dates can be
+// written as `2024-05-01` or the way the graph titles its journals, such as
+// `[[May 1st, 2024]]`.
+func PropertyDateBetween(property string, start, end time.Time) Query {
+	return indexing.PropertyDateBetween(property, start, end)
+}
+
 func PropertyReferences(property, target string) Query {
 	return indexing.PropertyReferences(property, target)
 }
@@ -80,6 +178,20 @@ func ReferencesTag(page string) Query {
 	return indexing.ReferencesTag(page)
 }
 
+// ReferencesInPath matches blocks that reference a page either themselves or
+// via one of their parents, the page they are on or the tags of that page. This
+// is how Logseq queries for a page, so that a task nested below a block that
+// references a project is found when querying for the project.
+func ReferencesInPath(page string) Query {
+	return indexing.ReferencesInPath(page)
+}
+
+// ReferencesTagInPath matches blocks that have a tag either themselves or via
+// one of their parents or the `tags::` property of the page they are on.
+func ReferencesTagInPath(tag string) Query {
+	return indexing.ReferencesTagInPath(tag)
+}
+
 func LinksToURL(url string) Query {
 	return indexing.LinksToURL(url)
 }
@@ -45,15 +45,12 @@ func (g *Graph) referencingSubPaths(ctx context.Context, title string) ([]string
 	subPaths := make([]string, 0)
 	seen := make(map[string]struct{})
 
-	err := eachResult(func(opts indexing.SearchOptions) (indexing.SearchResults[*indexing.Block], error) {
-		return g.index.SearchBlocks(ctx, indexing.References(title), opts)
-	}, func(block *indexing.Block) {
-		if _, ok := seen[block.PageSubPath]; ok {
-			return
+	err := g.index.EachBlock(ctx, indexing.References(title), func(block *indexing.Block) bool {
+		if _, ok := seen[block.PageSubPath]; !ok {
+			seen[block.PageSubPath] = struct{}{}
+			subPaths = append(subPaths, block.PageSubPath)
 		}
-
-		seen[block.PageSubPath] = struct{}{}
-		subPaths = append(subPaths, block.PageSubPath)
+		return true
 	})
 	if err != nil {
 		return nil, err
@@ -1,7 +1,10 @@
 package logseq
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/aholstenson/logseq-go/content"
@@ -19,20 +22,61 @@ type SearchResults[R any] interface {
 
 	// Results is a slice of all the results in this result set.
 	Results() []R
+
+	// Next returns a cursor that continues the search after the last result
+	// in this result set, for use with After. If there are no more results
+	// this will return an empty string.
+	Next() string
 }
 
+// ErrInvalidCursor is returned when a search is continued with a cursor that
+// is malformed or that was returned by a search sorted in another way.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
 // SearchOption is an option for doing a search.
 type SearchOption func(*searchOptions)
 
 type searchOptions struct {
 	query Query
 
-	size int
-	from int
+	size  int
+	from  int
+	after string
 
 	sortBy []indexing.SortField
 }
 
+// SortField is a field that results can be sorted by.
+type SortField int
+
+const (
+	// SortFieldScore sorts by how well results match the query.
+	SortFieldScore SortField = iota
+	// SortFieldTitle sorts by the title of the page, without regard for case.
+	// Blocks are sorted by the title of the page they are on.
+	SortFieldTitle
+	// SortFieldDate sorts by the date of journals. Pages that are not
+	// journals, and the blocks on them, have no date and come last.
+	SortFieldDate
+	// SortFieldLastModified sorts by when the page was last modified. Blocks
+	// are sorted by when they last changed, which is when the page they are on
+	// was modified in a way that changed the block.
+	SortFieldLastModified
+)
+
+func (f SortField) indexField() string {
+	switch f {
+	case SortFieldTitle:
+		return "title:sort"
+	case SortFieldDate:
+		return "date"
+	case SortFieldLastModified:
+		return "lastModified"
+	default:
+		return "_score"
+	}
+}
+
 // WithMaxHits sets the maximum number of hits to return. The default is 10.
 func WithMaxHits(n int) SearchOption {
 	return func(o *searchOptions) {
@@ -48,6 +92,46 @@ func FromHit(n int) SearchOption {
 	}
 }
 
+// SortBy sorts the results by a field, in ascending order if asc is true and
+// descending order otherwise. This option can be used multiple times in which
+// case the later fields sort results that are the same for the earlier ones.
+// The default is to sort by score, with the best matches first.
+func SortBy(field SortField, asc bool) SearchOption {
+	return func(o *searchOptions) {
+		o.sortBy = append(o.sortBy, indexing.SortField{
+			Field: field.indexField(),
+			Asc:   asc,
+		})
+	}
+}
+
+// SortByProperty sorts the results by the value of a property, in ascending
+// order if asc is true and descending order otherwise. Values that are numbers
+// or dates are sorted as such, before any values that are only text. Results
+// without the property come last.
+func SortByProperty(property string, asc bool) SearchOption {
+	return func(o *searchOptions) {
+		for _, suffix := range []string{":number", ":date", ":value"} {
+			o.sortBy = append(o.sortBy, indexing.SortField{
+				Field: "prop:" + property + suffix,
+				Asc:   asc,
+			})
+		}
+	}
+}
+
+// After continues a search after the last result of an earlier search, using
+// the cursor returned by SearchResults.Next. Unlike FromHit, results are not
+// skipped or repeated when the graph changes between the searches, which makes
+// this the better choice for going through large result sets. The search must
+// be sorted the same way as the one the cursor came from, and FromHit is
+// ignored when this option is used.
+func After(cursor string) SearchOption {
+	return func(o *searchOptions) {
+		o.after = cursor
+	}
+}
+
 // WithQuery sets the query to use for the search. If no query is set the
 // default is to match everything. This option can be used multiple times in
 // which case the queries are combined with a logical AND.
@@ -61,39 +145,87 @@ func WithQuery(q Query) SearchOption {
 	}
 }
 
-// eachResult goes through every result of a search, fetching them in batches so
-// that searches with more results than are wanted in memory at once can be
-// walked through.
-func eachResult[V any](search func(opts indexing.SearchOptions) (indexing.SearchResults[V], error), each func(V)) error {
-	const batchSize = 500
+// indexOptions turns the options into the options for searching the index.
+// One more result than asked for is fetched, so that it is known if there are
+// more results to continue with.
+func (o *searchOptions) indexOptions() (indexing.SearchOptions, error) {
+	indexOpts := indexing.SearchOptions{
+		Size:   o.size + 1,
+		From:   o.from,
+		SortBy: o.sortBy,
+	}
 
-	for from := 0; ; from += batchSize {
-		results, err := search(indexing.SearchOptions{
-			Size: batchSize,
-			From: from,
-		})
+	if o.after != "" {
+		after, err := decodeCursor(o.after, o.sortBy)
 		if err != nil {
-			return err
+			return indexing.SearchOptions{}, err
 		}
 
-		if results.Size() == 0 {
-			return nil
-		}
+		indexOpts.From = 0
+		indexOpts.After = after
+	}
 
-		for _, result := range results.Results() {
-			each(result)
-		}
+	return indexOpts, nil
+}
 
-		if from+results.Size() >= results.Count() {
-			return nil
+// searchCursor is what a cursor holds, the sort values of the last result
+// together with the sort order they are for.
+type searchCursor struct {
+	Sort  string   `json:"s"`
+	After [][]byte `json:"a"`
+}
+
+// sortKey describes a sort order, so that a cursor can be checked against the
+// search it is used for.
+func sortKey(sortBy []indexing.SortField) string {
+	var b strings.Builder
+	for _, sortField := range sortBy {
+		b.WriteString(sortField.Field)
+		if sortField.Asc {
+			b.WriteString(":asc,")
+		} else {
+			b.WriteString(":desc,")
 		}
 	}
+	return b.String()
+}
+
+func encodeCursor(sortBy []indexing.SortField, after [][]byte) string {
+	data, err := json.Marshal(searchCursor{
+		Sort:  sortKey(sortBy),
+		After: after,
+	})
+	if err != nil {
+		return ""
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(cursor string, sortBy []indexing.SortField) ([][]byte, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var decoded searchCursor
+	err = json.Unmarshal(data, &decoded)
+	if err != nil || len(decoded.After) == 0 {
+		return nil, ErrInvalidCursor
+	}
+
+	if decoded.Sort != sortKey(sortBy) {
+		return nil, ErrInvalidCursor
+	}
+
+	return decoded.After, nil
 }
 
 type searchResultsImpl[R any] struct {
 	size    int
 	count   int
 	results []R
+	next    string
 }
 
 func (s *searchResultsImpl[R]) Size() int {
@@ -108,15 +240,31 @@ func (s *searchResultsImpl[R]) Results() []R {
 	return s.results
 }
 
-func newSearchResults[I any, O any](r indexing.SearchResults[I], mapper func(I) O) SearchResults[O] {
-	results := make([]O, len(r.Results()))
-	for i, r := range r.Results() {
+func (s *searchResultsImpl[R]) Next() string {
+	return s.next
+}
+
+// newSearchResults maps the results from the index. The index is asked for
+// one more result than wanted, which is left out but tells that there is a
+// next result to continue with.
+func newSearchResults[I any, O any](r indexing.SearchResults[I], options *searchOptions, mapper func(I) O) SearchResults[O] {
+	indexResults := r.Results()
+
+	next := ""
+	if len(indexResults) > options.size {
+		indexResults = indexResults[:options.size]
+		next = encodeCursor(options.sortBy, r.SortValues()[options.size-1])
+	}
+
+	results := make([]O, len(indexResults))
+	for i, r := range indexResults {
 		results[i] = mapper(r)
 	}
 	return &searchResultsImpl[O]{
-		size:    r.Size(),
+		size:    len(results),
 		count:   r.Count(),
 		results: results,
+		next:    next,
 	}
 }
 
@@ -130,15 +278,33 @@ type PageResult interface {
 	// Date returns the date if this page is a journal.
 	Date() time.Time
 
+	// LastModified returns the last time the page was modified on disk, as of
+	// when it was indexed.
+	LastModified() time.Time
+
+	// Aliases returns the alternative titles of the page.
+	Aliases() []string
+
+	// Tags returns the pages in the `tags::` property of the page.
+	Tags() []string
+
+	// Properties returns the text of every property of the page, by name.
+	// Formatting such as page references is left out of the text.
+	Properties() map[string]string
+
 	// Open the page.
 	Open() (Page, error)
 }
 
 type pageResultImpl struct {
-	docType PageType
-	title   string
-	date    time.Time
-	opener  func() (Page, error)
+	docType      PageType
+	title        string
+	date         time.Time
+	lastModified time.Time
+	aliases      []string
+	tags         []string
+	properties   map[string]string
+	opener       func() (Page, error)
 }
 
 func (d *pageResultImpl) Type() PageType {
@@ -153,6 +319,22 @@ func (d *pageResultImpl) Date() time.Time {
 	return d.date
 }
 
+func (d *pageResultImpl) LastModified() time.Time {
+	return d.lastModified
+}
+
+func (d *pageResultImpl) Aliases() []string {
+	return d.aliases
+}
+
+func (d *pageResultImpl) Tags() []string {
+	return d.tags
+}
+
+func (d *pageResultImpl) Properties() map[string]string {
+	return d.properties
+}
+
 func (d *pageResultImpl) Open() (Page, error) {
 	return d.opener()
 }
@@ -178,6 +360,27 @@ type BlockResult interface {
 	// Preview gets a preview of the block.
 	Preview() string
 
+	// Content gets the text of the block without any formatting or
+	// properties. Unlike the preview, which is the first paragraph of the
+	// block, this is all of its text.
+	Content() string
+
+	// Properties gets the text of every property of the block, by name.
+	Properties() map[string]string
+
+	// TaskStatus gets the status of the block if it is a task, or
+	// content.TaskStatusNone if it is not.
+	TaskStatus() content.TaskStatus
+
+	// Refs gets the titles of the pages the block references, via links,
+	// tags, embeds or its properties. Every page is only included once.
+	Refs() []string
+
+	// Breadcrumb gets the previews of the blocks this block is nested below,
+	// starting at the top of the page. Blocks that are not nested have an
+	// empty breadcrumb.
+	Breadcrumb() []string
+
 	// OpenPage opens the page that this block belongs to.
 	OpenPage() (Page, error)
 
@@ -190,9 +393,18 @@ type blockResultImpl struct {
 	pageTitle string
 	pageDate  time.Time
 
-	id       string
-	preview  string
-	location []int
+	id         string
+	preview    string
+	content    string
+	properties map[string]string
+	taskStatus content.TaskStatus
+	refs       []string
+	breadcrumb []string
+
+	// pageSubPath and indexID locate the block in the index, which finds it on
+	// its page when it has no id of its own
+	pageSubPath string
+	indexID     string
 
 	opener func() (Page, error)
 }
@@ -217,6 +429,26 @@ func (b *blockResultImpl) Preview() string {
 	return b.preview
 }
 
+func (b *blockResultImpl) Content() string {
+	return b.content
+}
+
+func (b *blockResultImpl) Properties() map[string]string {
+	return b.properties
+}
+
+func (b *blockResultImpl) TaskStatus() content.TaskStatus {
+	return b.taskStatus
+}
+
+func (b *blockResultImpl) Refs() []string {
+	return b.refs
+}
+
+func (b *blockResultImpl) Breadcrumb() []string {
+	return b.breadcrumb
+}
+
 func (b *blockResultImpl) OpenPage() (Page, error) {
 	return b.opener()
 }
@@ -240,16 +472,10 @@ func (b *blockResultImpl) Open() (*content.Block, Page, error) {
 		return nil, nil, ErrBlockNotFound
 	}
 
-	// No stable id, walk the location and return the block
-	blocks := page.Blocks()
-	var block *content.Block
-	for _, i := range b.location {
-		if i >= len(blocks) {
-			return nil, nil, ErrBlockNotFound
-		}
-
-		block = blocks[i]
-		blocks = block.Blocks()
+	// No stable id, find the block by its id in the index
+	block := indexing.FindBlock(b.pageSubPath, page.Blocks(), b.indexID)
+	if block == nil {
+		return nil, nil, ErrBlockNotFound
 	}
 
 	return block, page, nil
@@ -2,11 +2,13 @@ package logseq_test
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"time"
 
 	logseq "github.com/aholstenson/logseq-go"
+	"github.com/aholstenson/logseq-go/content"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -112,6 +114,25 @@ var _ = Describe("Search", func() {
 			Expect(results.Results()[0].Type()).To(Equal(logseq.PageTypeDedicated))
 		})
 
+		It("reports stored fields on page results", func() {
+			graph = openGraphWithPages(dir, map[string]string{
+				"alpha.md": "alias:: First\ntags:: [[Greek]], letters\nrating:: 5\n\n- content\n",
+			})
+
+			results, err := graph.SearchPages(ctx,
+				logseq.WithQuery(logseq.TitleMatches("alpha")),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results.Size()).To(Equal(1))
+
+			result := results.Results()[0]
+			Expect(result.Aliases()).To(Equal([]string{"First"}))
+			Expect(result.Tags()).To(Equal([]string{"Greek", "letters"}))
+			Expect(result.Properties()).To(HaveKeyWithValue("rating", "5"))
+			Expect(result.Properties()).To(HaveKeyWithValue("tags", "Greek, letters"))
+			Expect(result.LastModified().IsZero()).To(BeFalse())
+		})
+
 		It("finds journal pages", func() {
 			Expect(os.WriteFile(
 				filepath.Join(dir, "journals", "2025_06_15.md"),
@@ -235,6 +256,24 @@ var _ = Describe("Search", func() {
 			Expect(result.Preview()).ToNot(BeEmpty())
 		})
 
+		It("reports stored fields on block results", func() {
+			graph = openGraphWithPages(dir, map[string]string{
+				"mypage.md": "- TODO Read [[Dune]] with #book and [[dune]]\n  author:: [[Frank Herbert]]\n\n  Second paragraph\n",
+			})
+
+			results, err := graph.SearchBlocks(ctx,
+				logseq.WithQuery(logseq.References("Dune")),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results.Size()).To(Equal(1))
+
+			result := results.Results()[0]
+			Expect(result.TaskStatus()).To(Equal(content.TaskStatusTodo))
+			Expect(result.Content()).To(ContainSubstring("Second paragraph"))
+			Expect(result.Properties()).To(Equal(map[string]string{"author": "Frank Herbert"}))
+			Expect(result.Refs()).To(Equal([]string{"Dune", "book", "Frank Herbert"}))
+		})
+
 		It("reports journal metadata on block results", func() {
 			Expect(os.WriteFile(
 				filepath.Join(dir, "journals", "2025_03_20.md"),
@@ -257,6 +296,28 @@ var _ = Describe("Search", func() {
 			))
 		})
 
+		It("finds blocks that inherit a reference from their parents", func() {
+			graph = openGraphWithPages(dir, map[string]string{
+				"notes.md":   "- [[ProjectX]]\n\t- TODO nested task\n- TODO unrelated task\n",
+				"tagged.md":  "tags:: ProjectX\n\n- TODO tagged task\n",
+				"project.md": "- something else\n",
+			})
+
+			results, err := graph.SearchBlocks(ctx,
+				logseq.WithQuery(logseq.And(
+					logseq.ReferencesInPath("ProjectX"),
+					logseq.ContentMatches("task"),
+				)),
+			)
+			Expect(err).ToNot(HaveOccurred())
+
+			previews := make([]string, 0, results.Size())
+			for _, result := range results.Results() {
+				previews = append(previews, result.Preview())
+			}
+			Expect(previews).To(ConsistOf("nested task", "tagged task"))
+		})
+
 		It("can open a block with a stable ID", func() {
 			graph = openGraphWithPages(dir, map[string]string{
 				"withid.md": "- id:: 65a1b2c3-d4e5-6789-abcd-ef0123456789\n  some block\n- id:: aaaa1111-bb22-cc33-dd44-eeeeeeee5555\n  unique5stable content\n",
@@ -277,6 +338,148 @@ var _ = Describe("Search", func() {
 		})
 	})
 
+	Describe("Block hierarchy", func() {
+		const meetingID = "65a1b2c3-d4e5-6789-abcd-ef0123456789"
+
+		blockPreviews := func(query logseq.Query) []string {
+			results, err := graph.SearchBlocks(ctx, logseq.WithQuery(query))
+			Expect(err).ToNot(HaveOccurred())
+
+			previews := make([]string, 0, results.Size())
+			for _, result := range results.Results() {
+				previews = append(previews, result.Preview())
+			}
+			return previews
+		}
+
+		BeforeEach(func() {
+			graph = openGraphWithPages(dir, map[string]string{
+				"notes.md": "- id:: " + meetingID + "\n  Meeting notes\n" +
+					"\t- first point\n" +
+					"\t\t- detail\n" +
+					"\t- second point\n" +
+					"- #decision use Go\n" +
+					"\t- because it is simple\n" +
+					"- unrelated\n",
+			})
+		})
+
+		It("finds the children of a block with ChildOf", func() {
+			Expect(blockPreviews(logseq.ChildOf(meetingID))).To(ConsistOf("first point", "second point"))
+		})
+
+		It("finds everything below a block with DescendantOf", func() {
+			Expect(blockPreviews(logseq.DescendantOf(meetingID))).To(ConsistOf("first point", "detail", "second point"))
+		})
+
+		It("finds the blocks below a matching block with HasAncestorMatching", func() {
+			Expect(blockPreviews(logseq.HasAncestorMatching(logseq.ReferencesTag("decision")))).To(
+				Equal([]string{"because it is simple"}),
+			)
+		})
+
+		It("combines hierarchy queries with other queries", func() {
+			Expect(blockPreviews(logseq.And(
+				logseq.DescendantOf(meetingID),
+				logseq.Not(logseq.ChildOf(meetingID)),
+			))).To(Equal([]string{"detail"}))
+		})
+
+		It("reports the breadcrumb of a block", func() {
+			results, err := graph.SearchBlocks(ctx, logseq.WithQuery(logseq.ContentMatches("detail")))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results.Size()).To(Equal(1))
+			Expect(results.Results()[0].Breadcrumb()).To(Equal([]string{"Meeting notes", "first point"}))
+		})
+	})
+
+	Describe("EachPage", func() {
+		It("goes through every matching page", func() {
+			pages := map[string]string{}
+			for i := 0; i < 25; i++ {
+				pages[fmt.Sprintf("page %d.md", i)] = "- eachterm1\n"
+			}
+			pages["other.md"] = "- something else\n"
+			graph = openGraphWithPages(dir, pages)
+
+			titles := make([]string, 0)
+			err := graph.EachPage(ctx, logseq.ContentMatches("eachterm1"), func(result logseq.PageResult) bool {
+				titles = append(titles, result.Title())
+				return true
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(titles).To(HaveLen(25))
+			Expect(titles).ToNot(ContainElement("other"))
+		})
+
+		It("stops when fn returns false", func() {
+			graph = openGraphWithPages(dir, map[string]string{
+				"a.md": "- content\n",
+				"b.md": "- content\n",
+				"c.md": "- content\n",
+			})
+
+			calls := 0
+			err := graph.EachPage(ctx, nil, func(result logseq.PageResult) bool {
+				calls++
+				return calls < 2
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(calls).To(Equal(2))
+		})
+
+		It("stops when the context is canceled", func() {
+			graph = openGraphWithPages(dir, map[string]string{
+				"a.md": "- content\n",
+				"b.md": "- content\n",
+				"c.md": "- content\n",
+			})
+
+			canceledCtx, cancel := context.WithCancel(ctx)
+
+			calls := 0
+			err := graph.EachPage(canceledCtx, nil, func(result logseq.PageResult) bool {
+				calls++
+				cancel()
+				return true
+			})
+			Expect(err).To(MatchError(context.Canceled))
+			Expect(calls).To(Equal(1))
+		})
+	})
+
+	Describe("EachBlock", func() {
+		It("goes through every matching block", func() {
+			graph = openGraphWithPages(dir, map[string]string{
+				"a.md": "- eachterm2\n- other\n\t- eachterm2 nested\n",
+				"b.md": "- eachterm2\n",
+			})
+
+			previews := make([]string, 0)
+			err := graph.EachBlock(ctx, logseq.ContentMatches("eachterm2"), func(result logseq.BlockResult) bool {
+				previews = append(previews, result.Preview())
+				return true
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(previews).To(ConsistOf("eachterm2", "eachterm2 nested", "eachterm2"))
+		})
+
+		It("returns results that can be opened", func() {
+			graph = openGraphWithPages(dir, map[string]string{
+				"a.md": "- first\n- eachterm3\n",
+			})
+
+			err := graph.EachBlock(ctx, logseq.ContentMatches("eachterm3"), func(result logseq.BlockResult) bool {
+				block, page, err := result.Open()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(block).ToNot(BeNil())
+				Expect(page.Title()).To(Equal("a"))
+				return true
+			})
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
 	Describe("Search options", func() {
 		It("limits results with WithMaxHits", func() {
 			graph = openGraphWithPages(dir, map[string]string{
@@ -310,5 +513,220 @@ var _ = Describe("Search", func() {
 			Expect(results.Size()).To(Equal(1))
 			Expect(results.Count()).To(Equal(3))
 		})
+
+		pageTitles := func(results logseq.SearchResults[logseq.PageResult]) []string {
+			titles := make([]string, 0, results.Size())
+			for _, result := range results.Results() {
+				titles = append(titles, result.Title())
+			}
+			return titles
+		}
+
+		It("sorts by title with SortBy", func() {
+			graph = openGraphWithPages(dir, map[string]string{
+				"beta.md":  "- content\n",
+				"Alpha.md": "- content\n",
+				"gamma.md": "- content\n",
+			})
+
+			results, err := graph.SearchPages(ctx,
+				logseq.SortBy(logseq.SortFieldTitle, true),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pageTitles(results)).To(Equal([]string{"Alpha", "beta", "gamma"}))
+
+			results, err = graph.SearchPages(ctx,
+				logseq.SortBy(logseq.SortFieldTitle, false),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pageTitles(results)).To(Equal([]string{"gamma", "beta", "Alpha"}))
+		})
+
+		It("sorts by when pages were last modified with SortBy", func() {
+			now := time.Now()
+			for i, name := range []string{"first.md", "second.md", "third.md"} {
+				path := filepath.Join(dir, "pages", name)
+				Expect(os.WriteFile(path, []byte("- content\n"), 0o644)).To(Succeed())
+
+				modified := now.Add(time.Duration(i-3) * time.Hour)
+				Expect(os.Chtimes(path, modified, modified)).To(Succeed())
+			}
+
+			graph = openGraphWithPages(dir, map[string]string{})
+
+			results, err := graph.SearchPages(ctx,
+				logseq.SortBy(logseq.SortFieldLastModified, false),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pageTitles(results)).To(Equal([]string{"third", "second", "first"}))
+		})
+
+		It("sorts blocks by the date of their journal with SortBy", func() {
+			for _, name := range []string{"2024_03_01.md", "2024_01_15.md", "2024_02_10.md"} {
+				Expect(os.WriteFile(
+					filepath.Join(dir, "journals", name),
+					[]byte("- journal sortterm1\n"),
+					0o644,
+				)).To(Succeed())
+			}
+
+			graph = openGraphWithPages(dir, map[string]string{})
+
+			results, err := graph.SearchBlocks(ctx,
+				logseq.WithQuery(logseq.ContentMatches("sortterm1")),
+				logseq.SortBy(logseq.SortFieldDate, true),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results.Size()).To(Equal(3))
+
+			dates := make([]time.Time, 0, results.Size())
+			for _, result := range results.Results() {
+				dates = append(dates, result.PageDate())
+			}
+			Expect(dates).To(Equal([]time.Time{
+				time.Date(2024, 1, 15, 0, 0, 0, 0, time.Local),
+				time.Date(2024, 2, 10, 0, 0, 0, 0, time.Local),
+				time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local),
+			}))
+		})
+
+		It("sorts by the value of a property with SortByProperty", func() {
+			graph = openGraphWithPages(dir, map[string]string{
+				"a.md": "rating:: 10\n\n- content\n",
+				"b.md": "rating:: 9\n\n- content\n",
+				"c.md": "rating:: 12\n\n- content\n",
+				"d.md": "- content\n",
+			})
+
+			results, err := graph.SearchPages(ctx,
+				logseq.SortByProperty("rating", true),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pageTitles(results)).To(Equal([]string{"b", "a", "c", "d"}))
+
+			results, err = graph.SearchPages(ctx,
+				logseq.SortByProperty("rating", false),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pageTitles(results)).To(Equal([]string{"c", "a", "b", "d"}))
+		})
+
+		It("finds properties that link to a journal by date", func() {
+			graph = openGraphWithPages(dir, map[string]string{
+				"a.md": "due:: [[May 1st, 2024]]\n\n- content\n",
+				"b.md": "due:: [[Jun 15th, 2024]]\n\n- content\n",
+			})
+
+			results, err := graph.SearchPages(ctx,
+				logseq.WithQuery(logseq.PropertyDateBetween("due",
+					time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local),
+					time.Date(2024, 5, 31, 0, 0, 0, 0, time.Local),
+				)),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pageTitles(results)).To(Equal([]string{"a"}))
+		})
+
+		It("continues a search with After", func() {
+			pages := map[string]string{}
+			for _, name := range []string{"a", "b", "c", "d", "e"} {
+				pages[name+".md"] = "- content\n"
+			}
+			graph = openGraphWithPages(dir, pages)
+
+			var titles []string
+			cursor := ""
+			for {
+				opts := []logseq.SearchOption{
+					logseq.SortBy(logseq.SortFieldTitle, true),
+					logseq.WithMaxHits(2),
+				}
+				if cursor != "" {
+					opts = append(opts, logseq.After(cursor))
+				}
+
+				results, err := graph.SearchPages(ctx, opts...)
+				Expect(err).ToNot(HaveOccurred())
+				titles = append(titles, pageTitles(results)...)
+
+				cursor = results.Next()
+				if cursor == "" {
+					break
+				}
+			}
+
+			Expect(titles).To(Equal([]string{"a", "b", "c", "d", "e"}))
+		})
+
+		It("does not skip results when pages are added between searches", func() {
+			graph = openGraphWithPages(dir, map[string]string{
+				"b.md": "- content\n",
+				"d.md": "- content\n",
+				"f.md": "- content\n",
+			})
+
+			results, err := graph.SearchPages(ctx,
+				logseq.SortBy(logseq.SortFieldTitle, true),
+				logseq.WithMaxHits(2),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pageTitles(results)).To(Equal([]string{"b", "d"}))
+
+			tx := graph.NewTransaction()
+			page, err := tx.OpenPage("a")
+			Expect(err).ToNot(HaveOccurred())
+			page.AddBlock(content.NewBlock(content.NewText("content")))
+			Expect(tx.Save()).To(Succeed())
+
+			results, err = graph.SearchPages(ctx,
+				logseq.SortBy(logseq.SortFieldTitle, true),
+				logseq.WithMaxHits(2),
+				logseq.After(results.Next()),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pageTitles(results)).To(Equal([]string{"f"}))
+			Expect(results.Next()).To(BeEmpty())
+		})
+
+		It("rejects a cursor from a search sorted another way", func() {
+			graph = openGraphWithPages(dir, map[string]string{
+				"a.md": "- content\n",
+				"b.md": "- content\n",
+			})
+
+			results, err := graph.SearchPages(ctx,
+				logseq.SortBy(logseq.SortFieldTitle, true),
+				logseq.WithMaxHits(1),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results.Next()).ToNot(BeEmpty())
+
+			_, err = graph.SearchPages(ctx,
+				logseq.SortBy(logseq.SortFieldLastModified, true),
+				logseq.After(results.Next()),
+			)
+			Expect(err).To(MatchError(logseq.ErrInvalidCursor))
+
+			_, err = graph.SearchPages(ctx, logseq.After("not a cursor"))
+			Expect(err).To(MatchError(logseq.ErrInvalidCursor))
+		})
+	})
+
+	Describe("ParseQuery", func() {
+		It("searches with a parsed query", func() {
+			graph = openGraphWithPages(dir, map[string]string{
+				"a.md": "- The Left Hand of Darkness #book\n  author:: [[Le Guin]]\n  status:: reading\n",
+				"b.md": "- Darkness of the left hand #book\n  author:: [[Le Guin]]\n  status:: done\n",
+				"c.md": "- The Left Hand of Darkness\n",
+			})
+
+			query, err := logseq.ParseQuery(`tag:book author:[[Le Guin]] -status:done "left hand"`)
+			Expect(err).ToNot(HaveOccurred())
+
+			results, err := graph.SearchBlocks(ctx, logseq.WithQuery(query))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results.Size()).To(Equal(1))
+			Expect(results.Results()[0].PageTitle()).To(Equal("a"))
+		})
 	})
 })
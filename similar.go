@@ -0,0 +1,156 @@
+package logseq
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aholstenson/logseq-go/internal/indexing"
+)
+
+// Embedder turns text into vectors, where texts that mean similar things get
+// vectors that point in similar directions. Implement it to use a local model
+// for SimilarBlocks and SimilarTo, or use NewHashingEmbedder.
+type Embedder = indexing.Embedder
+
+// NamedEmbedder is an Embedder that can tell which model it uses. Vectors kept
+// next to the index are only reused by an embedder with the same name that
+// makes vectors of the same length, so the name should change whenever the
+// model does.
+type NamedEmbedder = indexing.NamedEmbedder
+
+// NewHashingEmbedder creates an embedder that needs no model, which makes it
+// work offline and in tests. Words and parts of words are hashed into vectors
+// with the given number of dimensions, so it finds blocks that share words
+// even when they are written in different forms, but does not know about
+// synonyms.
+func NewHashingEmbedder(dimensions int) Embedder {
+	return indexing.NewHashingEmbedder(dimensions)
+}
+
+// SimilarBlocks finds the blocks that mean the most similar thing as the text,
+// most similar first. Unlike a search with ContentMatches the blocks do not
+// need to contain the words of the text, as long as the embedder places them
+// close to it.
+//
+// Search options such as WithMaxHits and FromHit can be used to page through
+// the blocks, and WithQuery narrows them down further. Blocks are ranked by
+// how similar they are, so SortBy and After do not apply to them.
+//
+// This requires the graph to have been opened with an embedder via
+// WithEmbedder.
+func (g *Graph) SimilarBlocks(ctx context.Context, text string, opts ...SearchOption) (SearchResults[BlockResult], error) {
+	if g.vectors == nil {
+		return nil, fmt.Errorf("embeddings are not enabled")
+	}
+
+	vector, err := g.vectors.Embed(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.similarBlocks(ctx, vector, "", opts)
+}
+
+// SimilarTo finds the blocks that are the most similar to the block with the
+// given id, most similar first. The block itself is not included. The same
+// options as for SimilarBlocks can be used.
+func (g *Graph) SimilarTo(ctx context.Context, id string, opts ...SearchOption) (SearchResults[BlockResult], error) {
+	if g.vectors == nil {
+		return nil, fmt.Errorf("embeddings are not enabled")
+	}
+
+	results, err := g.index.SearchBlocks(ctx, indexing.BlockIDEquals(id), indexing.SearchOptions{
+		Size: 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if results.Size() == 0 {
+		return nil, fmt.Errorf("block not found: %s", id)
+	}
+
+	indexID := results.Results()[0].IndexID
+	vector := g.vectors.Vector(indexID)
+	if vector == nil {
+		// The block has no text of its own to compare with
+		return &searchResultsImpl[BlockResult]{}, nil
+	}
+
+	return g.similarBlocks(ctx, vector, indexID, opts)
+}
+
+func (g *Graph) similarBlocks(ctx context.Context, vector []float32, exclude string, opts []SearchOption) (SearchResults[BlockResult], error) {
+	options := &searchOptions{
+		size: 10,
+	}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	if options.size <= 0 {
+		options.size = 10
+	}
+
+	// Blocks that do not match the query are filtered out before ranking, so
+	// that narrowing down the blocks does not leave fewer than asked for
+	var allowed map[string]struct{}
+	if options.query != nil {
+		allowed = make(map[string]struct{})
+		err := g.index.EachBlock(ctx, options.query, func(block *indexing.Block) bool {
+			allowed[block.IndexID] = struct{}{}
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	matches := g.vectors.Similar(vector, func(id string) bool {
+		if id == exclude {
+			return false
+		}
+
+		if allowed != nil {
+			_, ok := allowed[id]
+			return ok
+		}
+
+		return true
+	})
+
+	end := options.from + options.size
+	if end > len(matches) {
+		end = len(matches)
+	}
+
+	ids := make([]string, 0, options.size)
+	for idx := options.from; idx < end; idx++ {
+		ids = append(ids, matches[idx].ID)
+	}
+
+	blocks := make(map[string]*indexing.Block, len(ids))
+	if len(ids) > 0 {
+		err := g.index.EachBlock(ctx, indexing.IndexIDIn(ids...), func(block *indexing.Block) bool {
+			blocks[block.IndexID] = block
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	blockResults := make([]BlockResult, 0, len(ids))
+	for _, id := range ids {
+		if block, ok := blocks[id]; ok {
+			blockResults = append(blockResults, g.blockResult(block, g))
+		}
+	}
+
+	return &searchResultsImpl[BlockResult]{
+		size:    len(blockResults),
+		count:   len(matches),
+		results: blockResults,
+	}, nil
+}
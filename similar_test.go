@@ -0,0 +1,142 @@
+package logseq_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	logseq "github.com/aholstenson/logseq-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Similar blocks", func() {
+	var (
+		graph *logseq.Graph
+		dir   string
+		ctx   context.Context
+	)
+
+	BeforeEach(func() {
+		dir = setupGraph()
+		ctx = context.Background()
+	})
+
+	AfterEach(func() {
+		if graph != nil {
+			graph.Close()
+			graph = nil
+		}
+	})
+
+	writePages := func(pages map[string]string) {
+		for name, content := range pages {
+			Expect(os.WriteFile(
+				filepath.Join(dir, "pages", name),
+				[]byte(content),
+				0o644,
+			)).To(Succeed())
+		}
+	}
+
+	open := func(opts ...logseq.Option) *logseq.Graph {
+		graph, err := logseq.Open(ctx, dir, opts...)
+		Expect(err).ToNot(HaveOccurred())
+		return graph
+	}
+
+	previews := func(results logseq.SearchResults[logseq.BlockResult]) []string {
+		var result []string
+		for _, block := range results.Results() {
+			result = append(result, block.Preview())
+		}
+		return result
+	}
+
+	It("finds blocks with different forms of the same words", func() {
+		writePages(map[string]string{
+			"a.md": "- Notes from the weekly meetings with the team\n- Recipe for sourdough bread\n- Planning the garden\n",
+		})
+		graph = open(logseq.WithInMemoryIndex(), logseq.WithEmbedder(logseq.NewHashingEmbedder(512)))
+
+		results, err := graph.SimilarBlocks(ctx, "team meeting", logseq.WithMaxHits(1))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(previews(results)).To(Equal([]string{
+			"Notes from the weekly meetings with the team",
+		}))
+		Expect(results.Count()).To(Equal(3))
+	})
+
+	It("finds blocks similar to another block", func() {
+		writePages(map[string]string{
+			"a.md": "- Baking sourdough bread at home\n  id:: 6568d5b0-0e4b-4f2b-9bb4-0a4c2e8d3f10\n",
+			"b.md": "- Tips for baking bread\n- Repairing a bicycle\n",
+		})
+		graph = open(logseq.WithInMemoryIndex(), logseq.WithEmbedder(logseq.NewHashingEmbedder(512)))
+
+		results, err := graph.SimilarTo(ctx, "6568d5b0-0e4b-4f2b-9bb4-0a4c2e8d3f10")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(previews(results)).To(HaveLen(2))
+		Expect(previews(results)[0]).To(Equal("Tips for baking bread"))
+	})
+
+	It("narrows down blocks with a query", func() {
+		writePages(map[string]string{
+			"a.md": "- Baking bread\n",
+			"b.md": "- Baking bread with [[Friends]]\n",
+		})
+		graph = open(logseq.WithInMemoryIndex(), logseq.WithEmbedder(logseq.NewHashingEmbedder(512)))
+
+		results, err := graph.SimilarBlocks(ctx, "bread", logseq.WithQuery(logseq.References("Friends")))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(previews(results)).To(Equal([]string{"Baking bread with Friends"}))
+	})
+
+	It("requires an embedder", func() {
+		graph = open(logseq.WithInMemoryIndex())
+
+		_, err := graph.SimilarBlocks(ctx, "bread")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("embeds blocks of an existing index and keeps the vectors", func() {
+		writePages(map[string]string{
+			"a.md": "- Baking bread\n",
+		})
+		indexDir := filepath.Join(GinkgoT().TempDir(), "index")
+
+		graph = open(logseq.WithIndex(indexDir))
+		Expect(graph.Close()).To(Succeed())
+
+		graph = open(logseq.WithIndex(indexDir), logseq.WithEmbedder(logseq.NewHashingEmbedder(512)))
+		results, err := graph.SimilarBlocks(ctx, "bread")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(previews(results)).To(Equal([]string{"Baking bread"}))
+		Expect(graph.Close()).To(Succeed())
+
+		Expect(filepath.Join(indexDir, "vectors.gob")).To(BeAnExistingFile())
+
+		graph = open(logseq.WithIndex(indexDir), logseq.WithEmbedder(logseq.NewHashingEmbedder(512)))
+		results, err = graph.SimilarBlocks(ctx, "bread")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(previews(results)).To(Equal([]string{"Baking bread"}))
+	})
+
+	It("keeps the vectors up to date as pages change", func() {
+		writePages(map[string]string{
+			"a.md": "- Baking bread\n",
+		})
+		graph = open(logseq.WithInMemoryIndex(), logseq.WithEmbedder(logseq.NewHashingEmbedder(512)))
+
+		writePages(map[string]string{
+			"a.md": "- Repairing a bicycle\n",
+		})
+
+		Eventually(func() []string {
+			results, err := graph.SimilarBlocks(ctx, "bicycle")
+			Expect(err).ToNot(HaveOccurred())
+			return previews(results)
+		}, 5*time.Second).Should(Equal([]string{"Repairing a bicycle"}))
+	})
+})
@@ -0,0 +1,211 @@
+package logseq
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aholstenson/logseq-go/internal/indexing"
+)
+
+// suggestCandidates is the smallest number of pages looked at when ranking
+// suggestions that do not match at the start of their title. The index scores
+// pages without knowing which kind of match each one is, so more pages than
+// are returned have to be looked at for the best ones to come first.
+const suggestCandidates = 50
+
+// SuggestPages finds the pages that could be what is being typed, for
+// completing page titles in an editor. The text is the start of a title and
+// matches pages and journals by:
+//
+//   - the start of their title or one of their aliases, so `Log` suggests
+//     `Logseq`
+//   - the start of a segment of their namespace, so `Child` and `par/ch`
+//     suggest `Parent/Child`
+//   - the start of the words in their title, allowing for a typo or two in
+//     longer words, so `logsaq` suggests `Logseq`
+//
+// Pages that match at the start of their title come first, followed by the
+// other kinds of matches in the order above. Pages that match the same way
+// are ordered by when they were last modified, most recent first.
+//
+// Search options such as WithMaxHits and FromHit can be used to page through
+// the suggestions, and WithQuery narrows them down further. Suggestions are
+// ranked after they have been found, so SortBy and After do not apply to them.
+//
+// Suggestions are found via the index, so this requires the graph to have been
+// opened with indexing enabled.
+func (g *Graph) SuggestPages(ctx context.Context, text string, opts ...SearchOption) (SearchResults[PageResult], error) {
+	if g.index == nil {
+		return nil, fmt.Errorf("indexing is not enabled")
+	}
+
+	options := &searchOptions{
+		size: 10,
+	}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	if options.size <= 0 {
+		options.size = 10
+	}
+
+	query := indexing.TitleSuggests(text)
+	startQuery := indexing.TitleStartsWith(text)
+	if options.query != nil {
+		query = indexing.And(query, options.query)
+		startQuery = indexing.And(startQuery, options.query)
+	}
+
+	wanted := options.from + options.size
+
+	// Pages that start with what was typed are the best suggestions, so they
+	// are found on their own and in the order they are ranked in, however many
+	// other pages match
+	starts, err := g.index.SearchPages(ctx, startQuery, indexing.SearchOptions{
+		Size:   wanted,
+		SortBy: []indexing.SortField{{Field: "lastModified"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := wanted * 4
+	if candidates < suggestCandidates {
+		candidates = suggestCandidates
+	}
+
+	others, err := g.index.SearchPages(ctx, indexing.And(query, indexing.Not(startQuery)), indexing.SearchOptions{
+		Size: candidates,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pages := append(starts.Results(), others.Results()...)
+	suggestions := make([]*pageSuggestion, 0, len(pages))
+	for _, page := range pages {
+		result := g.pageResult(page, g)
+
+		names := make([]string, 0, len(page.Aliases)+1)
+		names = append(names, result.Title())
+		names = append(names, page.Aliases...)
+
+		suggestions = append(suggestions, &pageSuggestion{
+			result:       result,
+			rank:         suggestionRank(text, names),
+			lastModified: page.LastModified,
+		})
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if a.rank != b.rank {
+			return a.rank < b.rank
+		}
+
+		if !a.lastModified.Equal(b.lastModified) {
+			return a.lastModified.After(b.lastModified)
+		}
+
+		return strings.ToLower(a.result.Title()) < strings.ToLower(b.result.Title())
+	})
+
+	pageResults := make([]PageResult, 0, options.size)
+	for idx := options.from; idx < len(suggestions) && len(pageResults) < options.size; idx++ {
+		pageResults = append(pageResults, suggestions[idx].result)
+	}
+
+	return &searchResultsImpl[PageResult]{
+		size:    len(pageResults),
+		count:   starts.Count() + others.Count(),
+		results: pageResults,
+	}, nil
+}
+
+type pageSuggestion struct {
+	result       PageResult
+	rank         int
+	lastModified time.Time
+}
+
+// suggestionRank ranks how well a page matches what is being typed, based on
+// the best match among its names. Lower ranks are better matches.
+func suggestionRank(text string, names []string) int {
+	typed := strings.ToLower(strings.TrimSpace(text))
+
+	best := 3
+	for _, name := range names {
+		name = strings.ToLower(name)
+
+		rank := 3
+		switch {
+		case strings.HasPrefix(name, typed):
+			return 0
+		case namespaceSegmentsMatch(typed, name):
+			rank = 1
+		case wordsMatch(typed, name):
+			rank = 2
+		}
+
+		if rank < best {
+			best = rank
+		}
+	}
+
+	return best
+}
+
+// namespaceSegmentsMatch checks if the typed text is the start of a segment of
+// the namespace of a name, or if every segment that was typed is the start of
+// a segment of the name in the same order, so `par/ch` matches `parent/child`.
+func namespaceSegmentsMatch(typed string, name string) bool {
+	nameSegments := strings.Split(name, "/")
+	typedSegments := strings.Split(typed, "/")
+
+	for start := 0; start+len(typedSegments) <= len(nameSegments); start++ {
+		matches := true
+		for idx, segment := range typedSegments {
+			if !strings.HasPrefix(nameSegments[start+idx], segment) {
+				matches = false
+				break
+			}
+		}
+
+		if matches {
+			return true
+		}
+	}
+
+	return false
+}
+
+// wordsMatch checks if every word that was typed is the start of a word in
+// the name.
+func wordsMatch(typed string, name string) bool {
+	nameWords := strings.FieldsFunc(name, isWordSeparator)
+
+	for _, word := range strings.FieldsFunc(typed, isWordSeparator) {
+		found := false
+		for _, nameWord := range nameWords {
+			if strings.HasPrefix(nameWord, word) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isWordSeparator(r rune) bool {
+	return r == ' ' || r == '/' || r == '-' || r == '_' || r == ',' || r == '.'
+}
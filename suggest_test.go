@@ -0,0 +1,170 @@
+package logseq_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	logseq "github.com/aholstenson/logseq-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Suggestions", func() {
+	var (
+		graph *logseq.Graph
+		dir   string
+		ctx   context.Context
+	)
+
+	BeforeEach(func() {
+		dir = setupGraph()
+		ctx = context.Background()
+	})
+
+	AfterEach(func() {
+		if graph != nil {
+			graph.Close()
+			graph = nil
+		}
+	})
+
+	suggest := func(text string, opts ...logseq.SearchOption) []string {
+		results, err := graph.SuggestPages(ctx, text, opts...)
+		Expect(err).ToNot(HaveOccurred())
+
+		titles := make([]string, 0, results.Size())
+		for _, result := range results.Results() {
+			titles = append(titles, result.Title())
+		}
+		return titles
+	}
+
+	// touch sets when a page was last modified, so the order of suggestions
+	// that match the same way can be tested.
+	touch := func(name string, at time.Time) {
+		Expect(os.Chtimes(filepath.Join(dir, "pages", name), at, at)).To(Succeed())
+	}
+
+	It("suggests pages by the start of their title", func() {
+		graph = openGraphWithPages(dir, map[string]string{
+			"Logseq.md":   "- content\n",
+			"Logbook.md":  "- content\n",
+			"Markdown.md": "- content\n",
+		})
+
+		Expect(suggest("log")).To(ConsistOf("Logseq", "Logbook"))
+	})
+
+	It("suggests pages by the start of a word in their title", func() {
+		graph = openGraphWithPages(dir, map[string]string{
+			"Quick brown fox.md": "- content\n",
+			"Lazy dog.md":        "- content\n",
+		})
+
+		Expect(suggest("bro")).To(Equal([]string{"Quick brown fox"}))
+	})
+
+	It("suggests pages with a typo in what was typed", func() {
+		graph = openGraphWithPages(dir, map[string]string{
+			"Logseq.md":   "- content\n",
+			"Markdown.md": "- content\n",
+		})
+
+		Expect(suggest("logsaq")).To(Equal([]string{"Logseq"}))
+	})
+
+	It("suggests pages by a segment of their namespace", func() {
+		graph = openGraphWithPages(dir, map[string]string{
+			"Parent___Child.md": "- content\n",
+			"Other.md":          "- content\n",
+		})
+
+		Expect(suggest("chi")).To(Equal([]string{"Parent/Child"}))
+		Expect(suggest("par/ch")).To(Equal([]string{"Parent/Child"}))
+	})
+
+	It("suggests pages by their aliases", func() {
+		graph = openGraphWithPages(dir, map[string]string{
+			"Target.md": "alias:: Nickname\n- content\n",
+		})
+
+		Expect(suggest("nick")).To(Equal([]string{"Target"}))
+	})
+
+	It("suggests journals by their title", func() {
+		Expect(os.WriteFile(
+			filepath.Join(dir, "journals", "2025_06_15.md"),
+			[]byte("- journal entry\n"),
+			0o644,
+		)).To(Succeed())
+
+		graph = openGraphWithPages(dir, map[string]string{})
+
+		results, err := graph.SuggestPages(ctx, "jun 15")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results.Size()).To(Equal(1))
+		Expect(results.Results()[0].Type()).To(Equal(logseq.PageTypeJournal))
+	})
+
+	It("puts pages that start with what was typed first", func() {
+		now := time.Now()
+
+		Expect(os.WriteFile(filepath.Join(dir, "pages", "Reading list.md"), []byte("- content\n"), 0o644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "pages", "Book reading.md"), []byte("- content\n"), 0o644)).To(Succeed())
+		touch("Reading list.md", now.Add(-time.Hour))
+		touch("Book reading.md", now)
+
+		graph = openGraphWithPages(dir, map[string]string{})
+
+		Expect(suggest("read")).To(Equal([]string{"Reading list", "Book reading"}))
+	})
+
+	It("ranks all pages that start with what was typed", func() {
+		now := time.Now()
+
+		// More pages match the same way than are looked at when ranking
+		// suggestions, with the most recently modified one found last
+		for idx := 0; idx < 80; idx++ {
+			name := fmt.Sprintf("Work %d.md", idx)
+			Expect(os.WriteFile(filepath.Join(dir, "pages", name), []byte("- content\n"), 0o644)).To(Succeed())
+			touch(name, now.Add(-time.Hour))
+		}
+		touch("Work 9.md", now)
+
+		graph = openGraphWithPages(dir, map[string]string{})
+
+		results, err := graph.SuggestPages(ctx, "work", logseq.WithMaxHits(5))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results.Results()[0].Title()).To(Equal("Work 9"))
+		Expect(results.Count()).To(Equal(80))
+	})
+
+	It("puts recently modified pages first", func() {
+		now := time.Now()
+
+		Expect(os.WriteFile(filepath.Join(dir, "pages", "Project A.md"), []byte("- content\n"), 0o644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "pages", "Project B.md"), []byte("- content\n"), 0o644)).To(Succeed())
+		touch("Project A.md", now.Add(-time.Hour))
+		touch("Project B.md", now)
+
+		graph = openGraphWithPages(dir, map[string]string{})
+
+		Expect(suggest("proj")).To(Equal([]string{"Project B", "Project A"}))
+	})
+
+	It("limits the suggestions with WithMaxHits", func() {
+		graph = openGraphWithPages(dir, map[string]string{
+			"Note 1.md": "- content\n",
+			"Note 2.md": "- content\n",
+			"Note 3.md": "- content\n",
+		})
+
+		results, err := graph.SuggestPages(ctx, "note", logseq.WithMaxHits(2))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results.Size()).To(Equal(2))
+		Expect(results.Count()).To(Equal(3))
+	})
+})
@@ -0,0 +1,234 @@
+package logseq
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aholstenson/logseq-go/content"
+	"github.com/aholstenson/logseq-go/internal/indexing"
+)
+
+// TimeReport is the time clocked on tasks during a period, summed up per task,
+// page, tag and day.
+type TimeReport struct {
+	// From is the start of the period, or the zero time if it has no start.
+	From time.Time
+
+	// To is the end of the period, or the zero time if it runs until now.
+	To time.Time
+
+	// Total is all of the time clocked during the period.
+	Total time.Duration
+
+	// Tasks is the time clocked on every task with time in the period, with
+	// the task with the most time first.
+	Tasks []TaskTime
+
+	// Pages is the time clocked on the tasks of every page, by title.
+	Pages map[string]time.Duration
+
+	// Tags is the time clocked on tasks by the tags they have, either as
+	// `#tag` or in a `tags::` property. A task also has the tags of the blocks
+	// it is nested below and the tags of its page. Tasks with several tags
+	// count towards each of them.
+	Tags map[string]time.Duration
+
+	// Days is the time clocked on every day, by the date in the form
+	// `2006-01-02`. Clocks that run past midnight are split between the days.
+	Days map[string]time.Duration
+}
+
+// TaskTime is the time clocked on a task.
+type TaskTime struct {
+	// Task is the block of the task.
+	Task BlockResult
+
+	// Duration is the time clocked on the task during the period.
+	Duration time.Duration
+
+	// Running is whether the clock of the task is still running and counts
+	// towards the period, in which case the time until now is included in
+	// Duration.
+	Running bool
+}
+
+// TimeReport sums up the time clocked on the blocks that match the query
+// between from and to, which is the time between the `CLOCK:` entries in their
+// logbooks written by ClockIn and ClockOut or by Logseq. Clocks that are still
+// running count up until now, and only the part of a clock that is within
+// the period is counted. The zero time for from or to leaves the period open
+// at that end.
+//
+// Only tasks and blocks with a logbook are read. A nil query includes all of
+// them, and a query such as `logseq.References` limits the report to a
+// project. Blocks are found via the index, so this
+// requires the graph to have been opened with indexing enabled. If a block is
+// no longer on the page it was indexed on, as the page changed since it was
+// indexed, ErrBlockNotFound is returned.
+func (g *Graph) TimeReport(ctx context.Context, query Query, from time.Time, to time.Time) (*TimeReport, error) {
+	if g.index == nil {
+		return nil, fmt.Errorf("indexing is not enabled")
+	}
+
+	// Only tasks and blocks with a logbook can have time clocked on them
+	tracked := indexing.Or(indexing.IsTask(), indexing.HasLogbook())
+	if query == nil {
+		query = tracked
+	} else {
+		query = indexing.And(query, tracked)
+	}
+
+	// Blocks are gathered by page first, so that every page is only read once
+	var pageOrder []string
+	blocksByPage := make(map[string][]*indexing.Block)
+	err := g.index.EachBlock(ctx, query, func(block *indexing.Block) bool {
+		if _, ok := blocksByPage[block.PageSubPath]; !ok {
+			pageOrder = append(pageOrder, block.PageSubPath)
+		}
+
+		blocksByPage[block.PageSubPath] = append(blocksByPage[block.PageSubPath], block)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &TimeReport{
+		From:  from,
+		To:    to,
+		Pages: make(map[string]time.Duration),
+		Tags:  make(map[string]time.Duration),
+		Days:  make(map[string]time.Duration),
+	}
+
+	now := time.Now()
+	for _, subPath := range pageOrder {
+		var page Page
+		for _, indexed := range blocksByPage[subPath] {
+			result := g.blockResult(indexed, g)
+			if page == nil {
+				page, err = result.OpenPage()
+				if err != nil {
+					return nil, fmt.Errorf("failed to open page %s: %w", result.PageTitle(), err)
+				}
+			}
+
+			block := indexing.FindBlock(indexed.PageSubPath, page.Blocks(), indexed.IndexID)
+			if block == nil {
+				return nil, fmt.Errorf("failed to find block on page %s: %w", result.PageTitle(), ErrBlockNotFound)
+			}
+
+			logbook := block.Logbook(false)
+			if logbook == nil {
+				continue
+			}
+
+			task := TaskTime{Task: result}
+			for _, node := range logbook.Children() {
+				clock, ok := node.(*content.LogbookEntryClock)
+				if !ok {
+					continue
+				}
+
+				duration := report.addClock(clock, now)
+				if clock.IsRunning() && duration > 0 {
+					task.Running = true
+				}
+
+				task.Duration += duration
+			}
+
+			if task.Duration <= 0 {
+				continue
+			}
+
+			report.Tasks = append(report.Tasks, task)
+			report.Total += task.Duration
+			report.Pages[result.PageTitle()] += task.Duration
+			for _, tag := range taskTags(page, block) {
+				report.Tags[tag] += task.Duration
+			}
+		}
+	}
+
+	sort.SliceStable(report.Tasks, func(i, j int) bool {
+		return report.Tasks[i].Duration > report.Tasks[j].Duration
+	})
+
+	return report, nil
+}
+
+// addClock adds the part of a clock that is within the period of the report to
+// the days it ran on, returning how long that part is. A running clock runs
+// until now.
+func (r *TimeReport) addClock(clock *content.LogbookEntryClock, now time.Time) time.Duration {
+	start := clock.Start
+	end := clock.End
+	if clock.IsRunning() {
+		end = now
+	}
+
+	if !r.From.IsZero() && start.Before(r.From) {
+		start = r.From
+	}
+
+	if !r.To.IsZero() && end.After(r.To) {
+		end = r.To
+	}
+
+	if !end.After(start) {
+		return 0
+	}
+
+	for day := start; day.Before(end); {
+		nextDay := time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, day.Location())
+		if nextDay.After(end) {
+			nextDay = end
+		}
+
+		r.Days[day.Format("2006-01-02")] += nextDay.Sub(day)
+		day = nextDay
+	}
+
+	return end.Sub(start)
+}
+
+// taskTags returns the tags of a task, which are the tags of the block, of the
+// blocks it is nested below and of its page. Tags are only included once, the
+// first way they are written.
+func taskTags(page Page, block *content.Block) []string {
+	var tags []string
+	seen := make(map[string]struct{})
+	add := func(refs content.NodeList) {
+		for _, ref := range refs {
+			to := ref.(content.PageRef).GetTo()
+			key := strings.ToLower(to)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+
+			seen[key] = struct{}{}
+			tags = append(tags, to)
+		}
+	}
+
+	for current := block; current != nil; current, _ = current.Parent().(*content.Block) {
+		// Only the content of the block itself, as the blocks below it do not
+		// pass their tags up
+		add(current.Content().FilterDeep(content.IsOfType[*content.Hashtag]()))
+		if properties := current.FindProperties(); properties != nil {
+			add(properties.Get("tags").PageReferences())
+		}
+	}
+
+	if impl, ok := page.(*pageImpl); ok {
+		if properties := impl.findProperties(); properties != nil {
+			add(properties.Get("tags").PageReferences())
+		}
+	}
+
+	return tags
+}
@@ -0,0 +1,143 @@
+package logseq_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	logseq "github.com/aholstenson/logseq-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TimeReport", func() {
+	var (
+		graph *logseq.Graph
+		dir   string
+		ctx   context.Context
+	)
+
+	day := func(d, hour, minute int) time.Time {
+		return time.Date(2023, 6, d, hour, minute, 0, 0, time.Local)
+	}
+
+	BeforeEach(func() {
+		dir = setupGraph()
+		ctx = context.Background()
+
+		graph = openGraphWithPages(dir, map[string]string{
+			"work.md": "- DONE Write report #writing\n" +
+				"  :LOGBOOK:\n" +
+				"  CLOCK: [2023-06-26 Mon 23:00:00]--[2023-06-27 Tue 01:00:00] =>  02:00:00\n" +
+				"  :END:\n" +
+				"- DONE Review [[Project]]\n" +
+				"  :LOGBOOK:\n" +
+				"  CLOCK: [2023-06-27 Tue 09:00:00]--[2023-06-27 Tue 09:30:00] =>  00:30:00\n" +
+				"  :END:\n" +
+				"- NOW Plan #writing\n" +
+				"  :LOGBOOK:\n" +
+				"  CLOCK: [2023-06-27 Tue 10:00:00]\n" +
+				"  :END:\n" +
+				"- No time on this one\n",
+		})
+	})
+
+	AfterEach(func() {
+		if graph != nil {
+			graph.Close()
+			graph = nil
+		}
+	})
+
+	It("sums up the time per task, page, tag and day", func() {
+		report, err := graph.TimeReport(ctx, nil, day(26, 0, 0), day(28, 0, 0))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(report.Total).To(Equal(16*time.Hour + 30*time.Minute))
+		Expect(report.Tasks).To(HaveLen(3))
+		Expect(report.Tasks[0].Task.Preview()).To(ContainSubstring("Plan"))
+		Expect(report.Tasks[0].Running).To(BeTrue())
+		Expect(report.Tasks[0].Duration).To(Equal(14 * time.Hour))
+
+		Expect(report.Pages).To(Equal(map[string]time.Duration{
+			"work": 16*time.Hour + 30*time.Minute,
+		}))
+		Expect(report.Tags).To(Equal(map[string]time.Duration{
+			"writing": 16 * time.Hour,
+		}))
+		Expect(report.Days).To(Equal(map[string]time.Duration{
+			"2023-06-26": time.Hour,
+			"2023-06-27": 15*time.Hour + 30*time.Minute,
+		}))
+	})
+
+	It("only counts the time within the period", func() {
+		report, err := graph.TimeReport(ctx, nil, day(27, 0, 0), day(27, 9, 15))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(report.Total).To(Equal(time.Hour + 15*time.Minute))
+		Expect(report.Tasks).To(HaveLen(2))
+	})
+
+	It("only reports a running clock as running if it counts towards the period", func() {
+		graph.Close()
+		graph = openGraphWithPages(dir, map[string]string{
+			"earlier.md": "- DOING Refactor\n" +
+				"  :LOGBOOK:\n" +
+				"  CLOCK: [2023-06-20 Tue 09:00:00]--[2023-06-20 Tue 10:00:00] =>  01:00:00\n" +
+				"  CLOCK: [2023-06-29 Thu 08:00:00]\n" +
+				"  :END:\n" +
+				"- Call with the team\n" +
+				"  :LOGBOOK:\n" +
+				"  CLOCK: [2023-06-20 Tue 11:00:00]--[2023-06-20 Tue 11:45:00] =>  00:45:00\n" +
+				"  :END:\n",
+		})
+
+		report, err := graph.TimeReport(ctx, nil, day(20, 0, 0), day(21, 0, 0))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(report.Total).To(Equal(time.Hour + 45*time.Minute))
+		Expect(report.Tasks).To(HaveLen(2))
+		Expect(report.Tasks[0].Task.Preview()).To(ContainSubstring("Refactor"))
+		Expect(report.Tasks[0].Running).To(BeFalse())
+		Expect(report.Tasks[1].Task.Preview()).To(ContainSubstring("Call with the team"))
+	})
+
+	It("counts the tags of the blocks a task is nested below and of its page", func() {
+		graph.Close()
+		graph = openGraphWithPages(setupGraph(), map[string]string{
+			"client.md": "tags:: consulting\n\n" +
+				"- Work for #acme\n" +
+				"  - DONE Write proposal #writing\n" +
+				"    :LOGBOOK:\n" +
+				"    CLOCK: [2023-06-27 Tue 09:00:00]--[2023-06-27 Tue 10:00:00] =>  01:00:00\n" +
+				"    :END:\n",
+		})
+
+		report, err := graph.TimeReport(ctx, nil, time.Time{}, time.Time{})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(report.Tags).To(Equal(map[string]time.Duration{
+			"writing":    time.Hour,
+			"acme":       time.Hour,
+			"consulting": time.Hour,
+		}))
+	})
+
+	It("fails when a task is no longer on the page it was indexed on", func() {
+		Expect(os.WriteFile(filepath.Join(dir, "pages", "work.md"), []byte("- Something else\n"), 0o644)).To(Succeed())
+
+		_, err := graph.TimeReport(ctx, nil, time.Time{}, time.Time{})
+		Expect(errors.Is(err, logseq.ErrBlockNotFound)).To(BeTrue())
+	})
+
+	It("limits the report to the blocks that match the query", func() {
+		report, err := graph.TimeReport(ctx, logseq.References("Project"), time.Time{}, time.Time{})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(report.Total).To(Equal(30 * time.Minute))
+		Expect(report.Pages).To(HaveKeyWithValue("work", 30*time.Minute))
+	})
+})
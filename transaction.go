@@ -277,7 +277,7 @@ func (t *Transaction) renamePage(ctx context.Context, from string, to string) er
 
 		// The page keeps its content but is written to the file of the new
 		// title, leaving the old file to be removed.
-		renamed, err = openOrCreatePage(t, toPath, PageTypeDedicated, to, time.Time{}, "", t.graph.markdownParseOptions()...)
+		renamed, err = openOrCreatePage(t, toPath, PageTypeDedicated, to, time.Time{}, "", t.graph)
 		if err != nil {
 			return err
 		}
@@ -535,6 +535,7 @@ func (t *Transaction) Save() error {
 		}
 
 		err = os.WriteFile(path, []byte(data), 0644)
+		t.graph.pages.invalidate(path)
 		if err != nil {
 			return fmt.Errorf("failed to write page to %s: %w", path, err)
 		}
@@ -558,6 +559,7 @@ func (t *Transaction) Save() error {
 			continue
 		}
 
+		t.graph.pages.invalidate(path)
 		if err := t.graph.removePageFile(path); err != nil {
 			return err
 		}